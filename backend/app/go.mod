@@ -6,6 +6,7 @@ require (
 	github.com/a-h/templ v0.3.943
 	github.com/gchalakovmmi/PulpuWEB/auth v0.0.0-20250825010315-5d84e963313a
 	github.com/gchalakovmmi/PulpuWEB/db v0.0.0-20250825010315-5d84e963313a
+	github.com/gorilla/websocket v1.5.3
 	github.com/jackc/pgx/v5 v5.7.5
 	github.com/markbates/goth v1.82.0
 )
@@ -0,0 +1,137 @@
+package anki
+
+import (
+    "archive/zip"
+    "bytes"
+    "fmt"
+    "html"
+    "io"
+    "strings"
+
+    "PulpuVOX/internal/db"
+)
+
+// DeckName is the deck notes are imported into
+const DeckName = "PulpuVOX"
+
+// NotesFileName is the name of the notes file inside a package
+const NotesFileName = "pulpuvox-anki.txt"
+
+// Note is one Anki note of the Basic note type
+type Note struct {
+    Front string
+    Back  string
+    Tags  []string
+    // Audio is the file name of a sound played on the back of the card, if any
+    Audio string
+}
+
+// fieldCleaner keeps fields on one line, as a tab or line break would start a new field or note
+var fieldCleaner = strings.NewReplacer("\t", " ", "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+// field escapes text for an HTML field of the notes file
+func field(text string) string {
+    return fieldCleaner.Replace(html.EscapeString(text))
+}
+
+// CorrectionNote asks for the corrected form of a sentence the student said
+func CorrectionNote(card *db.ReviewCard) Note {
+    return Note{
+        Front: "<i>Say it correctly:</i><br>" + field(card.Original),
+        Back:  field(card.Corrected),
+        Tags:  []string{"pulpuvox", "correction"},
+    }
+}
+
+// WordNote shows a saved word on the front and the sentence it came from on the back
+func WordNote(entry *db.WordBankEntry) Note {
+    note := Note{
+        Front: field(entry.Word),
+        Back:  field(entry.Context),
+        Tags:  []string{"pulpuvox", "word"},
+    }
+    if entry.Lemma != strings.ToLower(entry.Word) {
+        note.Back = "<b>" + field(entry.Lemma) + "</b><br>" + note.Back
+    }
+    if entry.CEFRLevel != nil {
+        note.Front += " <small>(" + field(*entry.CEFRLevel) + ")</small>"
+        note.Tags = append(note.Tags, "cefr-"+*entry.CEFRLevel)
+    }
+    return note
+}
+
+// WriteNotes writes notes as a tab-separated file for Anki's File > Import. The header
+// lines tell Anki the separator, the deck and which column holds the tags.
+func WriteNotes(w io.Writer, notes []Note) error {
+    var buf bytes.Buffer
+    buf.WriteString("#separator:tab\n")
+    buf.WriteString("#html:true\n")
+    buf.WriteString("#notetype:Basic\n")
+    buf.WriteString("#deck:" + DeckName + "\n")
+    buf.WriteString("#tags column:3\n")
+    for _, note := range notes {
+        back := note.Back
+        if note.Audio != "" {
+            back += "<br>[sound:" + note.Audio + "]"
+        }
+        tags := make([]string, len(note.Tags))
+        for i, tag := range note.Tags {
+            tags[i] = strings.ReplaceAll(tag, " ", "_")
+        }
+        fmt.Fprintf(&buf, "%s\t%s\t%s\n", fieldCleaner.Replace(note.Front), fieldCleaner.Replace(back), strings.Join(tags, " "))
+    }
+    _, err := w.Write(buf.Bytes())
+    return err
+}
+
+// Package is a zip archive of a notes file and the sound files it refers to, written as
+// the sounds become available so that a long export does not hold them all in memory. It
+// is not an .apkg: the sounds go into Anki's collection.media folder by hand before the
+// notes file is imported.
+type Package struct {
+    archive *zip.Writer
+}
+
+// NewPackage starts a package written to w
+func NewPackage(w io.Writer) *Package {
+    return &Package{archive: zip.NewWriter(w)}
+}
+
+// AddSound adds a sound file under media/ and flushes it to the underlying writer
+func (p *Package) AddSound(name string, data []byte) error {
+    file, err := p.archive.Create("media/" + name)
+    if err != nil {
+        return err
+    }
+    if _, err := file.Write(data); err != nil {
+        return err
+    }
+    return p.archive.Flush()
+}
+
+// Close writes the notes file, which is last so that it only refers to the sounds added,
+// and finishes the archive
+func (p *Package) Close(notes []Note) error {
+    notesFile, err := p.archive.Create(NotesFileName)
+    if err != nil {
+        return err
+    }
+    if err := WriteNotes(notesFile, notes); err != nil {
+        return err
+    }
+    return p.archive.Close()
+}
+
+// AudioExtension guesses the file extension of synthesized audio from its first bytes
+func AudioExtension(data []byte) string {
+    switch {
+    case bytes.HasPrefix(data, []byte("RIFF")):
+        return ".wav"
+    case bytes.HasPrefix(data, []byte("OggS")):
+        return ".ogg"
+    case bytes.HasPrefix(data, []byte("fLaC")):
+        return ".flac"
+    default:
+        return ".mp3"
+    }
+}
@@ -0,0 +1,195 @@
+package assessment
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log"
+    "math"
+    "strconv"
+    "strings"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/grammar"
+    "PulpuVOX/internal/language"
+    "PulpuVOX/internal/openai"
+)
+
+// PromptVersion identifies the grading prompt stored with each report. Bump it whenever
+// the prompt or schema changes so reports graded differently can be told apart.
+const PromptVersion = "cefr-report-v4"
+
+// maxRepairAttempts is how many times invalid output is sent back to the model for repair
+const maxRepairAttempts = 2
+
+// ErrNoStudentTurns is returned when a conversation has nothing to grade
+var ErrNoStudentTurns = errors.New("conversation has no student turns")
+
+// systemPrompt is the grader's role for conversations in the given language
+func systemPrompt(target *language.Language) string {
+    return "You are an experienced " + target.Name + ` teacher who grades spoken conversations according to the CEFR.
+You answer with a single JSON object and nothing else.`
+}
+
+// reportSchema describes the JSON object the model must return
+const reportSchema = `{
+  "level": "overall CEFR level: A1, A2, B1, B2, C1 or C2",
+  "scores": {
+    "grammar": 0-100,
+    "vocabulary": 0-100,
+    "fluency": 0-100,
+    "coherence": 0-100
+  },
+  "summary": "two or three encouraging sentences addressed to the student",
+  "recurring_errors": [
+    {
+      "category": "short name of the error, e.g. verb tense, articles, prepositions",
+      "description": "what the student does wrong and the rule to follow",
+      "examples": [
+        {"turn": number of the student turn in brackets, "original": "what the student said", "corrected": "the corrected sentence"}
+      ]
+    }
+  ],
+  "focus_areas": ["what the student should practise next"]
+}`
+
+// Generate grades the student turns of a conversation in the target language and returns a validated report
+// together with the name of the model that graded it. The error rates measured from the
+// classified corrections of this conversation and, when overall is not nil, of all the
+// student's conversations are given to the model so that it grounds the recurring errors
+// on them. Output that is not valid JSON or
+// does not match the schema is sent back to the model together with the problems found,
+// up to maxRepairAttempts times.
+func Generate(ctx context.Context, chatModel openai.ChatModel, history []db.ConversationTurn, target *language.Language, overall *grammar.Stats) (*Report, string, error) {
+    transcript, studentTurns := formatTranscript(history)
+    if studentTurns == 0 {
+        return nil, "", ErrNoStudentTurns
+    }
+
+    errorRates := "In this conversation:\n" + formatErrorRates(grammar.Summarize(grammar.Count(history, target.Code)))
+    if overall != nil && overall.Sentences > 0 {
+        errorRates += "Across all of the student's conversations:\n" + formatErrorRates(overall)
+    }
+
+    messages := []openai.ChatCompletionMessage{
+        {
+            Role:    "system",
+            Content: systemPrompt(target),
+        },
+        {
+            Role: "user",
+            Content: `Below is a conversation between a student and a teacher. Each turn is numbered in brackets.
+The student's turns include a corrected version when they contained mistakes, and a speech analysis
+from the speech recognizer when it was available: speaking rate, recognizer confidence, pauses, and
+words the recognizer was unsure about. Unclear words usually point to pronunciation problems, and
+frequent or long pauses to hesitation.
+
+Analyze the student's ` + target.Name + ` and grade it. Use the speech analysis for fluency and mention
+recurring pronunciation problems as errors and focus areas. Write the summary, descriptions and
+focus areas in English, and quote the student's sentences and corrections as they are.
+Only list errors that occur more than once, and cite the turn numbers where they happen.
+Base the recurring errors on the measured error rates below, which count the corrected sentences
+by error type, and quote the rates in the descriptions, e.g. "you leave out articles in 40% of
+your sentences". Mention an error that is frequent across all conversations even if it is rare in
+this one.
+
+Measured error rates:
+` + errorRates + `
+
+Answer with a JSON object in exactly this shape:
+` + reportSchema + `
+
+Conversation:
+` + transcript,
+        },
+    }
+
+    var lastErr error
+    for attempt := 0; attempt <= maxRepairAttempts; attempt++ {
+        chatCompletion, err := chatModel.Complete(ctx, &openai.ChatCompletionRequest{
+            Messages:       messages,
+            ResponseFormat: openai.JSONObjectFormat,
+        })
+        if err != nil {
+            return nil, "", err
+        }
+        if len(chatCompletion.Choices) == 0 {
+            return nil, "", errors.New("model returned no choices")
+        }
+        content := chatCompletion.Choices[0].Message.Content
+
+        report, err := parseReport(content, len(history))
+        if err == nil {
+            return report, chatCompletion.Model, nil
+        }
+        lastErr = err
+        log.Printf("Invalid feedback report (attempt %d): %v", attempt+1, err)
+
+        // Ask the model to fix its own output
+        messages = append(messages,
+            openai.ChatCompletionMessage{
+                Role:    "assistant",
+                Content: content,
+            },
+            openai.ChatCompletionMessage{
+                Role: "user",
+                Content: "Your answer is not a valid report: " + err.Error() +
+                    "\nReturn the corrected JSON object only, in exactly the requested shape.",
+            },
+        )
+    }
+    return nil, "", fmt.Errorf("model did not return a valid report: %w", lastErr)
+}
+
+// formatTranscript numbers every turn with its index in the history and counts the student turns
+func formatTranscript(history []db.ConversationTurn) (string, int) {
+    var transcript strings.Builder
+    studentTurns := 0
+    for i, turn := range history {
+        prefix := "[" + strconv.Itoa(i) + "] "
+        if turn.Role == "user" {
+            studentTurns++
+            transcript.WriteString(prefix + "Student: " + turn.Content + "\n")
+            if turn.Suggestion != "" {
+                transcript.WriteString("    Corrected: " + turn.Suggestion + "\n")
+            }
+            if turn.Pronunciation != nil {
+                transcript.WriteString("    Speech: " + turn.Pronunciation.Summary() + "\n")
+            }
+        } else if turn.Role == "assistant" {
+            transcript.WriteString(prefix + "Teacher: " + turn.Content + "\n")
+        }
+    }
+    return transcript.String(), studentTurns
+}
+
+// formatErrorRates lists the error types by the share of sentences they occur in
+func formatErrorRates(stats *grammar.Stats) string {
+    if len(stats.Errors) == 0 {
+        return "    no corrected errors in " + strconv.Itoa(stats.Sentences) + " sentences\n"
+    }
+    var rates strings.Builder
+    for _, stat := range stats.Errors {
+        fmt.Fprintf(&rates, "    %s: %d of %d sentences (%d%%)\n",
+            stat.Label, stat.Sentences, stats.Sentences, int(math.Round(stat.Rate*100)))
+    }
+    return rates.String()
+}
+
+// parseReport decodes and validates model output, tolerating a surrounding code fence
+func parseReport(content string, turns int) (*Report, error) {
+    content = strings.TrimSpace(content)
+    if start, end := strings.Index(content, "{"), strings.LastIndex(content, "}"); start >= 0 && end > start {
+        content = content[start : end+1]
+    }
+
+    var report Report
+    if err := json.Unmarshal([]byte(content), &report); err != nil {
+        return nil, fmt.Errorf("invalid JSON: %w", err)
+    }
+    if err := report.Validate(turns); err != nil {
+        return nil, err
+    }
+    return &report, nil
+}
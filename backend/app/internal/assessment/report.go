@@ -0,0 +1,118 @@
+package assessment
+
+import (
+    "errors"
+    "fmt"
+    "math"
+    "strings"
+)
+
+// CEFRLevels lists the CEFR levels from lowest to highest
+var CEFRLevels = []string{"A1", "A2", "B1", "B2", "C1", "C2"}
+
+// LevelRank returns the position of a CEFR level in CEFRLevels, or -1 if it is unknown.
+// Reports can be sorted by it.
+func LevelRank(level string) int {
+    for i, l := range CEFRLevels {
+        if l == level {
+            return i
+        }
+    }
+    return -1
+}
+
+// AverageLevel returns the rounded average of CEFR levels, ignoring unknown ones, or an
+// empty string if none is known
+func AverageLevel(levels []string) string {
+    sum, count := 0, 0
+    for _, level := range levels {
+        if rank := LevelRank(level); rank >= 0 {
+            sum += rank
+            count++
+        }
+    }
+    if count == 0 {
+        return ""
+    }
+    return CEFRLevels[int(math.Round(float64(sum)/float64(count)))]
+}
+
+// Scores rates each skill from 0 to 100
+type Scores struct {
+    Grammar    int `json:"grammar"`
+    Vocabulary int `json:"vocabulary"`
+    Fluency    int `json:"fluency"`
+    Coherence  int `json:"coherence"`
+}
+
+// ErrorExample points to a student turn that shows a recurring error
+type ErrorExample struct {
+    Turn      int    `json:"turn"`
+    Original  string `json:"original"`
+    Corrected string `json:"corrected"`
+}
+
+// RecurringError is a mistake the student made more than once
+type RecurringError struct {
+    Category    string         `json:"category"`
+    Description string         `json:"description"`
+    Examples    []ErrorExample `json:"examples"`
+}
+
+// Report is the structured feedback on a conversation
+type Report struct {
+    Level           string           `json:"level"`
+    Scores          Scores           `json:"scores"`
+    Summary         string           `json:"summary"`
+    RecurringErrors []RecurringError `json:"recurring_errors"`
+    FocusAreas      []string         `json:"focus_areas"`
+}
+
+// Validate checks a report parsed from model output. turns is the length of the graded
+// history, which bounds the turn numbers of the examples.
+func (r *Report) Validate(turns int) error {
+    var errs []error
+
+    r.Level = strings.ToUpper(strings.TrimSpace(r.Level))
+    if LevelRank(r.Level) < 0 {
+        errs = append(errs, fmt.Errorf("level must be one of %s, got %q", strings.Join(CEFRLevels, ", "), r.Level))
+    }
+
+    for _, score := range []struct {
+        name  string
+        value int
+    }{
+        {"grammar", r.Scores.Grammar},
+        {"vocabulary", r.Scores.Vocabulary},
+        {"fluency", r.Scores.Fluency},
+        {"coherence", r.Scores.Coherence},
+    } {
+        if score.value < 0 || score.value > 100 {
+            errs = append(errs, fmt.Errorf("scores.%s must be between 0 and 100, got %d", score.name, score.value))
+        }
+    }
+
+    if strings.TrimSpace(r.Summary) == "" {
+        errs = append(errs, errors.New("summary must not be empty"))
+    }
+
+    for i, recurring := range r.RecurringErrors {
+        if strings.TrimSpace(recurring.Category) == "" {
+            errs = append(errs, fmt.Errorf("recurring_errors[%d].category must not be empty", i))
+        }
+        for j, example := range recurring.Examples {
+            if example.Turn < 0 || example.Turn >= turns {
+                errs = append(errs, fmt.Errorf("recurring_errors[%d].examples[%d].turn must be between 0 and %d, got %d", i, j, turns-1, example.Turn))
+            }
+        }
+    }
+
+    if r.RecurringErrors == nil {
+        r.RecurringErrors = []RecurringError{}
+    }
+    if r.FocusAreas == nil {
+        r.FocusAreas = []string{}
+    }
+
+    return errors.Join(errs...)
+}
@@ -0,0 +1,73 @@
+package assessment
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "time"
+
+    "PulpuVOX/internal/db"
+    "github.com/jackc/pgx/v5"
+)
+
+// StoredReport is a report together with how and when it was graded
+type StoredReport struct {
+    ConversationID int       `json:"conversation_id"`
+    Report         *Report   `json:"report"`
+    PromptVersion  string    `json:"prompt_version"`
+    Model          string    `json:"model"`
+    CreatedAt      time.Time `json:"created_at"`
+}
+
+// Load returns the stored report of a conversation
+func Load(ctx context.Context, conn *pgx.Conn, conversationID int) (*StoredReport, error) {
+    record, err := db.GetFeedbackReport(ctx, conn, conversationID)
+    if err != nil {
+        return nil, err
+    }
+
+    var report Report
+    if err := json.Unmarshal(record.Report, &report); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal report: %w", err)
+    }
+
+    return &StoredReport{
+        ConversationID: record.ConversationID,
+        Report:         &report,
+        PromptVersion:  record.PromptVersion,
+        Model:          record.Model,
+        CreatedAt:      record.CreatedAt,
+    }, nil
+}
+
+// Save stores the report of a conversation graded by the given model, replacing any earlier one
+func Save(ctx context.Context, conn *pgx.Conn, conversationID, userID int, report *Report, model string) (*StoredReport, error) {
+    reportJSON, err := json.Marshal(report)
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal report: %w", err)
+    }
+
+    record := &db.FeedbackReport{
+        ConversationID:  conversationID,
+        UserID:          userID,
+        Report:          reportJSON,
+        Level:           report.Level,
+        GrammarScore:    report.Scores.Grammar,
+        VocabularyScore: report.Scores.Vocabulary,
+        FluencyScore:    report.Scores.Fluency,
+        CoherenceScore:  report.Scores.Coherence,
+        PromptVersion:   PromptVersion,
+        Model:           model,
+    }
+    if err := db.SaveFeedbackReport(ctx, conn, record); err != nil {
+        return nil, err
+    }
+
+    return &StoredReport{
+        ConversationID: conversationID,
+        Report:         report,
+        PromptVersion:  PromptVersion,
+        Model:          model,
+        CreatedAt:      record.CreatedAt,
+    }, nil
+}
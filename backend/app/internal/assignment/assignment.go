@@ -0,0 +1,81 @@
+package assignment
+
+import (
+    "context"
+    "errors"
+
+    "PulpuVOX/internal/db"
+    "github.com/jackc/pgx/v5"
+)
+
+// Modes of an assignment: a free conversation with Voxy on the topic, or a scenario whose
+// goal must be reached
+const (
+    ModeConversation = "conversation"
+    ModeScenario     = "scenario"
+)
+
+// Statuses of a student's assignment
+const (
+    StatusNotStarted = "not_started"
+    StatusInProgress = "in_progress"
+    StatusCompleted  = "completed"
+    StatusLate       = "late"
+)
+
+// Evaluate returns the number of turns the student took in a conversation and whether it
+// completes the assignment: enough turns and, in a scenario, its goal reached
+func Evaluate(assignment *db.Assignment, conversation *db.Conversation) (int, bool) {
+    turns := 0
+    for _, turn := range conversation.History {
+        if turn.Role == "user" {
+            turns++
+        }
+    }
+    completed := turns >= assignment.MinTurns
+    if assignment.Mode == ModeScenario {
+        completed = completed && conversation.ScenarioCompleted
+    }
+    return turns, completed
+}
+
+// Record stores whether a saved conversation completes the assignment it was started from.
+// Conversations without an assignment, or whose assignment was deleted, are skipped.
+func Record(ctx context.Context, conn *pgx.Conn, conversation *db.Conversation) error {
+    if conversation.AssignmentID == nil {
+        return nil
+    }
+    assignment, err := db.GetAssignment(ctx, conn, *conversation.AssignmentID)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            return nil
+        }
+        return err
+    }
+
+    turns, completed := Evaluate(assignment, conversation)
+    return db.SaveAssignmentSubmission(ctx, conn, &db.AssignmentSubmission{
+        ConversationID: conversation.ID,
+        AssignmentID:   assignment.ID,
+        UserID:         conversation.UserID,
+        TurnCount:      turns,
+        Completed:      completed,
+        SubmittedAt:    conversation.CreatedAt,
+    })
+}
+
+// Status works out a student's status from what they have submitted. An assignment first
+// completed after its due date is late; one not completed stays in progress or not
+// started after the due date too, so the teacher sees what is missing.
+func Status(assignment *db.Assignment, progress *db.AssignmentProgress) string {
+    switch {
+    case progress.CompletedAt != nil && progress.CompletedAt.After(assignment.DueAt):
+        return StatusLate
+    case progress.CompletedAt != nil:
+        return StatusCompleted
+    case progress.Attempts > 0:
+        return StatusInProgress
+    default:
+        return StatusNotStarted
+    }
+}
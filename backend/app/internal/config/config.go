@@ -2,6 +2,8 @@ package config
 
 import (
     "os"
+    "strconv"
+    "strings"
     "time"
 )
 
@@ -11,6 +13,22 @@ type Config struct {
     WriteTimeout time.Duration
     IdleTimeout  time.Duration
     InstanceName string
+
+    // ProviderWriteTimeout replaces WriteTimeout on the routes that wait for the speech and
+    // chat model providers, which may take several calls of up to their own timeout each
+    ProviderWriteTimeout time.Duration
+
+    // Ordered provider chains selected from the whisper, openai and tts registries
+    TranscriberProviders []string
+    ChatModelProviders   []string
+    SynthesizerProviders []string
+
+    // Circuit breaker settings shared by the provider chains
+    ProviderFailureThreshold int
+    ProviderCooldown         time.Duration
+
+    // ProviderFailoverRetries replaces the retries of a provider that has a fallback
+    ProviderFailoverRetries int
 }
 
 func Load() Config {
@@ -20,6 +38,16 @@ func Load() Config {
         WriteTimeout: getEnvAsDuration("WRITE_TIMEOUT", 15*time.Second),
         IdleTimeout:  getEnvAsDuration("IDLE_TIMEOUT", 60*time.Second),
         InstanceName: getEnv("INSTANCE_NAME", "myapp-1"),
+
+        ProviderWriteTimeout: getEnvAsDuration("PROVIDER_WRITE_TIMEOUT", 2*time.Minute),
+
+        TranscriberProviders: getEnvAsList("WHISPER_PROVIDER", "docker"),
+        ChatModelProviders:   getEnvAsList("OPENAI_PROVIDER", "openai"),
+        SynthesizerProviders: getEnvAsList("TTS_PROVIDER", "kittentts"),
+
+        ProviderFailureThreshold: getEnvAsInt("PROVIDER_FAILURE_THRESHOLD", 3),
+        ProviderCooldown:         getEnvAsDuration("PROVIDER_COOLDOWN", 30*time.Second),
+        ProviderFailoverRetries:  getEnvAsInt("PROVIDER_FAILOVER_RETRIES", 0),
     }
 }
 
@@ -38,3 +66,26 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
     }
     return defaultValue
 }
+
+func getEnvAsInt(key string, defaultValue int) int {
+    if value, exists := os.LookupEnv(key); exists {
+        if i, err := strconv.Atoi(value); err == nil {
+            return i
+        }
+    }
+    return defaultValue
+}
+
+// getEnvAsList splits a comma-separated value such as "groq,docker"
+func getEnvAsList(key, defaultValue string) []string {
+    var list []string
+    for _, item := range strings.Split(getEnv(key, defaultValue), ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            list = append(list, item)
+        }
+    }
+    if len(list) == 0 {
+        return []string{defaultValue}
+    }
+    return list
+}
@@ -0,0 +1,241 @@
+package correction
+
+import (
+    "strings"
+
+    "PulpuVOX/internal/textnorm"
+)
+
+// Error types of a changed span
+const (
+    ErrorArticle     = "article"
+    ErrorTense       = "tense"
+    ErrorPreposition = "preposition"
+    ErrorWordOrder   = "word_order"
+    ErrorAgreement   = "agreement"
+    ErrorPlural      = "plural"
+    ErrorSpelling    = "spelling"
+    ErrorWordChoice  = "word_choice"
+    ErrorOther       = "other"
+)
+
+// ErrorTypes lists the error types in the order they are reported
+var ErrorTypes = []string{
+    ErrorArticle, ErrorTense, ErrorAgreement, ErrorPreposition, ErrorPlural,
+    ErrorWordChoice, ErrorWordOrder, ErrorSpelling, ErrorOther,
+}
+
+// articles holds the articles of each language by ISO 639-1 code
+var articles = map[string]map[string]bool{
+    "en": set("a", "an", "the"),
+    "de": set(
+        "der", "die", "das", "den", "dem", "des",
+        "ein", "eine", "einen", "einem", "einer", "eines",
+    ),
+    "es": set("el", "la", "los", "las", "lo", "un", "una", "unos", "unas"),
+}
+
+// prepositions holds the common prepositions of each language by ISO 639-1 code
+var prepositions = map[string]map[string]bool{
+    "en": set(
+        "about", "above", "across", "after", "against", "along", "among", "around", "as", "at",
+        "before", "behind", "below", "beneath", "beside", "between", "beyond", "by", "despite",
+        "down", "during", "except", "for", "from", "in", "inside", "into", "like", "near", "of",
+        "off", "on", "onto", "out", "outside", "over", "past", "since", "through", "throughout",
+        "till", "to", "toward", "towards", "under", "underneath", "until", "up", "upon", "with",
+        "within", "without",
+    ),
+    "de": set(
+        "ab", "an", "auf", "aus", "außer", "bei", "bis", "durch", "für", "gegen", "gegenüber",
+        "hinter", "in", "mit", "nach", "neben", "ohne", "seit", "statt", "trotz", "über", "um",
+        "unter", "von", "vor", "während", "wegen", "zu", "zwischen",
+    ),
+    "es": set(
+        "a", "ante", "bajo", "con", "contra", "de", "desde", "durante", "en", "entre", "hacia",
+        "hasta", "mediante", "para", "por", "según", "sin", "sobre", "tras",
+    ),
+}
+
+// auxiliaries are the verbs that build tenses and aspects: "have gone", "will go", "was going"
+var auxiliaries = set(
+    "be", "am", "is", "are", "was", "were", "been", "being",
+    "have", "has", "had", "do", "does", "did",
+    "will", "would", "shall", "should",
+)
+
+// subjects are the pronouns whose verb agrees with them: "he go" -> "he goes"
+var subjects = set("i", "you", "he", "she", "it", "we", "they", "there")
+
+// agreementPairs are the singular and plural forms of verbs and determiners, whose
+// number follows another word: "they was", "this books"
+var agreementPairs = [][2]string{
+    {"is", "are"}, {"am", "are"}, {"was", "were"}, {"has", "have"}, {"does", "do"},
+    {"this", "these"}, {"that", "those"},
+}
+
+// pluralPairs are the irregular nouns and their plurals
+var pluralPairs = [][2]string{
+    {"child", "children"}, {"man", "men"}, {"woman", "women"}, {"person", "people"},
+    {"foot", "feet"}, {"tooth", "teeth"}, {"mouse", "mice"},
+}
+
+// isPair reports whether two words are the two forms of one of the pairs
+func isPair(pairs [][2]string, a, b string) bool {
+    for _, pair := range pairs {
+        if (a == pair[0] && b == pair[1]) || (a == pair[1] && b == pair[0]) {
+            return true
+        }
+    }
+    return false
+}
+
+func set(words ...string) map[string]bool {
+    m := make(map[string]bool, len(words))
+    for _, word := range words {
+        m[word] = true
+    }
+    return m
+}
+
+// all reports whether every word of the block is in the set
+func (b *block) all(words map[string]bool) bool {
+    for _, list := range [][]token{b.removed, b.added} {
+        for _, t := range list {
+            if !words[t.key] {
+                return false
+            }
+        }
+    }
+    return true
+}
+
+// classify names the kind of error a group of changes corrects in a language. Tense,
+// agreement and plurals are only told apart in English; in other languages a small change
+// to a word is reported as spelling.
+func (b *block) classify(language string) string {
+    switch {
+    case b.moved:
+        return ErrorWordOrder
+    case b.all(articles[language]):
+        return ErrorArticle
+    case b.all(prepositions[language]):
+        return ErrorPreposition
+    case language != "en":
+        return b.classifyWords()
+    case len(b.removed) == 1 && len(b.added) == 1 && isNumberChange(b.removed[0].key, b.added[0].key):
+        return b.numberError()
+    }
+
+    // Auxiliaries are set aside: "I have went" -> "I went" and "I go" -> "I will go"
+    // change the tense, and so do the verb forms left on either side
+    removed, added := withoutAuxiliaries(b.removed), withoutAuxiliaries(b.added)
+    hasAuxiliary := len(removed) < len(b.removed) || len(added) < len(b.added)
+    if len(removed) == len(added) {
+        sameLemmas, numberChange, irregularPlural := true, false, false
+        for i := range removed {
+            lemma := textnorm.Lemma(removed[i])
+            if lemma != textnorm.Lemma(added[i]) {
+                sameLemmas = false
+                break
+            }
+            if isNumberChange(removed[i], added[i]) {
+                numberChange = true
+            }
+            // "childs" -> "children" is a plural even though "childs" is not a word
+            if isPair(pluralPairs, lemma, removed[i]) || isPair(pluralPairs, lemma, added[i]) {
+                irregularPlural = true
+            }
+        }
+        if sameLemmas {
+            switch {
+            case len(removed) == 0 || hasAuxiliary:
+                return ErrorTense
+            case irregularPlural:
+                return ErrorPlural
+            case numberChange:
+                return ErrorAgreement
+            default:
+                return ErrorTense
+            }
+        }
+    }
+    return b.classifyWords()
+}
+
+// classifyWords names a change that is not about grammar words: a misspelt word, a word
+// replaced by another, or words added or removed
+func (b *block) classifyWords() string {
+    if len(b.removed) == 1 && len(b.added) == 1 {
+        original, corrected := b.removed[0].key, b.added[0].key
+        if len(corrected) >= 4 && charDistance(original, corrected) <= 2 {
+            return ErrorSpelling
+        }
+        return ErrorWordChoice
+    }
+    if len(b.removed) > 0 && len(b.added) > 0 {
+        return ErrorWordChoice
+    }
+    return ErrorOther
+}
+
+// numberError tells a verb that does not agree with its subject, "he go", from a noun in
+// the wrong number, "two book". Words after a subject pronoun are taken as verbs.
+func (b *block) numberError() string {
+    original, corrected := b.removed[0].key, b.added[0].key
+    switch {
+    case isPair(agreementPairs, original, corrected):
+        return ErrorAgreement
+    case isPair(pluralPairs, original, corrected):
+        return ErrorPlural
+    case subjects[b.before]:
+        return ErrorAgreement
+    default:
+        return ErrorPlural
+    }
+}
+
+// withoutAuxiliaries returns the normalized words of tokens that are not auxiliaries
+func withoutAuxiliaries(tokens []token) []string {
+    var words []string
+    for _, t := range tokens {
+        if !auxiliaries[t.key] {
+            words = append(words, t.key)
+        }
+    }
+    return words
+}
+
+// isNumberChange reports whether two words are the singular and plural of each other:
+// "is" and "are", "go" and "goes", "city" and "cities"
+func isNumberChange(a, b string) bool {
+    if isPair(agreementPairs, a, b) || isPair(pluralPairs, a, b) {
+        return true
+    }
+    if len(a) > len(b) {
+        a, b = b, a
+    }
+    return b == a+"s" || b == a+"es" ||
+        (strings.HasSuffix(a, "y") && b == strings.TrimSuffix(a, "y")+"ies")
+}
+
+// charDistance is the Levenshtein distance between two words
+func charDistance(a, b string) int {
+    ra, rb := []rune(a), []rune(b)
+    previous := make([]int, len(rb)+1)
+    for j := range previous {
+        previous[j] = j
+    }
+    for i := 1; i <= len(ra); i++ {
+        current := make([]int, len(rb)+1)
+        current[0] = i
+        for j := 1; j <= len(rb); j++ {
+            cost := 1
+            if ra[i-1] == rb[j-1] {
+                cost = 0
+            }
+            current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+        }
+        previous = current
+    }
+    return previous[len(rb)]
+}
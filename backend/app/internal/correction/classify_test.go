@@ -0,0 +1,57 @@
+package correction
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestClassify(t *testing.T) {
+    tests := []struct {
+        original  string
+        corrected string
+        language  string
+        want      []string
+    }{
+        // One sentence per error type
+        {"I saw a elephant", "I saw an elephant", "en", []string{ErrorArticle}},
+        {"Yesterday I go home", "Yesterday I went home", "en", []string{ErrorTense}},
+        {"I go home tomorrow", "I will go home tomorrow", "en", []string{ErrorTense}},
+        {"We arrived in the station", "We arrived at the station", "en", []string{ErrorPreposition}},
+        {"I very like it", "I like it very", "en", []string{ErrorWordOrder, ErrorWordOrder}},
+        {"I always coffee drink", "I always drink coffee", "en", []string{ErrorWordOrder, ErrorWordOrder}},
+        {"They was happy", "They were happy", "en", []string{ErrorAgreement}},
+        {"She play tennis", "She plays tennis", "en", []string{ErrorAgreement}},
+        {"I have two book", "I have two books", "en", []string{ErrorPlural}},
+        {"Three childs came", "Three children came", "en", []string{ErrorPlural}},
+        {"I live in a beautifull house", "I live in a beautiful house", "en", []string{ErrorSpelling}},
+        {"I made a photo", "I took a photo", "en", []string{ErrorWordChoice}},
+        {"It is very nice here", "It is nice here", "en", []string{ErrorOther}},
+
+        // German
+        {"Ich gehe in die Schule mit der Bus", "Ich gehe in die Schule mit dem Bus", "de", []string{ErrorArticle}},
+        {"Ich warte für dich", "Ich warte auf dich", "de", []string{ErrorPreposition}},
+        {"Ich habe Hunger gehabt gestern", "Ich habe gestern Hunger gehabt", "de", []string{ErrorWordOrder, ErrorWordOrder}},
+        {"Das ist ein Problemm", "Das ist ein Problem", "de", []string{ErrorSpelling}},
+        {"Ich mache ein Foto schön", "Ich mache ein Foto gern", "de", []string{ErrorWordChoice}},
+
+        // Spanish
+        {"Tengo un casa", "Tengo una casa", "es", []string{ErrorArticle}},
+        {"Pienso en ti para siempre", "Pienso en ti por siempre", "es", []string{ErrorPreposition}},
+        {"Me gusta mucho la musica", "Me gusta mucho la música", "es", []string{ErrorSpelling}},
+        {"Quiero tomar una fiesta", "Quiero hacer una fiesta", "es", []string{ErrorWordChoice}},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.language+": "+tt.original, func(t *testing.T) {
+            var got []string
+            for _, span := range Diff(tt.original, tt.corrected, tt.language) {
+                if span.Op != OpEqual {
+                    got = append(got, span.Type)
+                }
+            }
+            if !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("types of %q -> %q = %v, want %v", tt.original, tt.corrected, got, tt.want)
+            }
+        })
+    }
+}
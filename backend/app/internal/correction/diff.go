@@ -0,0 +1,266 @@
+package correction
+
+import (
+    "strings"
+
+    "PulpuVOX/internal/textnorm"
+)
+
+// Span operations
+const (
+    OpEqual   = "equal"
+    OpInsert  = "insert"
+    OpDelete  = "delete"
+    OpReplace = "replace"
+)
+
+// Span is a run of words that the correction kept, added, removed or replaced.
+// Original and Corrected hold the words as written on each side; Type classifies
+// the error of a changed span and is empty for equal ones.
+type Span struct {
+    Op        string `json:"op"`
+    Original  string `json:"original,omitempty"`
+    Corrected string `json:"corrected,omitempty"`
+    Type      string `json:"type,omitempty"`
+}
+
+// token is a normalized word of a sentence with the text it was written as. A contraction
+// becomes several tokens, "I'm" -> "i" "am", and only the first carries the written text;
+// word is the position of the written word they come from.
+type token struct {
+    key  string
+    text string
+    word int
+}
+
+// tokenize splits a sentence into tokens compared the way suggestions are: case,
+// punctuation and contractions do not matter
+func tokenize(sentence, language string) []token {
+    var tokens []token
+    for position, word := range strings.Fields(sentence) {
+        for i, key := range strings.Fields(textnorm.Normalize(word, language)) {
+            t := token{key: key, word: position}
+            if i == 0 {
+                t.text = word
+            }
+            tokens = append(tokens, t)
+        }
+    }
+    return tokens
+}
+
+// Diff aligns the words of a sentence in a language, given by its ISO 639-1 code, with
+// those of its correction and returns the spans that cover both, in order. Changed spans
+// are classified by error type.
+func Diff(original, corrected, language string) []Span {
+    a, b := tokenize(original, language), tokenize(corrected, language)
+
+    // lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+    lcs := make([][]int, len(a)+1)
+    for i := range lcs {
+        lcs[i] = make([]int, len(b)+1)
+    }
+    for i := len(a) - 1; i >= 0; i-- {
+        for j := len(b) - 1; j >= 0; j-- {
+            if a[i].key == b[j].key {
+                lcs[i][j] = lcs[i+1][j+1] + 1
+            } else {
+                lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+            }
+        }
+    }
+
+    // Walk the alignment, collecting runs of equal words and blocks of changes
+    var blocks []*block
+    current := func(equal bool) *block {
+        if len(blocks) == 0 || blocks[len(blocks)-1].equal != equal {
+            blocks = append(blocks, &block{equal: equal})
+        }
+        return blocks[len(blocks)-1]
+    }
+    i, j := 0, 0
+    for i < len(a) || j < len(b) {
+        switch {
+        case i < len(a) && j < len(b) && a[i].key == b[j].key:
+            run := current(true)
+            run.removed = append(run.removed, a[i])
+            run.added = append(run.added, b[j])
+            i++
+            j++
+        case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+            change := current(false)
+            change.added = append(change.added, b[j])
+            j++
+        default:
+            change := current(false)
+            change.removed = append(change.removed, a[i])
+            i++
+        }
+    }
+
+    blocks = joinSplitWords(blocks)
+    setBefore(blocks)
+    markMoves(blocks)
+
+    spans := make([]Span, 0, len(blocks))
+    for _, blk := range blocks {
+        span := Span{
+            Original:  text(blk.removed),
+            Corrected: text(blk.added),
+        }
+        switch {
+        case blk.equal:
+            span.Op = OpEqual
+        case len(blk.removed) == 0:
+            span.Op = OpInsert
+        case len(blk.added) == 0:
+            span.Op = OpDelete
+        default:
+            span.Op = OpReplace
+        }
+        if !blk.equal {
+            span.Type = blk.classify(language)
+        }
+        spans = append(spans, span)
+    }
+    return spans
+}
+
+// block is a run of equal words or a group of adjacent changes. before is the corrected
+// word that precedes a change.
+type block struct {
+    equal   bool
+    removed []token
+    added   []token
+    moved   bool
+    before  string
+}
+
+// sameWord reports whether the last token of one list and the first of the next come
+// from the same written word
+func sameWord(last, first []token) bool {
+    return len(last) > 0 && len(first) > 0 && last[len(last)-1].word == first[0].word
+}
+
+// joinSplitWords moves the tokens of a contraction that the alignment split between an
+// equal run and a change into the change, so that the whole written word is shown as
+// changed: "I'm" -> "I was" is one replacement rather than "I'm" kept and "was" replacing
+// nothing. Equal runs left empty are dropped and the changes around them merged.
+func joinSplitWords(blocks []*block) []*block {
+    for k, run := range blocks {
+        if !run.equal {
+            continue
+        }
+        if k+1 < len(blocks) {
+            next := blocks[k+1]
+            for sameWord(run.removed, next.removed) || sameWord(run.added, next.added) {
+                last := len(run.removed) - 1
+                next.removed = append([]token{run.removed[last]}, next.removed...)
+                next.added = append([]token{run.added[last]}, next.added...)
+                run.removed, run.added = run.removed[:last], run.added[:last]
+            }
+        }
+        if k > 0 {
+            prev := blocks[k-1]
+            for sameWord(prev.removed, run.removed) || sameWord(prev.added, run.added) {
+                prev.removed = append(prev.removed, run.removed[0])
+                prev.added = append(prev.added, run.added[0])
+                run.removed, run.added = run.removed[1:], run.added[1:]
+            }
+        }
+    }
+
+    var joined []*block
+    for _, blk := range blocks {
+        if blk.equal && len(blk.removed) == 0 {
+            continue
+        }
+        if n := len(joined); n > 0 && !blk.equal && !joined[n-1].equal {
+            joined[n-1].removed = append(joined[n-1].removed, blk.removed...)
+            joined[n-1].added = append(joined[n-1].added, blk.added...)
+            continue
+        }
+        joined = append(joined, blk)
+    }
+    return joined
+}
+
+// setBefore records the corrected word that precedes each change
+func setBefore(blocks []*block) {
+    before := ""
+    for _, blk := range blocks {
+        if !blk.equal {
+            blk.before = before
+        }
+        if len(blk.added) > 0 {
+            before = blk.added[len(blk.added)-1].key
+        }
+    }
+}
+
+// keys lists the normalized words of tokens
+func keys(tokens []token) []string {
+    list := make([]string, len(tokens))
+    for i, t := range tokens {
+        list[i] = t.key
+    }
+    return list
+}
+
+// text joins the written form of tokens
+func text(tokens []token) string {
+    var words []string
+    for _, t := range tokens {
+        if t.text != "" {
+            words = append(words, t.text)
+        }
+    }
+    return strings.Join(words, " ")
+}
+
+// markMoves flags word order errors: words removed in one place and added back in
+// another, "I very like it" -> "I like it very much", or a replacement that only
+// rearranges the same words
+func markMoves(blocks []*block) {
+    for _, blk := range blocks {
+        if !blk.equal && len(blk.removed) > 1 && len(blk.added) == len(blk.removed) &&
+            sameWords(keys(blk.removed), keys(blk.added)) {
+            blk.moved = true
+        }
+    }
+    for _, removal := range blocks {
+        if removal.equal || removal.moved || len(removal.removed) == 0 || len(removal.added) > 0 {
+            continue
+        }
+        for _, insertion := range blocks {
+            if insertion.equal || insertion.moved || len(insertion.added) == 0 || len(insertion.removed) > 0 {
+                continue
+            }
+            if containsWords(keys(insertion.added), keys(removal.removed)) {
+                removal.moved = true
+                insertion.moved = true
+                break
+            }
+        }
+    }
+}
+
+// containsWords reports whether every word of part is in whole, counting repeats
+func containsWords(whole, part []string) bool {
+    counts := map[string]int{}
+    for _, word := range whole {
+        counts[word]++
+    }
+    for _, word := range part {
+        counts[word]--
+        if counts[word] < 0 {
+            return false
+        }
+    }
+    return true
+}
+
+// sameWords reports whether two lists hold the same words, in any order
+func sameWords(a, b []string) bool {
+    return len(a) == len(b) && containsWords(a, b)
+}
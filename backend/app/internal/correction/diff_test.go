@@ -0,0 +1,131 @@
+package correction
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestDiff(t *testing.T) {
+    tests := []struct {
+        name      string
+        original  string
+        corrected string
+        language  string
+        want      []Span
+    }{
+        {
+            name:      "unchanged sentence ignores case and punctuation",
+            original:  "i like tea",
+            corrected: "I like tea.",
+            language:  "en",
+            want: []Span{
+                {Op: OpEqual, Original: "i like tea", Corrected: "I like tea."},
+            },
+        },
+        {
+            name:      "insert",
+            original:  "I went to cinema",
+            corrected: "I went to the cinema",
+            language:  "en",
+            want: []Span{
+                {Op: OpEqual, Original: "I went to", Corrected: "I went to"},
+                {Op: OpInsert, Corrected: "the", Type: ErrorArticle},
+                {Op: OpEqual, Original: "cinema", Corrected: "cinema"},
+            },
+        },
+        {
+            name:      "delete",
+            original:  "I have went home",
+            corrected: "I went home",
+            language:  "en",
+            want: []Span{
+                {Op: OpEqual, Original: "I", Corrected: "I"},
+                {Op: OpDelete, Original: "have", Type: ErrorTense},
+                {Op: OpEqual, Original: "went home", Corrected: "went home"},
+            },
+        },
+        {
+            name:      "replace",
+            original:  "He go to work",
+            corrected: "He goes to work",
+            language:  "en",
+            want: []Span{
+                {Op: OpEqual, Original: "He", Corrected: "He"},
+                {Op: OpReplace, Original: "go", Corrected: "goes", Type: ErrorAgreement},
+                {Op: OpEqual, Original: "to work", Corrected: "to work"},
+            },
+        },
+        {
+            name:      "moved words",
+            original:  "I very like it",
+            corrected: "I like it very",
+            language:  "en",
+            want: []Span{
+                {Op: OpEqual, Original: "I", Corrected: "I"},
+                {Op: OpDelete, Original: "very", Type: ErrorWordOrder},
+                {Op: OpEqual, Original: "like it", Corrected: "like it"},
+                {Op: OpInsert, Corrected: "very", Type: ErrorWordOrder},
+            },
+        },
+        {
+            name:      "contraction split keeps the written word",
+            original:  "I'm tired yesterday",
+            corrected: "I was tired yesterday",
+            language:  "en",
+            want: []Span{
+                {Op: OpReplace, Original: "I'm", Corrected: "I was", Type: ErrorTense},
+                {Op: OpEqual, Original: "tired yesterday", Corrected: "tired yesterday"},
+            },
+        },
+        {
+            name:      "contraction split by a deletion",
+            original:  "I don't like it",
+            corrected: "I do like it",
+            language:  "en",
+            want: []Span{
+                {Op: OpEqual, Original: "I", Corrected: "I"},
+                {Op: OpReplace, Original: "don't", Corrected: "do", Type: ErrorWordChoice},
+                {Op: OpEqual, Original: "like it", Corrected: "like it"},
+            },
+        },
+        {
+            name:      "expanded contraction is unchanged",
+            original:  "I'm going home",
+            corrected: "I am going home.",
+            language:  "en",
+            want: []Span{
+                {Op: OpEqual, Original: "I'm going home", Corrected: "I am going home."},
+            },
+        },
+        {
+            name:      "German article",
+            original:  "Ich sehe der Hund",
+            corrected: "Ich sehe den Hund",
+            language:  "de",
+            want: []Span{
+                {Op: OpEqual, Original: "Ich sehe", Corrected: "Ich sehe"},
+                {Op: OpReplace, Original: "der", Corrected: "den", Type: ErrorArticle},
+                {Op: OpEqual, Original: "Hund", Corrected: "Hund"},
+            },
+        },
+        {
+            name:      "Spanish preposition",
+            original:  "Voy en Madrid",
+            corrected: "Voy a Madrid",
+            language:  "es",
+            want: []Span{
+                {Op: OpEqual, Original: "Voy", Corrected: "Voy"},
+                {Op: OpReplace, Original: "en", Corrected: "a", Type: ErrorPreposition},
+                {Op: OpEqual, Original: "Madrid", Corrected: "Madrid"},
+            },
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := Diff(tt.original, tt.corrected, tt.language); !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("Diff(%q, %q) =\n%+v\nwant\n%+v", tt.original, tt.corrected, got, tt.want)
+            }
+        })
+    }
+}
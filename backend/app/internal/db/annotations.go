@@ -0,0 +1,189 @@
+package db
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// Verdicts a teacher can give the suggested correction of a turn
+const (
+    CorrectionApproved   = "approved"
+    CorrectionOverridden = "overridden"
+)
+
+// TurnAnnotation is a teacher's comment on a student turn of a saved conversation. The
+// correction status is empty, approved or overridden; an overridden correction is replaced
+// by the teacher's correction.
+type TurnAnnotation struct {
+    ID               int       `json:"id"`
+    ConversationID   int       `json:"conversation_id"`
+    TurnIndex        int       `json:"turn_index"`
+    TeacherID        int       `json:"teacher_id"`
+    TeacherName      string    `json:"teacher_name"`
+    Comment          string    `json:"comment"`
+    CorrectionStatus string    `json:"correction_status"`
+    Correction       string    `json:"correction"`
+    CreatedAt        time.Time `json:"created_at"`
+    UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// ConversationGrade is the CEFR level a teacher gave a saved conversation. It takes
+// precedence over the level of the graded feedback report.
+type ConversationGrade struct {
+    ConversationID int       `json:"conversation_id"`
+    TeacherID      int       `json:"teacher_id"`
+    TeacherName    string    `json:"teacher_name"`
+    Level          string    `json:"level"`
+    Comment        string    `json:"comment"`
+    CreatedAt      time.Time `json:"created_at"`
+    UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ListTurnAnnotations returns the annotations of a conversation in turn order
+func ListTurnAnnotations(ctx context.Context, conn *pgx.Conn, conversationID int) ([]TurnAnnotation, error) {
+    rows, err := conn.Query(ctx, `
+        SELECT a.id, a.conversation_id, a.turn_index, a.teacher_id, COALESCE(t.name, ''),
+            a.comment, a.correction_status, a.correction, a.created_at, a.updated_at
+        FROM turn_annotations a
+        LEFT JOIN users t ON t.id = a.teacher_id
+        WHERE a.conversation_id = $1
+        ORDER BY a.turn_index`,
+        conversationID,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    annotations := []TurnAnnotation{}
+    for rows.Next() {
+        var annotation TurnAnnotation
+        err := rows.Scan(&annotation.ID, &annotation.ConversationID, &annotation.TurnIndex, &annotation.TeacherID,
+            &annotation.TeacherName, &annotation.Comment, &annotation.CorrectionStatus, &annotation.Correction,
+            &annotation.CreatedAt, &annotation.UpdatedAt)
+        if err != nil {
+            return nil, fmt.Errorf("database scan error: %w", err)
+        }
+        annotations = append(annotations, annotation)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return annotations, nil
+}
+
+// ApplyAnnotations returns a copy of a conversation's history in which the teacher has the
+// final word on corrections: an overridden correction is replaced by the teacher's, whose
+// changes are diffed again. An override that repeats the student's sentence rejects the
+// correction, leaving nothing to practise.
+func ApplyAnnotations(history []ConversationTurn, annotations []TurnAnnotation) []ConversationTurn {
+    corrected := make([]ConversationTurn, len(history))
+    copy(corrected, history)
+    for _, annotation := range annotations {
+        if annotation.CorrectionStatus != CorrectionOverridden || annotation.TurnIndex < 0 || annotation.TurnIndex >= len(corrected) {
+            continue
+        }
+        turn := &corrected[annotation.TurnIndex]
+        turn.Suggestion = annotation.Correction
+        turn.SuggestionDiff = nil
+        turn.Explanation = ""
+    }
+    return corrected
+}
+
+// CorrectedHistory returns the history of a saved conversation with its teacher's
+// annotations applied
+func CorrectedHistory(ctx context.Context, conn *pgx.Conn, conversation *Conversation) ([]ConversationTurn, error) {
+    annotations, err := ListTurnAnnotations(ctx, conn, conversation.ID)
+    if err != nil {
+        return nil, err
+    }
+    return ApplyAnnotations(conversation.History, annotations), nil
+}
+
+// SaveTurnAnnotation stores the annotation of a turn, replacing any earlier one
+func SaveTurnAnnotation(ctx context.Context, conn *pgx.Conn, annotation *TurnAnnotation) error {
+    err := conn.QueryRow(ctx, `
+        INSERT INTO turn_annotations (conversation_id, turn_index, teacher_id, comment, correction_status, correction)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (conversation_id, turn_index) DO UPDATE SET
+            teacher_id = EXCLUDED.teacher_id,
+            comment = EXCLUDED.comment,
+            correction_status = EXCLUDED.correction_status,
+            correction = EXCLUDED.correction,
+            updated_at = CURRENT_TIMESTAMP
+        RETURNING id, created_at, updated_at`,
+        annotation.ConversationID, annotation.TurnIndex, annotation.TeacherID,
+        annotation.Comment, annotation.CorrectionStatus, annotation.Correction,
+    ).Scan(&annotation.ID, &annotation.CreatedAt, &annotation.UpdatedAt)
+    if err != nil {
+        return fmt.Errorf("database upsert error: %w", err)
+    }
+    return nil
+}
+
+// DeleteTurnAnnotation removes the annotation of a turn
+func DeleteTurnAnnotation(ctx context.Context, conn *pgx.Conn, conversationID, turnIndex int) error {
+    result, err := conn.Exec(ctx,
+        "DELETE FROM turn_annotations WHERE conversation_id = $1 AND turn_index = $2",
+        conversationID, turnIndex,
+    )
+    if err != nil {
+        return fmt.Errorf("database delete error: %w", err)
+    }
+    if result.RowsAffected() == 0 {
+        return pgx.ErrNoRows
+    }
+    return nil
+}
+
+// GetConversationGrade returns the teacher's grade of a conversation
+func GetConversationGrade(ctx context.Context, conn *pgx.Conn, conversationID int) (*ConversationGrade, error) {
+    var grade ConversationGrade
+    err := conn.QueryRow(ctx, `
+        SELECT g.conversation_id, g.teacher_id, COALESCE(t.name, ''), g.level, g.comment, g.created_at, g.updated_at
+        FROM conversation_grades g
+        LEFT JOIN users t ON t.id = g.teacher_id
+        WHERE g.conversation_id = $1`,
+        conversationID,
+    ).Scan(&grade.ConversationID, &grade.TeacherID, &grade.TeacherName, &grade.Level, &grade.Comment,
+        &grade.CreatedAt, &grade.UpdatedAt)
+    if err != nil {
+        return nil, err
+    }
+    return &grade, nil
+}
+
+// SaveConversationGrade stores the teacher's grade of a conversation, replacing any earlier one
+func SaveConversationGrade(ctx context.Context, conn *pgx.Conn, grade *ConversationGrade) error {
+    err := conn.QueryRow(ctx, `
+        INSERT INTO conversation_grades (conversation_id, teacher_id, level, comment)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (conversation_id) DO UPDATE SET
+            teacher_id = EXCLUDED.teacher_id,
+            level = EXCLUDED.level,
+            comment = EXCLUDED.comment,
+            updated_at = CURRENT_TIMESTAMP
+        RETURNING created_at, updated_at`,
+        grade.ConversationID, grade.TeacherID, grade.Level, grade.Comment,
+    ).Scan(&grade.CreatedAt, &grade.UpdatedAt)
+    if err != nil {
+        return fmt.Errorf("database upsert error: %w", err)
+    }
+    return nil
+}
+
+// DeleteConversationGrade removes the teacher's grade of a conversation
+func DeleteConversationGrade(ctx context.Context, conn *pgx.Conn, conversationID int) error {
+    result, err := conn.Exec(ctx, "DELETE FROM conversation_grades WHERE conversation_id = $1", conversationID)
+    if err != nil {
+        return fmt.Errorf("database delete error: %w", err)
+    }
+    if result.RowsAffected() == 0 {
+        return pgx.ErrNoRows
+    }
+    return nil
+}
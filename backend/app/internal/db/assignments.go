@@ -0,0 +1,275 @@
+package db
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// Assignment is work a teacher sets a class: a conversation in a mode, on a topic or in a
+// scenario, of at least a number of turns by a due date
+type Assignment struct {
+    ID            int       `json:"id"`
+    ClassID       int       `json:"class_id"`
+    ClassName     string    `json:"class_name"`
+    TeacherID     int       `json:"teacher_id"`
+    Title         string    `json:"title"`
+    Mode          string    `json:"mode"`
+    ScenarioID    *int      `json:"scenario_id"`
+    ScenarioTitle *string   `json:"scenario_title"`
+    Topic         string    `json:"topic"`
+    Language      string    `json:"language"`
+    MinTurns      int       `json:"min_turns"`
+    DueAt         time.Time `json:"due_at"`
+    CreatedAt     time.Time `json:"created_at"`
+}
+
+// AssignmentSummary is an assignment with how many students of its class have completed it
+type AssignmentSummary struct {
+    Assignment
+    StudentCount   int `json:"student_count"`
+    CompletedCount int `json:"completed_count"`
+}
+
+// AssignmentProgress is what a student has submitted for an assignment: the number of
+// conversations, the most turns in one, when it was first completed and the conversation
+// that best shows it. Status is worked out from these by the assignment package.
+type AssignmentProgress struct {
+    Attempts       int        `json:"attempts"`
+    BestTurns      int        `json:"best_turns"`
+    CompletedAt    *time.Time `json:"completed_at"`
+    ConversationID *int       `json:"conversation_id"`
+    Status         string     `json:"status"`
+}
+
+// StudentAssignment is an assignment with the progress of one student
+type StudentAssignment struct {
+    Assignment
+    Progress AssignmentProgress `json:"progress"`
+}
+
+// AssignmentStudent is a student of an assignment's class with their progress
+type AssignmentStudent struct {
+    UserID   int                `json:"user_id"`
+    Name     string             `json:"name"`
+    Email    string             `json:"email"`
+    Progress AssignmentProgress `json:"progress"`
+}
+
+// AssignmentSubmission is a conversation saved from an assignment
+type AssignmentSubmission struct {
+    ConversationID int
+    AssignmentID   int
+    UserID         int
+    TurnCount      int
+    Completed      bool
+    SubmittedAt    time.Time
+}
+
+// assignmentColumns are the columns read by scanAssignment, from assignments a joined with
+// their class c and scenario s
+const assignmentColumns = `a.id, a.class_id, c.name, c.teacher_id, a.title, a.mode, a.scenario_id, s.title,
+    a.topic, a.language, a.min_turns, a.due_at, a.created_at`
+
+// assignmentTables are the tables assignmentColumns are selected from
+const assignmentTables = `assignments a
+    JOIN classes c ON c.id = a.class_id
+    LEFT JOIN scenarios s ON s.id = a.scenario_id`
+
+// progressColumns aggregate the submissions sub of a student into an AssignmentProgress
+const progressColumns = `COUNT(sub.conversation_id), COALESCE(MAX(sub.turn_count), 0),
+    MIN(sub.submitted_at) FILTER (WHERE sub.completed),
+    (ARRAY_AGG(sub.conversation_id ORDER BY sub.completed DESC, sub.turn_count DESC, sub.submitted_at))[1]`
+
+// assignmentFields returns the scan destinations of assignmentColumns
+func assignmentFields(a *Assignment) []interface{} {
+    return []interface{}{
+        &a.ID, &a.ClassID, &a.ClassName, &a.TeacherID, &a.Title, &a.Mode, &a.ScenarioID, &a.ScenarioTitle,
+        &a.Topic, &a.Language, &a.MinTurns, &a.DueAt, &a.CreatedAt,
+    }
+}
+
+// progressFields returns the scan destinations of progressColumns
+func progressFields(p *AssignmentProgress) []interface{} {
+    return []interface{}{&p.Attempts, &p.BestTurns, &p.CompletedAt, &p.ConversationID}
+}
+
+// CreateAssignment stores a new assignment, setting its ID and creation time
+func CreateAssignment(ctx context.Context, conn *pgx.Conn, assignment *Assignment) error {
+    err := conn.QueryRow(ctx, `
+        INSERT INTO assignments (class_id, title, mode, scenario_id, topic, language, min_turns, due_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id, created_at`,
+        assignment.ClassID, assignment.Title, assignment.Mode, assignment.ScenarioID, assignment.Topic,
+        assignment.Language, assignment.MinTurns, assignment.DueAt,
+    ).Scan(&assignment.ID, &assignment.CreatedAt)
+    if err != nil {
+        return fmt.Errorf("database insert error: %w", err)
+    }
+    return nil
+}
+
+// GetAssignment returns an assignment by its ID
+func GetAssignment(ctx context.Context, conn *pgx.Conn, assignmentID int) (*Assignment, error) {
+    var assignment Assignment
+    err := conn.QueryRow(ctx, `
+        SELECT `+assignmentColumns+`
+        FROM `+assignmentTables+`
+        WHERE a.id = $1`,
+        assignmentID,
+    ).Scan(assignmentFields(&assignment)...)
+    if err != nil {
+        return nil, err
+    }
+    return &assignment, nil
+}
+
+// GetStudentAssignment returns an assignment if the user is a student of its class
+func GetStudentAssignment(ctx context.Context, conn *pgx.Conn, assignmentID, userID int) (*Assignment, error) {
+    var assignment Assignment
+    err := conn.QueryRow(ctx, `
+        SELECT `+assignmentColumns+`
+        FROM `+assignmentTables+`
+        JOIN class_members m ON m.class_id = a.class_id
+        WHERE a.id = $1 AND m.user_id = $2`,
+        assignmentID, userID,
+    ).Scan(assignmentFields(&assignment)...)
+    if err != nil {
+        return nil, err
+    }
+    return &assignment, nil
+}
+
+// ListClassAssignments returns the assignments of a class by due date, with how many of
+// its students have completed each
+func ListClassAssignments(ctx context.Context, conn *pgx.Conn, classID int) ([]AssignmentSummary, error) {
+    rows, err := conn.Query(ctx, `
+        SELECT `+assignmentColumns+`,
+            (SELECT COUNT(*) FROM class_members m WHERE m.class_id = a.class_id),
+            (SELECT COUNT(DISTINCT sub.user_id) FROM assignment_submissions sub
+                JOIN class_members m ON m.class_id = a.class_id AND m.user_id = sub.user_id
+                WHERE sub.assignment_id = a.id AND sub.completed)
+        FROM `+assignmentTables+`
+        WHERE a.class_id = $1
+        ORDER BY a.due_at DESC, a.id DESC`,
+        classID,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    assignments := []AssignmentSummary{}
+    for rows.Next() {
+        var summary AssignmentSummary
+        fields := append(assignmentFields(&summary.Assignment), &summary.StudentCount, &summary.CompletedCount)
+        if err := rows.Scan(fields...); err != nil {
+            return nil, fmt.Errorf("database scan error: %w", err)
+        }
+        assignments = append(assignments, summary)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return assignments, nil
+}
+
+// ListStudentAssignments returns the assignments of every class the user is a student of,
+// soonest due first, with what they have submitted for each
+func ListStudentAssignments(ctx context.Context, conn *pgx.Conn, userID int) ([]StudentAssignment, error) {
+    rows, err := conn.Query(ctx, `
+        SELECT `+assignmentColumns+`, `+progressColumns+`
+        FROM `+assignmentTables+`
+        JOIN class_members m ON m.class_id = a.class_id
+        LEFT JOIN assignment_submissions sub ON sub.assignment_id = a.id AND sub.user_id = m.user_id
+        WHERE m.user_id = $1
+        GROUP BY a.id, c.id, s.id
+        ORDER BY a.due_at, a.id`,
+        userID,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    assignments := []StudentAssignment{}
+    for rows.Next() {
+        var assignment StudentAssignment
+        fields := append(assignmentFields(&assignment.Assignment), progressFields(&assignment.Progress)...)
+        if err := rows.Scan(fields...); err != nil {
+            return nil, fmt.Errorf("database scan error: %w", err)
+        }
+        assignments = append(assignments, assignment)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return assignments, nil
+}
+
+// ListAssignmentStudents returns every student of an assignment's class by name, with what
+// they have submitted for it
+func ListAssignmentStudents(ctx context.Context, conn *pgx.Conn, assignmentID int) ([]AssignmentStudent, error) {
+    rows, err := conn.Query(ctx, `
+        SELECT u.id, COALESCE(u.name, ''), COALESCE(u.email, ''), `+progressColumns+`
+        FROM assignments a
+        JOIN class_members m ON m.class_id = a.class_id
+        JOIN users u ON u.id = m.user_id
+        LEFT JOIN assignment_submissions sub ON sub.assignment_id = a.id AND sub.user_id = u.id
+        WHERE a.id = $1
+        GROUP BY u.id
+        ORDER BY u.name, u.id`,
+        assignmentID,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    students := []AssignmentStudent{}
+    for rows.Next() {
+        var student AssignmentStudent
+        fields := append([]interface{}{&student.UserID, &student.Name, &student.Email}, progressFields(&student.Progress)...)
+        if err := rows.Scan(fields...); err != nil {
+            return nil, fmt.Errorf("database scan error: %w", err)
+        }
+        students = append(students, student)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return students, nil
+}
+
+// DeleteAssignment removes an assignment and its submissions. Conversations started from
+// it are kept.
+func DeleteAssignment(ctx context.Context, conn *pgx.Conn, assignmentID int) error {
+    result, err := conn.Exec(ctx, "DELETE FROM assignments WHERE id = $1", assignmentID)
+    if err != nil {
+        return fmt.Errorf("database delete error: %w", err)
+    }
+    if result.RowsAffected() == 0 {
+        return pgx.ErrNoRows
+    }
+    return nil
+}
+
+// SaveAssignmentSubmission stores whether a conversation completes its assignment,
+// replacing an earlier result for the same conversation
+func SaveAssignmentSubmission(ctx context.Context, conn *pgx.Conn, submission *AssignmentSubmission) error {
+    _, err := conn.Exec(ctx, `
+        INSERT INTO assignment_submissions (conversation_id, assignment_id, user_id, turn_count, completed, submitted_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (conversation_id) DO UPDATE SET
+            turn_count = EXCLUDED.turn_count,
+            completed = EXCLUDED.completed`,
+        submission.ConversationID, submission.AssignmentID, submission.UserID, submission.TurnCount,
+        submission.Completed, submission.SubmittedAt,
+    )
+    if err != nil {
+        return fmt.Errorf("database insert error: %w", err)
+    }
+    return nil
+}
@@ -0,0 +1,239 @@
+package db
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// Class is a teacher's class with the number of students in it
+type Class struct {
+    ID           int       `json:"id"`
+    TeacherID    int       `json:"teacher_id"`
+    TeacherName  string    `json:"teacher_name"`
+    Name         string    `json:"name"`
+    InviteCode   string    `json:"invite_code,omitempty"`
+    StudentCount int       `json:"student_count"`
+    CreatedAt    time.Time `json:"created_at"`
+}
+
+// ClassMember is a student of a class with a summary of their practice
+type ClassMember struct {
+    UserID             int        `json:"user_id"`
+    Name               string     `json:"name"`
+    Email              string     `json:"email"`
+    PictureLink        string     `json:"picture_link"`
+    JoinedAt           time.Time  `json:"joined_at"`
+    ConversationCount  int        `json:"conversation_count"`
+    LastConversationAt *time.Time `json:"last_conversation_at"`
+}
+
+// ErrInviteCodeTaken is returned when a new invite code is already used by another class
+var ErrInviteCodeTaken = errors.New("invite code is already in use")
+
+// classColumns are the columns read by scanClass, from classes c joined with their teacher t
+const classColumns = `c.id, c.teacher_id, COALESCE(t.name, ''), c.name, c.invite_code,
+    (SELECT COUNT(*) FROM class_members m WHERE m.class_id = c.id), c.created_at`
+
+// scanClass reads a class selected with classColumns
+func scanClass(row pgx.Row) (*Class, error) {
+    var class Class
+    err := row.Scan(&class.ID, &class.TeacherID, &class.TeacherName, &class.Name, &class.InviteCode,
+        &class.StudentCount, &class.CreatedAt)
+    if err != nil {
+        return nil, err
+    }
+    return &class, nil
+}
+
+// queryClasses runs a query selecting classColumns and reads every class
+func queryClasses(ctx context.Context, conn *pgx.Conn, sql string, args ...interface{}) ([]Class, error) {
+    rows, err := conn.Query(ctx, sql, args...)
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    classes := []Class{}
+    for rows.Next() {
+        class, err := scanClass(rows)
+        if err != nil {
+            return nil, fmt.Errorf("database scan error: %w", err)
+        }
+        classes = append(classes, *class)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return classes, nil
+}
+
+// CreateClass creates a class taught by the teacher, returning ErrInviteCodeTaken if
+// another class has the invite code
+func CreateClass(ctx context.Context, conn *pgx.Conn, teacherID int, name, inviteCode string) (*Class, error) {
+    var classID int
+    err := conn.QueryRow(ctx, `
+        INSERT INTO classes (teacher_id, name, invite_code)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (invite_code) DO NOTHING
+        RETURNING id`,
+        teacherID, name, inviteCode,
+    ).Scan(&classID)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            return nil, ErrInviteCodeTaken
+        }
+        return nil, fmt.Errorf("database insert error: %w", err)
+    }
+    return GetClass(ctx, conn, classID)
+}
+
+// GetClass returns a class by its ID
+func GetClass(ctx context.Context, conn *pgx.Conn, classID int) (*Class, error) {
+    return scanClass(conn.QueryRow(ctx, `
+        SELECT `+classColumns+`
+        FROM classes c
+        JOIN users t ON t.id = c.teacher_id
+        WHERE c.id = $1`,
+        classID,
+    ))
+}
+
+// ListClasses returns the classes taught by the teacher, or every class when teacherID
+// is nil, by name
+func ListClasses(ctx context.Context, conn *pgx.Conn, teacherID *int) ([]Class, error) {
+    return queryClasses(ctx, conn, `
+        SELECT `+classColumns+`
+        FROM classes c
+        JOIN users t ON t.id = c.teacher_id
+        WHERE $1::integer IS NULL OR c.teacher_id = $1
+        ORDER BY c.name, c.id`,
+        teacherID,
+    )
+}
+
+// ListJoinedClasses returns the classes the user is a student of, without their invite codes
+func ListJoinedClasses(ctx context.Context, conn *pgx.Conn, userID int) ([]Class, error) {
+    classes, err := queryClasses(ctx, conn, `
+        SELECT `+classColumns+`
+        FROM classes c
+        JOIN users t ON t.id = c.teacher_id
+        JOIN class_members m ON m.class_id = c.id
+        WHERE m.user_id = $1
+        ORDER BY c.name, c.id`,
+        userID,
+    )
+    for i := range classes {
+        classes[i].InviteCode = ""
+    }
+    return classes, err
+}
+
+// SetInviteCode replaces the invite code of a class, returning ErrInviteCodeTaken if
+// another class has it. The old code stops working.
+func SetInviteCode(ctx context.Context, conn *pgx.Conn, classID int, inviteCode string) error {
+    result, err := conn.Exec(ctx, `
+        UPDATE classes SET invite_code = $2, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM classes WHERE invite_code = $2)`,
+        classID, inviteCode,
+    )
+    if err != nil {
+        return fmt.Errorf("database update error: %w", err)
+    }
+    if result.RowsAffected() == 0 {
+        return ErrInviteCodeTaken
+    }
+    return nil
+}
+
+// JoinClass adds the user to the class with the invite code, returning pgx.ErrNoRows if
+// there is none. Joining a class twice is not an error.
+func JoinClass(ctx context.Context, conn *pgx.Conn, userID int, inviteCode string) (*Class, error) {
+    var classID int
+    err := conn.QueryRow(ctx, "SELECT id FROM classes WHERE invite_code = $1", inviteCode).Scan(&classID)
+    if err != nil {
+        return nil, err
+    }
+
+    _, err = conn.Exec(ctx, `
+        INSERT INTO class_members (class_id, user_id)
+        VALUES ($1, $2)
+        ON CONFLICT (class_id, user_id) DO NOTHING`,
+        classID, userID,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("database insert error: %w", err)
+    }
+
+    class, err := GetClass(ctx, conn, classID)
+    if err != nil {
+        return nil, err
+    }
+    class.InviteCode = ""
+    return class, nil
+}
+
+// ListClassMembers returns the students of a class by name
+func ListClassMembers(ctx context.Context, conn *pgx.Conn, classID int) ([]ClassMember, error) {
+    rows, err := conn.Query(ctx, `
+        SELECT u.id, COALESCE(u.name, ''), COALESCE(u.email, ''), COALESCE(u.picture_link, ''), m.joined_at,
+            (SELECT COUNT(*) FROM conversations c WHERE c.user_id = u.id),
+            (SELECT MAX(c.created_at) FROM conversations c WHERE c.user_id = u.id)
+        FROM class_members m
+        JOIN users u ON u.id = m.user_id
+        WHERE m.class_id = $1
+        ORDER BY u.name, u.id`,
+        classID,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    members := []ClassMember{}
+    for rows.Next() {
+        var member ClassMember
+        if err := rows.Scan(&member.UserID, &member.Name, &member.Email, &member.PictureLink, &member.JoinedAt,
+            &member.ConversationCount, &member.LastConversationAt); err != nil {
+            return nil, fmt.Errorf("database scan error: %w", err)
+        }
+        members = append(members, member)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return members, nil
+}
+
+// RemoveClassMember removes a student from a class, returning pgx.ErrNoRows if they are
+// not in it
+func RemoveClassMember(ctx context.Context, conn *pgx.Conn, classID, userID int) error {
+    result, err := conn.Exec(ctx, "DELETE FROM class_members WHERE class_id = $1 AND user_id = $2", classID, userID)
+    if err != nil {
+        return fmt.Errorf("database delete error: %w", err)
+    }
+    if result.RowsAffected() == 0 {
+        return pgx.ErrNoRows
+    }
+    return nil
+}
+
+// TeachesStudent reports whether the student is in one of the teacher's classes
+func TeachesStudent(ctx context.Context, conn *pgx.Conn, teacherID, studentID int) (bool, error) {
+    var teaches bool
+    err := conn.QueryRow(ctx, `
+        SELECT EXISTS (
+            SELECT 1 FROM class_members m
+            JOIN classes c ON c.id = m.class_id
+            WHERE c.teacher_id = $1 AND m.user_id = $2
+        )`,
+        teacherID, studentID,
+    ).Scan(&teaches)
+    if err != nil {
+        return false, fmt.Errorf("database query error: %w", err)
+    }
+    return teaches, nil
+}
@@ -0,0 +1,351 @@
+package db
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "regexp"
+    "time"
+
+    "PulpuVOX/internal/correction"
+    "PulpuVOX/internal/pronunciation"
+    "github.com/jackc/pgx/v5"
+)
+
+// ConversationTurn represents a single turn in the conversation
+type ConversationTurn struct {
+    Role string `json:"role"`
+    Content string `json:"content"`
+    Suggestion string `json:"suggestion,omitempty"`
+    UserName string `json:"user_name,omitempty"`
+    Pronunciation *pronunciation.Analysis `json:"pronunciation,omitempty"`
+    SuggestionDiff []correction.Span `json:"suggestion_diff,omitempty"`
+    // Explanation says why the sentence was corrected, in the user's native language
+    Explanation string `json:"explanation,omitempty"`
+}
+
+// ConversationSession is a conversation in progress whose history is owned by the server
+type ConversationSession struct {
+    ID                  string
+    UserID              int
+    History             []ConversationTurn
+    Status              string
+    ScenarioID          *int
+    ScenarioCompletedAt *time.Time
+    AssignmentID        *int
+    Language            string
+    CreatedAt           time.Time
+    UpdatedAt           time.Time
+}
+
+// Conversation is a finished conversation saved from a session
+type Conversation struct {
+    ID                int
+    UserID            int
+    SessionID         *string
+    ScenarioID        *int
+    ScenarioCompleted bool
+    AssignmentID      *int
+    Language          string
+    History           []ConversationTurn
+    CreatedAt         time.Time
+}
+
+// ConversationSummary describes a saved conversation in a list. The level is the teacher's
+// grade if there is one, otherwise the level of the feedback report.
+type ConversationSummary struct {
+    ID        int       `json:"id"`
+    CreatedAt time.Time `json:"created_at"`
+    TurnCount int       `json:"turn_count"`
+    Preview   string    `json:"preview"`
+    Level     *string   `json:"level"`
+}
+
+// ConversationFilter selects a page of a user's conversations. From and To bound the
+// creation time when set; To is exclusive.
+type ConversationFilter struct {
+    From   *time.Time
+    To     *time.Time
+    Limit  int
+    Offset int
+}
+
+// Session statuses
+const (
+    SessionActive = "active"
+    SessionEnded  = "ended"
+)
+
+// ErrSessionEnded is returned when turns are appended to a session that is no longer active
+var ErrSessionEnded = errors.New("conversation session has ended")
+
+// sessionIDPattern matches the UUIDs used as session IDs
+var sessionIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// GetUserIDByProviderID returns the internal ID of the user authenticated by the given provider
+func GetUserIDByProviderID(ctx context.Context, conn *pgx.Conn, provider, idByProvider string) (int, error) {
+    var userID int
+    err := conn.QueryRow(ctx,
+        "SELECT id FROM users WHERE provider = $1 AND id_by_provider = $2",
+        provider, idByProvider,
+    ).Scan(&userID)
+    if err != nil {
+        return 0, err
+    }
+    return userID, nil
+}
+
+// CreateSession opens a new conversation session in a language starting with the given
+// turns, optionally set in a scenario and started from an assignment
+func CreateSession(ctx context.Context, conn *pgx.Conn, userID int, scenarioID, assignmentID *int, language string, history []ConversationTurn) (string, error) {
+    if history == nil {
+        history = []ConversationTurn{}
+    }
+    historyJSON, err := json.Marshal(history)
+    if err != nil {
+        return "", fmt.Errorf("failed to marshal history: %w", err)
+    }
+
+    var sessionID string
+    err = conn.QueryRow(ctx,
+        "INSERT INTO conversation_sessions (user_id, scenario_id, assignment_id, language, history) VALUES ($1, $2, $3, $4, $5) RETURNING id::text",
+        userID, scenarioID, assignmentID, language, historyJSON,
+    ).Scan(&sessionID)
+    if err != nil {
+        return "", fmt.Errorf("database insert error: %w", err)
+    }
+    return sessionID, nil
+}
+
+// GetSession returns the session with the given ID if it belongs to the user
+func GetSession(ctx context.Context, conn *pgx.Conn, sessionID string, userID int) (*ConversationSession, error) {
+    if !sessionIDPattern.MatchString(sessionID) {
+        return nil, pgx.ErrNoRows
+    }
+
+    var session ConversationSession
+    var historyJSON []byte
+    err := conn.QueryRow(ctx, `
+        SELECT id::text, user_id, history, status, scenario_id, scenario_completed_at, assignment_id, language,
+            created_at, updated_at
+        FROM conversation_sessions
+        WHERE id = $1::uuid AND user_id = $2`,
+        sessionID, userID,
+    ).Scan(
+        &session.ID, &session.UserID, &historyJSON, &session.Status,
+        &session.ScenarioID, &session.ScenarioCompletedAt, &session.AssignmentID, &session.Language,
+        &session.CreatedAt, &session.UpdatedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(historyJSON, &session.History); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal history: %w", err)
+    }
+    return &session, nil
+}
+
+// AppendSessionTurns appends turns to the history of an active session
+func AppendSessionTurns(ctx context.Context, conn *pgx.Conn, sessionID string, turns ...ConversationTurn) error {
+    if !sessionIDPattern.MatchString(sessionID) {
+        return ErrSessionEnded
+    }
+
+    turnsJSON, err := json.Marshal(turns)
+    if err != nil {
+        return fmt.Errorf("failed to marshal turns: %w", err)
+    }
+
+    result, err := conn.Exec(ctx, `
+        UPDATE conversation_sessions
+        SET history = history || $2::jsonb, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1::uuid AND status = $3`,
+        sessionID, turnsJSON, SessionActive,
+    )
+    if err != nil {
+        return fmt.Errorf("database update error: %w", err)
+    }
+    if result.RowsAffected() == 0 {
+        return ErrSessionEnded
+    }
+    return nil
+}
+
+// CompleteSessionScenario records that the learner reached the goal of the session's
+// scenario. It reports whether this call was the one that completed it.
+func CompleteSessionScenario(ctx context.Context, conn *pgx.Conn, sessionID string) (bool, error) {
+    if !sessionIDPattern.MatchString(sessionID) {
+        return false, pgx.ErrNoRows
+    }
+
+    result, err := conn.Exec(ctx, `
+        UPDATE conversation_sessions
+        SET scenario_completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1::uuid AND scenario_id IS NOT NULL AND scenario_completed_at IS NULL`,
+        sessionID,
+    )
+    if err != nil {
+        return false, fmt.Errorf("database update error: %w", err)
+    }
+    return result.RowsAffected() > 0, nil
+}
+
+// EndSession closes the session and saves its history as a conversation.
+// Ending a session twice returns the conversation saved the first time.
+func EndSession(ctx context.Context, conn *pgx.Conn, sessionID string, userID int) (int, error) {
+    if !sessionIDPattern.MatchString(sessionID) {
+        return 0, pgx.ErrNoRows
+    }
+
+    tx, err := conn.Begin(ctx)
+    if err != nil {
+        return 0, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback(ctx)
+
+    var status string
+    var historyJSON []byte
+    var scenarioID *int
+    var scenarioCompleted bool
+    var assignmentID *int
+    var language string
+    err = tx.QueryRow(ctx, `
+        SELECT status, history, scenario_id, scenario_completed_at IS NOT NULL, assignment_id, language
+        FROM conversation_sessions
+        WHERE id = $1::uuid AND user_id = $2
+        FOR UPDATE`,
+        sessionID, userID,
+    ).Scan(&status, &historyJSON, &scenarioID, &scenarioCompleted, &assignmentID, &language)
+    if err != nil {
+        return 0, err
+    }
+
+    var conversationID int
+    if status == SessionEnded {
+        err = tx.QueryRow(ctx,
+            "SELECT id FROM conversations WHERE session_id = $1::uuid",
+            sessionID,
+        ).Scan(&conversationID)
+        if err != nil {
+            return 0, fmt.Errorf("error getting saved conversation: %w", err)
+        }
+        return conversationID, nil
+    }
+
+    err = tx.QueryRow(ctx,
+        `INSERT INTO conversations (user_id, session_id, scenario_id, scenario_completed, assignment_id, language, history)
+        VALUES ($1, $2::uuid, $3, $4, $5, $6, $7) RETURNING id`,
+        userID, sessionID, scenarioID, scenarioCompleted, assignmentID, language, historyJSON,
+    ).Scan(&conversationID)
+    if err != nil {
+        return 0, fmt.Errorf("database insert error: %w", err)
+    }
+
+    _, err = tx.Exec(ctx, `
+        UPDATE conversation_sessions
+        SET status = $2, ended_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1::uuid`,
+        sessionID, SessionEnded,
+    )
+    if err != nil {
+        return 0, fmt.Errorf("database update error: %w", err)
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return 0, fmt.Errorf("failed to commit transaction: %w", err)
+    }
+    return conversationID, nil
+}
+
+// GetConversation returns a saved conversation if it belongs to the user
+func GetConversation(ctx context.Context, conn *pgx.Conn, conversationID, userID int) (*Conversation, error) {
+    var conversation Conversation
+    var historyJSON []byte
+    err := conn.QueryRow(ctx, `
+        SELECT id, user_id, session_id::text, scenario_id, scenario_completed, assignment_id, language, history, created_at
+        FROM conversations
+        WHERE id = $1 AND user_id = $2`,
+        conversationID, userID,
+    ).Scan(
+        &conversation.ID, &conversation.UserID, &conversation.SessionID, &conversation.ScenarioID,
+        &conversation.ScenarioCompleted, &conversation.AssignmentID, &conversation.Language, &historyJSON,
+        &conversation.CreatedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(historyJSON, &conversation.History); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal history: %w", err)
+    }
+    return &conversation, nil
+}
+
+// ListConversations returns a page of the user's conversations, newest first, together
+// with the number of conversations matching the filter
+func ListConversations(ctx context.Context, conn *pgx.Conn, userID int, filter ConversationFilter) ([]ConversationSummary, int, error) {
+    var total int
+    err := conn.QueryRow(ctx, `
+        SELECT COUNT(*) FROM conversations
+        WHERE user_id = $1
+            AND ($2::timestamptz IS NULL OR created_at >= $2)
+            AND ($3::timestamptz IS NULL OR created_at < $3)`,
+        userID, filter.From, filter.To,
+    ).Scan(&total)
+    if err != nil {
+        return nil, 0, fmt.Errorf("database count error: %w", err)
+    }
+
+    // The preview is the first thing the student said
+    rows, err := conn.Query(ctx, `
+        SELECT c.id, c.created_at, jsonb_array_length(c.history),
+            COALESCE((
+                SELECT turn->>'content' FROM jsonb_array_elements(c.history) AS turn
+                WHERE turn->>'role' = 'user' LIMIT 1
+            ), ''),
+            COALESCE(g.level, f.level)
+        FROM conversations c
+        LEFT JOIN feedback_reports f ON f.conversation_id = c.id
+        LEFT JOIN conversation_grades g ON g.conversation_id = c.id
+        WHERE c.user_id = $1
+            AND ($2::timestamptz IS NULL OR c.created_at >= $2)
+            AND ($3::timestamptz IS NULL OR c.created_at < $3)
+        ORDER BY c.created_at DESC, c.id DESC
+        LIMIT $4 OFFSET $5`,
+        userID, filter.From, filter.To, filter.Limit, filter.Offset,
+    )
+    if err != nil {
+        return nil, 0, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    conversations := []ConversationSummary{}
+    for rows.Next() {
+        var summary ConversationSummary
+        if err := rows.Scan(&summary.ID, &summary.CreatedAt, &summary.TurnCount, &summary.Preview, &summary.Level); err != nil {
+            return nil, 0, fmt.Errorf("database scan error: %w", err)
+        }
+        conversations = append(conversations, summary)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, 0, fmt.Errorf("database rows error: %w", err)
+    }
+    return conversations, total, nil
+}
+
+// GetConversationIDBySession returns the conversation saved when the user's session ended
+func GetConversationIDBySession(ctx context.Context, conn *pgx.Conn, sessionID string, userID int) (int, error) {
+    if !sessionIDPattern.MatchString(sessionID) {
+        return 0, pgx.ErrNoRows
+    }
+
+    var conversationID int
+    err := conn.QueryRow(ctx,
+        "SELECT id FROM conversations WHERE session_id = $1::uuid AND user_id = $2",
+        sessionID, userID,
+    ).Scan(&conversationID)
+    if err != nil {
+        return 0, err
+    }
+    return conversationID, nil
+}
@@ -6,6 +6,7 @@ import (
     "log"
     "time"
 
+    pulpuwebDB "github.com/gchalakovmmi/PulpuWEB/db"
     "github.com/jackc/pgx/v5"
     "github.com/markbates/goth"
 )
@@ -188,3 +189,13 @@ func UpdateUserIfChanged(ctx context.Context, conn *pgx.Conn, userID int, authUs
     rowsAffected := result.RowsAffected()
     return rowsAffected > 0, nil
 }
+
+// Connect opens a connection of its own for work that outlives the request it started in
+func Connect(ctx context.Context, details pulpuwebDB.ConnectionDetails) (*pgx.Conn, error) {
+    url := fmt.Sprintf("postgres://%s:%s@%s:%d/%s", details.User, details.Password, details.ServerIP, details.Port, details.Schema)
+    conn, err := pgx.Connect(ctx, url)
+    if err != nil {
+        return nil, fmt.Errorf("database connection error: %w", err)
+    }
+    return conn, nil
+}
@@ -0,0 +1,150 @@
+package db
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "time"
+
+    "PulpuVOX/internal/pronunciation"
+    "github.com/jackc/pgx/v5"
+)
+
+// ExamTurn is a single turn of a speaking exam, labelled with the part it belongs to
+type ExamTurn struct {
+    Part          int                     `json:"part"`
+    Role          string                  `json:"role"`
+    Content       string                  `json:"content"`
+    Pronunciation *pronunciation.Analysis `json:"pronunciation,omitempty"`
+}
+
+// ExamSession is a speaking exam in progress or finished
+type ExamSession struct {
+    ID            string
+    UserID        int
+    Exam          string
+    CurrentPart   int
+    PartStartedAt time.Time
+    History       []ExamTurn
+    Status        string
+    Report        []byte
+    Model         *string
+    PromptVersion *string
+    CreatedAt     time.Time
+    EndedAt       *time.Time
+}
+
+// Exam session statuses
+const (
+    ExamActive   = "active"
+    ExamFinished = "finished"
+)
+
+// CreateExamSession starts an exam in its first part with the interlocutor's opening turn
+func CreateExamSession(ctx context.Context, conn *pgx.Conn, userID int, exam string, opening ExamTurn) (string, error) {
+    historyJSON, err := json.Marshal([]ExamTurn{opening})
+    if err != nil {
+        return "", fmt.Errorf("failed to marshal history: %w", err)
+    }
+
+    var sessionID string
+    err = conn.QueryRow(ctx,
+        "INSERT INTO exam_sessions (user_id, exam, current_part, history) VALUES ($1, $2, $3, $4) RETURNING id::text",
+        userID, exam, opening.Part, historyJSON,
+    ).Scan(&sessionID)
+    if err != nil {
+        return "", fmt.Errorf("database insert error: %w", err)
+    }
+    return sessionID, nil
+}
+
+// GetExamSession returns an exam session owned by the user
+func GetExamSession(ctx context.Context, conn *pgx.Conn, sessionID string, userID int) (*ExamSession, error) {
+    if !sessionIDPattern.MatchString(sessionID) {
+        return nil, pgx.ErrNoRows
+    }
+
+    var session ExamSession
+    var historyJSON []byte
+    err := conn.QueryRow(ctx, `
+        SELECT id::text, user_id, exam, current_part, part_started_at, history, status,
+            report, model, prompt_version, created_at, ended_at
+        FROM exam_sessions
+        WHERE id = $1::uuid AND user_id = $2`,
+        sessionID, userID,
+    ).Scan(
+        &session.ID, &session.UserID, &session.Exam, &session.CurrentPart, &session.PartStartedAt,
+        &historyJSON, &session.Status, &session.Report, &session.Model, &session.PromptVersion,
+        &session.CreatedAt, &session.EndedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+
+    if err := json.Unmarshal(historyJSON, &session.History); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal history: %w", err)
+    }
+    return &session, nil
+}
+
+// AppendExamTurns appends turns to the history of an active exam
+func AppendExamTurns(ctx context.Context, conn *pgx.Conn, sessionID string, turns ...ExamTurn) error {
+    turnsJSON, err := json.Marshal(turns)
+    if err != nil {
+        return fmt.Errorf("failed to marshal turns: %w", err)
+    }
+
+    result, err := conn.Exec(ctx, `
+        UPDATE exam_sessions
+        SET history = history || $2::jsonb, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1::uuid AND status = $3`,
+        sessionID, turnsJSON, ExamActive,
+    )
+    if err != nil {
+        return fmt.Errorf("database update error: %w", err)
+    }
+    if result.RowsAffected() == 0 {
+        return ErrSessionEnded
+    }
+    return nil
+}
+
+// AdvanceExamPart moves an active exam from part to the next one, restarting the part
+// timer and recording the interlocutor's opening turn. It fails with ErrSessionEnded if
+// the exam is no longer in that part, so a part cannot be skipped twice.
+func AdvanceExamPart(ctx context.Context, conn *pgx.Conn, sessionID string, part int, opening ExamTurn) error {
+    turnsJSON, err := json.Marshal([]ExamTurn{opening})
+    if err != nil {
+        return fmt.Errorf("failed to marshal turns: %w", err)
+    }
+
+    result, err := conn.Exec(ctx, `
+        UPDATE exam_sessions
+        SET current_part = $3, part_started_at = CURRENT_TIMESTAMP,
+            history = history || $4::jsonb, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1::uuid AND status = $5 AND current_part = $2`,
+        sessionID, part, opening.Part, turnsJSON, ExamActive,
+    )
+    if err != nil {
+        return fmt.Errorf("database update error: %w", err)
+    }
+    if result.RowsAffected() == 0 {
+        return ErrSessionEnded
+    }
+    return nil
+}
+
+// FinishExamSession ends an exam and stores its report. Finishing again replaces the report.
+func FinishExamSession(ctx context.Context, conn *pgx.Conn, sessionID string, report []byte, model, promptVersion string) error {
+    _, err := conn.Exec(ctx, `
+        UPDATE exam_sessions
+        SET status = $2, report = $3, model = $4, prompt_version = $5,
+            ended_at = COALESCE(ended_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1::uuid`,
+        sessionID, ExamFinished, report, model, promptVersion,
+    )
+    if err != nil {
+        return fmt.Errorf("database update error: %w", err)
+    }
+    return nil
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// FeedbackReport is a graded report stored for a conversation. The level and scores
+// are copied out of the report so teachers can sort and filter on them.
+type FeedbackReport struct {
+    ID              int
+    ConversationID  int
+    UserID          int
+    Report          json.RawMessage
+    Level           string
+    GrammarScore    int
+    VocabularyScore int
+    FluencyScore    int
+    CoherenceScore  int
+    PromptVersion   string
+    Model           string
+    CreatedAt       time.Time
+}
+
+// GetFeedbackReport returns the stored report of a conversation
+func GetFeedbackReport(ctx context.Context, conn *pgx.Conn, conversationID int) (*FeedbackReport, error) {
+    var report FeedbackReport
+    err := conn.QueryRow(ctx, `
+        SELECT id, conversation_id, user_id, report, level,
+            grammar_score, vocabulary_score, fluency_score, coherence_score,
+            prompt_version, model, created_at
+        FROM feedback_reports
+        WHERE conversation_id = $1`,
+        conversationID,
+    ).Scan(
+        &report.ID, &report.ConversationID, &report.UserID, &report.Report, &report.Level,
+        &report.GrammarScore, &report.VocabularyScore, &report.FluencyScore, &report.CoherenceScore,
+        &report.PromptVersion, &report.Model, &report.CreatedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return &report, nil
+}
+
+// SaveFeedbackReport stores the report of a conversation, replacing any earlier one
+func SaveFeedbackReport(ctx context.Context, conn *pgx.Conn, report *FeedbackReport) error {
+    err := conn.QueryRow(ctx, `
+        INSERT INTO feedback_reports (
+            conversation_id, user_id, report, level,
+            grammar_score, vocabulary_score, fluency_score, coherence_score,
+            prompt_version, model
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        ON CONFLICT (conversation_id) DO UPDATE SET
+            report = EXCLUDED.report,
+            level = EXCLUDED.level,
+            grammar_score = EXCLUDED.grammar_score,
+            vocabulary_score = EXCLUDED.vocabulary_score,
+            fluency_score = EXCLUDED.fluency_score,
+            coherence_score = EXCLUDED.coherence_score,
+            prompt_version = EXCLUDED.prompt_version,
+            model = EXCLUDED.model,
+            created_at = CURRENT_TIMESTAMP
+        RETURNING id, created_at`,
+        report.ConversationID, report.UserID, report.Report, report.Level,
+        report.GrammarScore, report.VocabularyScore, report.FluencyScore, report.CoherenceScore,
+        report.PromptVersion, report.Model,
+    ).Scan(&report.ID, &report.CreatedAt)
+    if err != nil {
+        return fmt.Errorf("database upsert error: %w", err)
+    }
+    return nil
+}
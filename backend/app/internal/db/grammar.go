@@ -0,0 +1,149 @@
+package db
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// GrammarErrorCount is how often one type of grammar error was corrected. Sentences counts
+// the student turns that had the error and Errors every correction of that type.
+type GrammarErrorCount struct {
+    ErrorType string
+    Sentences int
+    Errors    int
+}
+
+// GrammarCount is the grammar errors corrected in one conversation, out of the number of
+// sentences (student turns) it had
+type GrammarCount struct {
+    ConversationID int
+    UserID         int
+    Sentences      int
+    Errors         []GrammarErrorCount
+    ConversationAt time.Time
+}
+
+// SaveGrammarCount stores the grammar errors of a conversation, replacing earlier ones
+func SaveGrammarCount(ctx context.Context, conn *pgx.Conn, count *GrammarCount) error {
+    tx, err := conn.Begin(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback(ctx)
+
+    _, err = tx.Exec(ctx, `
+        INSERT INTO conversation_grammar (conversation_id, user_id, sentence_count, conversation_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (conversation_id) DO UPDATE SET
+            sentence_count = EXCLUDED.sentence_count,
+            computed_at = CURRENT_TIMESTAMP`,
+        count.ConversationID, count.UserID, count.Sentences, count.ConversationAt,
+    )
+    if err != nil {
+        return fmt.Errorf("database upsert error: %w", err)
+    }
+
+    if _, err := tx.Exec(ctx, "DELETE FROM grammar_errors WHERE conversation_id = $1", count.ConversationID); err != nil {
+        return fmt.Errorf("database delete error: %w", err)
+    }
+
+    types := make([]string, len(count.Errors))
+    sentences := make([]int, len(count.Errors))
+    errors := make([]int, len(count.Errors))
+    for i, e := range count.Errors {
+        types[i] = e.ErrorType
+        sentences[i] = e.Sentences
+        errors[i] = e.Errors
+    }
+    _, err = tx.Exec(ctx, `
+        INSERT INTO grammar_errors (conversation_id, error_type, sentence_count, error_count)
+        SELECT $1, e.error_type, e.sentence_count, e.error_count
+        FROM unnest($2::text[], $3::int[], $4::int[]) AS e(error_type, sentence_count, error_count)`,
+        count.ConversationID, types, sentences, errors,
+    )
+    if err != nil {
+        return fmt.Errorf("database insert error: %w", err)
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return fmt.Errorf("failed to commit transaction: %w", err)
+    }
+    return nil
+}
+
+// ListConversationsWithoutGrammar returns the user's conversations whose grammar errors
+// have not been counted yet, oldest first
+func ListConversationsWithoutGrammar(ctx context.Context, conn *pgx.Conn, userID int) ([]Conversation, error) {
+    rows, err := conn.Query(ctx, `
+        SELECT c.id, c.user_id, c.language, c.history, c.created_at
+        FROM conversations c
+        LEFT JOIN conversation_grammar g ON g.conversation_id = c.id
+        WHERE c.user_id = $1 AND g.conversation_id IS NULL
+        ORDER BY c.created_at`,
+        userID,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    var conversations []Conversation
+    for rows.Next() {
+        var conversation Conversation
+        if err := rows.Scan(&conversation.ID, &conversation.UserID, &conversation.Language, &conversation.History, &conversation.CreatedAt); err != nil {
+            return nil, fmt.Errorf("database scan error: %w", err)
+        }
+        conversations = append(conversations, conversation)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return conversations, nil
+}
+
+// SumGrammarErrors adds up the grammar errors of the user's conversations in a date range,
+// either end of which may be nil. It returns the number of sentences and the errors by type.
+func SumGrammarErrors(ctx context.Context, conn *pgx.Conn, userID int, from, to *time.Time) (int, []GrammarErrorCount, error) {
+    var sentences int
+    err := conn.QueryRow(ctx, `
+        SELECT COALESCE(SUM(sentence_count), 0) FROM conversation_grammar
+        WHERE user_id = $1
+            AND ($2::timestamptz IS NULL OR conversation_at >= $2)
+            AND ($3::timestamptz IS NULL OR conversation_at < $3)`,
+        userID, from, to,
+    ).Scan(&sentences)
+    if err != nil {
+        return 0, nil, fmt.Errorf("database count error: %w", err)
+    }
+
+    rows, err := conn.Query(ctx, `
+        SELECT e.error_type, SUM(e.sentence_count), SUM(e.error_count)
+        FROM grammar_errors e
+        JOIN conversation_grammar g ON g.conversation_id = e.conversation_id
+        WHERE g.user_id = $1
+            AND ($2::timestamptz IS NULL OR g.conversation_at >= $2)
+            AND ($3::timestamptz IS NULL OR g.conversation_at < $3)
+        GROUP BY e.error_type`,
+        userID, from, to,
+    )
+    if err != nil {
+        return 0, nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    var counts []GrammarErrorCount
+    for rows.Next() {
+        var count GrammarErrorCount
+        if err := rows.Scan(&count.ErrorType, &count.Sentences, &count.Errors); err != nil {
+            return 0, nil, fmt.Errorf("database scan error: %w", err)
+        }
+        counts = append(counts, count)
+    }
+    if err := rows.Err(); err != nil {
+        return 0, nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return sentences, counts, nil
+}
@@ -0,0 +1,189 @@
+package db
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// Categories of the facts remembered about a learner
+const (
+    MemoryPersonal = "personal"
+    MemoryWork     = "work"
+    MemoryHobby    = "hobby"
+    MemoryTopic    = "topic"
+)
+
+// LearnerMemory is a durable fact about a user remembered from one of their conversations
+type LearnerMemory struct {
+    ID             int       `json:"id"`
+    Category       string    `json:"category"`
+    Fact           string    `json:"fact"`
+    ConversationID *int      `json:"conversation_id"`
+    CreatedAt      time.Time `json:"created_at"`
+    UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ListLearnerMemories returns everything remembered about the user, most recently
+// confirmed first
+func ListLearnerMemories(ctx context.Context, conn *pgx.Conn, userID int) ([]LearnerMemory, error) {
+    rows, err := conn.Query(ctx, `
+        SELECT id, category, fact, conversation_id, created_at, updated_at
+        FROM learner_memories
+        WHERE user_id = $1
+        ORDER BY updated_at DESC, id DESC`,
+        userID,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    memories := []LearnerMemory{}
+    for rows.Next() {
+        var memory LearnerMemory
+        if err := rows.Scan(&memory.ID, &memory.Category, &memory.Fact, &memory.ConversationID, &memory.CreatedAt, &memory.UpdatedAt); err != nil {
+            return nil, fmt.Errorf("database scan error: %w", err)
+        }
+        memories = append(memories, memory)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return memories, nil
+}
+
+// ListConversationsWithoutMemory returns the latest of the user's free conversations whose
+// facts have not been remembered yet, at most limit of them, oldest first. Scenario
+// conversations are left out: see MarkScenarioConversationsRemembered.
+func ListConversationsWithoutMemory(ctx context.Context, conn *pgx.Conn, userID, limit int) ([]Conversation, error) {
+    rows, err := conn.Query(ctx, `
+        SELECT id, user_id, language, history, created_at
+        FROM (
+            SELECT id, user_id, language, history, created_at
+            FROM conversations
+            WHERE user_id = $1 AND scenario_id IS NULL AND NOT memory_recorded
+            ORDER BY created_at DESC
+            LIMIT $2
+        ) latest
+        ORDER BY created_at`,
+        userID, limit,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    var conversations []Conversation
+    for rows.Next() {
+        var conversation Conversation
+        if err := rows.Scan(&conversation.ID, &conversation.UserID, &conversation.Language, &conversation.History, &conversation.CreatedAt); err != nil {
+            return nil, fmt.Errorf("database scan error: %w", err)
+        }
+        conversations = append(conversations, conversation)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return conversations, nil
+}
+
+// MarkScenarioConversationsRemembered marks the user's scenario conversations as
+// remembered without reading them, since what the learner says in a role-play, such as
+// the job history of an interview, is not about themselves
+func MarkScenarioConversationsRemembered(ctx context.Context, conn *pgx.Conn, userID int) error {
+    _, err := conn.Exec(ctx, `
+        UPDATE conversations SET memory_recorded = TRUE
+        WHERE user_id = $1 AND scenario_id IS NOT NULL AND NOT memory_recorded`,
+        userID,
+    )
+    if err != nil {
+        return fmt.Errorf("database update error: %w", err)
+    }
+    return nil
+}
+
+// SaveLearnerMemories stores the facts learned from a conversation, forgets the ones they
+// replace and marks the conversation as remembered. A fact that is already remembered is
+// confirmed instead of added twice, and only the keep most recently confirmed facts of the
+// user are kept.
+func SaveLearnerMemories(ctx context.Context, conn *pgx.Conn, userID, conversationID int, memories []LearnerMemory, forget []int, keep int) error {
+    tx, err := conn.Begin(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback(ctx)
+
+    if _, err := tx.Exec(ctx, "UPDATE conversations SET memory_recorded = TRUE WHERE id = $1", conversationID); err != nil {
+        return fmt.Errorf("database update error: %w", err)
+    }
+
+    if len(forget) > 0 {
+        if _, err := tx.Exec(ctx, "DELETE FROM learner_memories WHERE user_id = $1 AND id = ANY($2)", userID, forget); err != nil {
+            return fmt.Errorf("database delete error: %w", err)
+        }
+    }
+
+    categories := make([]string, len(memories))
+    facts := make([]string, len(memories))
+    for i, memory := range memories {
+        categories[i] = memory.Category
+        facts[i] = memory.Fact
+    }
+    _, err = tx.Exec(ctx, `
+        INSERT INTO learner_memories (user_id, category, fact, conversation_id)
+        SELECT $1, m.category, m.fact, $2
+        FROM unnest($3::text[], $4::text[]) AS m(category, fact)
+        ON CONFLICT (user_id, LOWER(fact)) DO UPDATE SET
+            category = EXCLUDED.category,
+            conversation_id = EXCLUDED.conversation_id,
+            updated_at = CURRENT_TIMESTAMP`,
+        userID, conversationID, categories, facts,
+    )
+    if err != nil {
+        return fmt.Errorf("database upsert error: %w", err)
+    }
+
+    _, err = tx.Exec(ctx, `
+        DELETE FROM learner_memories
+        WHERE user_id = $1 AND id NOT IN (
+            SELECT id FROM learner_memories
+            WHERE user_id = $1
+            ORDER BY updated_at DESC, id DESC
+            LIMIT $2
+        )`,
+        userID, keep,
+    )
+    if err != nil {
+        return fmt.Errorf("database delete error: %w", err)
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return fmt.Errorf("failed to commit transaction: %w", err)
+    }
+    return nil
+}
+
+// DeleteLearnerMemory forgets one fact remembered about the user
+func DeleteLearnerMemory(ctx context.Context, conn *pgx.Conn, userID, memoryID int) error {
+    result, err := conn.Exec(ctx, "DELETE FROM learner_memories WHERE id = $1 AND user_id = $2", memoryID, userID)
+    if err != nil {
+        return fmt.Errorf("database delete error: %w", err)
+    }
+    if result.RowsAffected() == 0 {
+        return pgx.ErrNoRows
+    }
+    return nil
+}
+
+// DeleteLearnerMemories forgets everything remembered about the user and returns how many
+// facts were forgotten
+func DeleteLearnerMemories(ctx context.Context, conn *pgx.Conn, userID int) (int, error) {
+    result, err := conn.Exec(ctx, "DELETE FROM learner_memories WHERE user_id = $1", userID)
+    if err != nil {
+        return 0, fmt.Errorf("database delete error: %w", err)
+    }
+    return int(result.RowsAffected()), nil
+}
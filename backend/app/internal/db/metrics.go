@@ -0,0 +1,136 @@
+package db
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// ConversationMetrics are the progress measures derived from one conversation
+type ConversationMetrics struct {
+    ConversationID      int       `json:"conversation_id"`
+    UserID              int       `json:"-"`
+    TurnCount           int       `json:"turn_count"`
+    WordCount           int       `json:"word_count"`
+    WordsPerTurn        float64   `json:"words_per_turn"`
+    CorrectionRate      float64   `json:"correction_rate"`
+    VocabularyDiversity float64   `json:"vocabulary_diversity"`
+    CEFRLevel           *string   `json:"cefr_level"`
+    ConversationAt      time.Time `json:"conversation_at"`
+}
+
+// SaveConversationMetrics stores the metrics of a conversation, replacing earlier ones.
+// A CEFR level already recorded is kept when the new metrics have none.
+func SaveConversationMetrics(ctx context.Context, conn *pgx.Conn, metrics *ConversationMetrics) error {
+    _, err := conn.Exec(ctx, `
+        INSERT INTO conversation_metrics (
+            conversation_id, user_id, turn_count, word_count, words_per_turn,
+            correction_rate, vocabulary_diversity, cefr_level, conversation_at
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        ON CONFLICT (conversation_id) DO UPDATE SET
+            turn_count = EXCLUDED.turn_count,
+            word_count = EXCLUDED.word_count,
+            words_per_turn = EXCLUDED.words_per_turn,
+            correction_rate = EXCLUDED.correction_rate,
+            vocabulary_diversity = EXCLUDED.vocabulary_diversity,
+            cefr_level = COALESCE(EXCLUDED.cefr_level, conversation_metrics.cefr_level),
+            computed_at = CURRENT_TIMESTAMP`,
+        metrics.ConversationID, metrics.UserID, metrics.TurnCount, metrics.WordCount, metrics.WordsPerTurn,
+        metrics.CorrectionRate, metrics.VocabularyDiversity, metrics.CEFRLevel, metrics.ConversationAt,
+    )
+    if err != nil {
+        return fmt.Errorf("database upsert error: %w", err)
+    }
+    return nil
+}
+
+// RefreshMetricsLevel records the CEFR level of a conversation in its metrics: the teacher's
+// grade if there is one, otherwise the level of its feedback report
+func RefreshMetricsLevel(ctx context.Context, conn *pgx.Conn, conversationID int) error {
+    _, err := conn.Exec(ctx, `
+        UPDATE conversation_metrics SET
+            cefr_level = COALESCE(
+                (SELECT level FROM conversation_grades WHERE conversation_id = $1),
+                (SELECT level FROM feedback_reports WHERE conversation_id = $1)
+            ),
+            computed_at = CURRENT_TIMESTAMP
+        WHERE conversation_id = $1`,
+        conversationID,
+    )
+    if err != nil {
+        return fmt.Errorf("database update error: %w", err)
+    }
+    return nil
+}
+
+// ListConversationsWithoutMetrics returns the user's conversations that have no metrics yet,
+// together with their teacher's grade or the level of their feedback report if one was graded
+func ListConversationsWithoutMetrics(ctx context.Context, conn *pgx.Conn, userID int) ([]Conversation, []*string, error) {
+    rows, err := conn.Query(ctx, `
+        SELECT c.id, c.user_id, c.history, c.created_at, COALESCE(g.level, f.level)
+        FROM conversations c
+        LEFT JOIN conversation_metrics m ON m.conversation_id = c.id
+        LEFT JOIN feedback_reports f ON f.conversation_id = c.id
+        LEFT JOIN conversation_grades g ON g.conversation_id = c.id
+        WHERE c.user_id = $1 AND m.conversation_id IS NULL
+        ORDER BY c.created_at`,
+        userID,
+    )
+    if err != nil {
+        return nil, nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    var conversations []Conversation
+    var levels []*string
+    for rows.Next() {
+        var conversation Conversation
+        var level *string
+        if err := rows.Scan(&conversation.ID, &conversation.UserID, &conversation.History, &conversation.CreatedAt, &level); err != nil {
+            return nil, nil, fmt.Errorf("database scan error: %w", err)
+        }
+        conversations = append(conversations, conversation)
+        levels = append(levels, level)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return conversations, levels, nil
+}
+
+// ListConversationMetrics returns the metrics of the user's conversations in time order
+func ListConversationMetrics(ctx context.Context, conn *pgx.Conn, userID int, from, to *time.Time) ([]ConversationMetrics, error) {
+    rows, err := conn.Query(ctx, `
+        SELECT conversation_id, user_id, turn_count, word_count, words_per_turn,
+            correction_rate, vocabulary_diversity, cefr_level, conversation_at
+        FROM conversation_metrics
+        WHERE user_id = $1
+            AND ($2::timestamptz IS NULL OR conversation_at >= $2)
+            AND ($3::timestamptz IS NULL OR conversation_at < $3)
+        ORDER BY conversation_at, conversation_id`,
+        userID, from, to,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    metrics := []ConversationMetrics{}
+    for rows.Next() {
+        var m ConversationMetrics
+        err := rows.Scan(
+            &m.ConversationID, &m.UserID, &m.TurnCount, &m.WordCount, &m.WordsPerTurn,
+            &m.CorrectionRate, &m.VocabularyDiversity, &m.CEFRLevel, &m.ConversationAt,
+        )
+        if err != nil {
+            return nil, fmt.Errorf("database scan error: %w", err)
+        }
+        metrics = append(metrics, m)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return metrics, nil
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// Notification is news for a user about one of their conversations
+type Notification struct {
+    ID             int        `json:"id"`
+    ConversationID *int       `json:"conversation_id"`
+    Message        string     `json:"message"`
+    CreatedAt      time.Time  `json:"created_at"`
+    ReadAt         *time.Time `json:"read_at"`
+}
+
+// NotifyConversation tells a user about a change to one of their conversations. A user has
+// at most one unread notification per conversation, which is replaced by the latest news.
+func NotifyConversation(ctx context.Context, conn *pgx.Conn, userID, conversationID int, message string) error {
+    _, err := conn.Exec(ctx, `
+        INSERT INTO notifications (user_id, conversation_id, message)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (user_id, conversation_id) WHERE read_at IS NULL DO UPDATE SET
+            message = EXCLUDED.message,
+            created_at = CURRENT_TIMESTAMP`,
+        userID, conversationID, message,
+    )
+    if err != nil {
+        return fmt.Errorf("database upsert error: %w", err)
+    }
+    return nil
+}
+
+// ListUnreadNotifications returns the user's unread notifications, newest first
+func ListUnreadNotifications(ctx context.Context, conn *pgx.Conn, userID int) ([]Notification, error) {
+    rows, err := conn.Query(ctx, `
+        SELECT id, conversation_id, message, created_at, read_at
+        FROM notifications
+        WHERE user_id = $1 AND read_at IS NULL
+        ORDER BY created_at DESC, id DESC`,
+        userID,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    notifications := []Notification{}
+    for rows.Next() {
+        var notification Notification
+        err := rows.Scan(&notification.ID, &notification.ConversationID, &notification.Message,
+            &notification.CreatedAt, &notification.ReadAt)
+        if err != nil {
+            return nil, fmt.Errorf("database scan error: %w", err)
+        }
+        notifications = append(notifications, notification)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return notifications, nil
+}
+
+// MarkNotificationsRead marks the user's notifications with the given IDs as read, or all of
+// them when no IDs are given
+func MarkNotificationsRead(ctx context.Context, conn *pgx.Conn, userID int, ids []int) error {
+    _, err := conn.Exec(ctx, `
+        UPDATE notifications SET read_at = CURRENT_TIMESTAMP
+        WHERE user_id = $1 AND read_at IS NULL
+            AND (COALESCE(cardinality($2::int[]), 0) = 0 OR id = ANY($2))`,
+        userID, ids,
+    )
+    if err != nil {
+        return fmt.Errorf("database update error: %w", err)
+    }
+    return nil
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// LearnerProfile is what the conversation adapts to: the user's current CEFR level in a
+// language and what they like to talk about. The level is nil until it is set or graded.
+type LearnerProfile struct {
+    UserID          int        `json:"-"`
+    Language        string     `json:"language"`
+    CEFRLevel       *string    `json:"cefr_level"`
+    Interests       []string   `json:"interests"`
+    Goals           []string   `json:"goals"`
+    PreferredTopics []string   `json:"preferred_topics"`
+    LevelUpdatedAt  *time.Time `json:"level_updated_at"`
+}
+
+// GetLearnerProfile returns the user's profile with their level in a language. Parts the
+// user has not filled in yet are empty.
+func GetLearnerProfile(ctx context.Context, conn *pgx.Conn, userID int, language string) (*LearnerProfile, error) {
+    profile := LearnerProfile{UserID: userID, Language: language}
+    err := conn.QueryRow(ctx, `
+        SELECT COALESCE(p.interests, '{}'), COALESCE(p.goals, '{}'), COALESCE(p.preferred_topics, '{}'),
+            l.cefr_level, l.updated_at
+        FROM (SELECT $1::int AS user_id) u
+        LEFT JOIN learner_profiles p ON p.user_id = u.user_id
+        LEFT JOIN learner_levels l ON l.user_id = u.user_id AND l.language = $2`,
+        userID, language,
+    ).Scan(&profile.Interests, &profile.Goals, &profile.PreferredTopics, &profile.CEFRLevel, &profile.LevelUpdatedAt)
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    return &profile, nil
+}
+
+// SaveLearnerProfile stores the interests, goals and preferred topics of a profile and its
+// level in the profile's language, clearing the level when it is nil
+func SaveLearnerProfile(ctx context.Context, conn *pgx.Conn, profile *LearnerProfile) error {
+    tx, err := conn.Begin(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback(ctx)
+
+    _, err = tx.Exec(ctx, `
+        INSERT INTO learner_profiles (user_id, interests, goals, preferred_topics)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (user_id) DO UPDATE SET
+            interests = EXCLUDED.interests,
+            goals = EXCLUDED.goals,
+            preferred_topics = EXCLUDED.preferred_topics,
+            updated_at = CURRENT_TIMESTAMP`,
+        profile.UserID, profile.Interests, profile.Goals, profile.PreferredTopics,
+    )
+    if err != nil {
+        return fmt.Errorf("database upsert error: %w", err)
+    }
+
+    if profile.CEFRLevel == nil {
+        _, err = tx.Exec(ctx, "DELETE FROM learner_levels WHERE user_id = $1 AND language = $2", profile.UserID, profile.Language)
+        if err != nil {
+            return fmt.Errorf("database delete error: %w", err)
+        }
+        profile.LevelUpdatedAt = nil
+    } else {
+        err = tx.QueryRow(ctx, `
+            INSERT INTO learner_levels (user_id, language, cefr_level)
+            VALUES ($1, $2, $3)
+            ON CONFLICT (user_id, language) DO UPDATE SET
+                cefr_level = EXCLUDED.cefr_level,
+                updated_at = CASE
+                    WHEN learner_levels.cefr_level = EXCLUDED.cefr_level THEN learner_levels.updated_at
+                    ELSE CURRENT_TIMESTAMP
+                END
+            RETURNING updated_at`,
+            profile.UserID, profile.Language, *profile.CEFRLevel,
+        ).Scan(&profile.LevelUpdatedAt)
+        if err != nil {
+            return fmt.Errorf("database upsert error: %w", err)
+        }
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return fmt.Errorf("failed to commit transaction: %w", err)
+    }
+    return nil
+}
+
+// SetLearnerLevel records the user's current CEFR level in a language
+func SetLearnerLevel(ctx context.Context, conn *pgx.Conn, userID int, language, level string) error {
+    _, err := conn.Exec(ctx, `
+        INSERT INTO learner_levels (user_id, language, cefr_level)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (user_id, language) DO UPDATE SET
+            cefr_level = EXCLUDED.cefr_level,
+            updated_at = CURRENT_TIMESTAMP`,
+        userID, language, level,
+    )
+    if err != nil {
+        return fmt.Errorf("database upsert error: %w", err)
+    }
+    return nil
+}
+
+// ListRecentLevels returns the levels of the user's latest graded conversations in a
+// language, newest first. A teacher's grade takes precedence over the level of the
+// feedback report.
+func ListRecentLevels(ctx context.Context, conn *pgx.Conn, userID int, language string, limit int) ([]string, error) {
+    rows, err := conn.Query(ctx, `
+        SELECT COALESCE(g.level, f.level)
+        FROM conversations c
+        LEFT JOIN feedback_reports f ON f.conversation_id = c.id
+        LEFT JOIN conversation_grades g ON g.conversation_id = c.id
+        WHERE c.user_id = $1 AND c.language = $2 AND COALESCE(g.level, f.level) IS NOT NULL
+        ORDER BY c.created_at DESC, c.id DESC
+        LIMIT $3`,
+        userID, language, limit,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    var levels []string
+    for rows.Next() {
+        var level string
+        if err := rows.Scan(&level); err != nil {
+            return nil, fmt.Errorf("database scan error: %w", err)
+        }
+        levels = append(levels, level)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return levels, nil
+}
@@ -0,0 +1,262 @@
+package db
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// ReviewCard is a past correction to practise: the student hears the original sentence
+// and has to say the corrected one. The schedule fields follow the SM-2 algorithm.
+type ReviewCard struct {
+    ID             int        `json:"id"`
+    UserID         int        `json:"-"`
+    ConversationID *int       `json:"conversation_id"`
+    Original       string     `json:"original"`
+    Corrected      string     `json:"corrected"`
+    Language       string     `json:"language"`
+    EaseFactor     float64    `json:"ease_factor"`
+    IntervalDays   int        `json:"interval_days"`
+    Repetitions    int        `json:"repetitions"`
+    DueAt          time.Time  `json:"due_at"`
+    ReviewCount    int        `json:"review_count"`
+    CorrectCount   int        `json:"correct_count"`
+    LastReviewedAt *time.Time `json:"last_reviewed_at"`
+    CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ReviewStats counts the user's review cards
+type ReviewStats struct {
+    Due       int        `json:"due"`
+    Total     int        `json:"total"`
+    NextDueAt *time.Time `json:"next_due_at"`
+}
+
+// reviewCardColumns are the columns scanned by scanReviewCard
+const reviewCardColumns = `id, user_id, conversation_id, original, corrected, language, ease_factor,
+    interval_days, repetitions, due_at, review_count, correct_count, last_reviewed_at, created_at`
+
+// scanReviewCard reads a row selected with reviewCardColumns
+func scanReviewCard(row pgx.Row) (*ReviewCard, error) {
+    var card ReviewCard
+    err := row.Scan(
+        &card.ID, &card.UserID, &card.ConversationID, &card.Original, &card.Corrected, &card.Language,
+        &card.EaseFactor, &card.IntervalDays, &card.Repetitions, &card.DueAt,
+        &card.ReviewCount, &card.CorrectCount, &card.LastReviewedAt, &card.CreatedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return &card, nil
+}
+
+// CreateReviewCards stores the corrections of a saved conversation as review cards due
+// immediately; only the sentences, language and ease factor of the cards are used. Each conversation
+// is processed once, and a correction the user already has a card for is not added again.
+func CreateReviewCards(ctx context.Context, conn *pgx.Conn, conversationID, userID int, cards []ReviewCard) error {
+    tx, err := conn.Begin(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback(ctx)
+
+    result, err := tx.Exec(ctx,
+        "UPDATE conversations SET review_cards_created = TRUE WHERE id = $1 AND NOT review_cards_created",
+        conversationID,
+    )
+    if err != nil {
+        return fmt.Errorf("database update error: %w", err)
+    }
+    if result.RowsAffected() == 0 {
+        return nil
+    }
+
+    for _, card := range cards {
+        _, err := tx.Exec(ctx, `
+            INSERT INTO review_cards (user_id, conversation_id, original, corrected, language, ease_factor)
+            VALUES ($1, $2, $3, $4, $5, $6)
+            ON CONFLICT (user_id, original, corrected) DO NOTHING`,
+            userID, conversationID, card.Original, card.Corrected, card.Language, card.EaseFactor,
+        )
+        if err != nil {
+            return fmt.Errorf("database insert error: %w", err)
+        }
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return fmt.Errorf("failed to commit transaction: %w", err)
+    }
+    return nil
+}
+
+// ReplaceReviewCards makes the review cards of a saved conversation match its corrections
+// after a teacher changed them. Cards whose correction is unchanged keep their schedule,
+// cards of corrections that no longer exist are deleted and new corrections are added.
+func ReplaceReviewCards(ctx context.Context, conn *pgx.Conn, conversationID, userID int, cards []ReviewCard) error {
+    tx, err := conn.Begin(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback(ctx)
+
+    _, err = tx.Exec(ctx, "UPDATE conversations SET review_cards_created = TRUE WHERE id = $1", conversationID)
+    if err != nil {
+        return fmt.Errorf("database update error: %w", err)
+    }
+
+    originals := make([]string, len(cards))
+    corrections := make([]string, len(cards))
+    for i, card := range cards {
+        originals[i] = card.Original
+        corrections[i] = card.Corrected
+    }
+    _, err = tx.Exec(ctx, `
+        DELETE FROM review_cards r
+        WHERE r.conversation_id = $1 AND r.user_id = $2
+            AND NOT EXISTS (
+                SELECT 1 FROM unnest($3::text[], $4::text[]) AS c(original, corrected)
+                WHERE c.original = r.original AND c.corrected = r.corrected
+            )`,
+        conversationID, userID, originals, corrections,
+    )
+    if err != nil {
+        return fmt.Errorf("database delete error: %w", err)
+    }
+
+    for _, card := range cards {
+        _, err := tx.Exec(ctx, `
+            INSERT INTO review_cards (user_id, conversation_id, original, corrected, language, ease_factor)
+            VALUES ($1, $2, $3, $4, $5, $6)
+            ON CONFLICT (user_id, original, corrected) DO NOTHING`,
+            userID, conversationID, card.Original, card.Corrected, card.Language, card.EaseFactor,
+        )
+        if err != nil {
+            return fmt.Errorf("database insert error: %w", err)
+        }
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return fmt.Errorf("failed to commit transaction: %w", err)
+    }
+    return nil
+}
+
+// ListConversationsWithoutReviewCards returns the user's conversations whose corrections
+// have not been turned into review cards yet, oldest first
+func ListConversationsWithoutReviewCards(ctx context.Context, conn *pgx.Conn, userID int) ([]Conversation, error) {
+    rows, err := conn.Query(ctx, `
+        SELECT id, user_id, language, history, created_at
+        FROM conversations
+        WHERE user_id = $1 AND NOT review_cards_created
+        ORDER BY created_at`,
+        userID,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    var conversations []Conversation
+    for rows.Next() {
+        var conversation Conversation
+        if err := rows.Scan(&conversation.ID, &conversation.UserID, &conversation.Language, &conversation.History, &conversation.CreatedAt); err != nil {
+            return nil, fmt.Errorf("database scan error: %w", err)
+        }
+        conversations = append(conversations, conversation)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return conversations, nil
+}
+
+// NextDueReviewCard returns the user's most overdue card. It returns pgx.ErrNoRows when
+// no card is due.
+func NextDueReviewCard(ctx context.Context, conn *pgx.Conn, userID int) (*ReviewCard, error) {
+    return scanReviewCard(conn.QueryRow(ctx, `
+        SELECT `+reviewCardColumns+`
+        FROM review_cards
+        WHERE user_id = $1 AND due_at <= CURRENT_TIMESTAMP
+        ORDER BY due_at, id
+        LIMIT 1`,
+        userID,
+    ))
+}
+
+// GetReviewCard returns a review card owned by the user
+func GetReviewCard(ctx context.Context, conn *pgx.Conn, cardID, userID int) (*ReviewCard, error) {
+    return scanReviewCard(conn.QueryRow(ctx, `
+        SELECT `+reviewCardColumns+`
+        FROM review_cards
+        WHERE id = $1 AND user_id = $2`,
+        cardID, userID,
+    ))
+}
+
+// GetReviewStats counts the user's cards that are due and in total
+func GetReviewStats(ctx context.Context, conn *pgx.Conn, userID int) (*ReviewStats, error) {
+    var stats ReviewStats
+    err := conn.QueryRow(ctx, `
+        SELECT COUNT(*) FILTER (WHERE due_at <= CURRENT_TIMESTAMP), COUNT(*),
+            MIN(due_at) FILTER (WHERE due_at > CURRENT_TIMESTAMP)
+        FROM review_cards
+        WHERE user_id = $1`,
+        userID,
+    ).Scan(&stats.Due, &stats.Total, &stats.NextDueAt)
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    return &stats, nil
+}
+
+// SaveReviewAnswer stores the new schedule of a card after an answer
+func SaveReviewAnswer(ctx context.Context, conn *pgx.Conn, card *ReviewCard, correct bool) error {
+    correctCount := 0
+    if correct {
+        correctCount = 1
+    }
+
+    err := conn.QueryRow(ctx, `
+        UPDATE review_cards
+        SET ease_factor = $2, interval_days = $3, repetitions = $4, due_at = $5,
+            review_count = review_count + 1, correct_count = correct_count + $6,
+            last_reviewed_at = CURRENT_TIMESTAMP
+        WHERE id = $1
+        RETURNING review_count, correct_count, last_reviewed_at`,
+        card.ID, card.EaseFactor, card.IntervalDays, card.Repetitions, card.DueAt, correctCount,
+    ).Scan(&card.ReviewCount, &card.CorrectCount, &card.LastReviewedAt)
+    if err != nil {
+        return fmt.Errorf("database update error: %w", err)
+    }
+    return nil
+}
+
+// ListReviewCards returns all of the user's review cards, oldest first
+func ListReviewCards(ctx context.Context, conn *pgx.Conn, userID int) ([]ReviewCard, error) {
+    rows, err := conn.Query(ctx, `
+        SELECT `+reviewCardColumns+`
+        FROM review_cards
+        WHERE user_id = $1
+        ORDER BY created_at, id`,
+        userID,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    cards := []ReviewCard{}
+    for rows.Next() {
+        card, err := scanReviewCard(rows)
+        if err != nil {
+            return nil, fmt.Errorf("database scan error: %w", err)
+        }
+        cards = append(cards, *card)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return cards, nil
+}
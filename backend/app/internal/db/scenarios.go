@@ -0,0 +1,125 @@
+package db
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// Scenario is a role-play situation a conversation can be set in
+type Scenario struct {
+    ID                  int       `json:"id"`
+    Slug                string    `json:"slug"`
+    Title               string    `json:"title"`
+    Description         string    `json:"description"`
+    Setting             string    `json:"setting"`
+    AIRole              string    `json:"ai_role"`
+    LearnerGoal         string    `json:"learner_goal"`
+    TargetVocabulary    []string  `json:"target_vocabulary"`
+    CompletionCondition string    `json:"completion_condition"`
+    OpeningLine         string    `json:"opening_line"`
+    CreatedBy           *int      `json:"created_by"`
+    CreatedAt           time.Time `json:"created_at"`
+}
+
+// ErrScenarioSlugTaken is returned when a new scenario's slug is already used by another one
+var ErrScenarioSlugTaken = errors.New("scenario slug is already in use")
+
+const scenarioColumns = `id, slug, title, description, setting, ai_role, learner_goal,
+    target_vocabulary, completion_condition, opening_line, created_by, created_at`
+
+func scanScenario(row pgx.Row) (*Scenario, error) {
+    var s Scenario
+    err := row.Scan(
+        &s.ID, &s.Slug, &s.Title, &s.Description, &s.Setting, &s.AIRole, &s.LearnerGoal,
+        &s.TargetVocabulary, &s.CompletionCondition, &s.OpeningLine, &s.CreatedBy, &s.CreatedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return &s, nil
+}
+
+// ListScenarios returns every scenario ordered by title
+func ListScenarios(ctx context.Context, conn *pgx.Conn) ([]Scenario, error) {
+    rows, err := conn.Query(ctx, "SELECT "+scenarioColumns+" FROM scenarios ORDER BY title")
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    scenarios := []Scenario{}
+    for rows.Next() {
+        scenario, err := scanScenario(rows)
+        if err != nil {
+            return nil, fmt.Errorf("database scan error: %w", err)
+        }
+        scenarios = append(scenarios, *scenario)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return scenarios, nil
+}
+
+// GetScenario returns the scenario with the given ID
+func GetScenario(ctx context.Context, conn *pgx.Conn, scenarioID int) (*Scenario, error) {
+    return scanScenario(conn.QueryRow(ctx, "SELECT "+scenarioColumns+" FROM scenarios WHERE id = $1", scenarioID))
+}
+
+// CreateScenario stores a new scenario and fills in its ID and creation time, returning
+// ErrScenarioSlugTaken if another scenario has the same slug
+func CreateScenario(ctx context.Context, conn *pgx.Conn, scenario *Scenario) error {
+    err := conn.QueryRow(ctx, `
+        INSERT INTO scenarios (slug, title, description, setting, ai_role, learner_goal,
+            target_vocabulary, completion_condition, opening_line, created_by)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        ON CONFLICT (slug) DO NOTHING
+        RETURNING id, created_at`,
+        scenario.Slug, scenario.Title, scenario.Description, scenario.Setting, scenario.AIRole, scenario.LearnerGoal,
+        scenario.TargetVocabulary, scenario.CompletionCondition, scenario.OpeningLine, scenario.CreatedBy,
+    ).Scan(&scenario.ID, &scenario.CreatedAt)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            return ErrScenarioSlugTaken
+        }
+        return fmt.Errorf("database insert error: %w", err)
+    }
+    return nil
+}
+
+// UpdateScenario replaces what a scenario asks of the learner and the assistant. Its
+// slug and creator are kept.
+func UpdateScenario(ctx context.Context, conn *pgx.Conn, scenario *Scenario) error {
+    result, err := conn.Exec(ctx, `
+        UPDATE scenarios SET
+            title = $2, description = $3, setting = $4, ai_role = $5, learner_goal = $6,
+            target_vocabulary = $7, completion_condition = $8, opening_line = $9
+        WHERE id = $1`,
+        scenario.ID, scenario.Title, scenario.Description, scenario.Setting, scenario.AIRole, scenario.LearnerGoal,
+        scenario.TargetVocabulary, scenario.CompletionCondition, scenario.OpeningLine,
+    )
+    if err != nil {
+        return fmt.Errorf("database update error: %w", err)
+    }
+    if result.RowsAffected() == 0 {
+        return pgx.ErrNoRows
+    }
+    return nil
+}
+
+// DeleteScenario deletes a scenario. Conversations and assignments set in it keep
+// existing without it.
+func DeleteScenario(ctx context.Context, conn *pgx.Conn, scenarioID int) error {
+    result, err := conn.Exec(ctx, "DELETE FROM scenarios WHERE id = $1", scenarioID)
+    if err != nil {
+        return fmt.Errorf("database delete error: %w", err)
+    }
+    if result.RowsAffected() == 0 {
+        return pgx.ErrNoRows
+    }
+    return nil
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// User roles. Teachers manage classes and see their students' work; admins see everything
+// and set roles.
+const (
+    RoleStudent = "student"
+    RoleTeacher = "teacher"
+    RoleAdmin   = "admin"
+)
+
+// UserSummary is a user as listed to admins
+type UserSummary struct {
+    ID        int       `json:"id"`
+    Name      string    `json:"name"`
+    Email     string    `json:"email"`
+    Role      string    `json:"role"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// GetTargetLanguage returns the ISO 639-1 code of the language the user practises
+func GetTargetLanguage(ctx context.Context, conn *pgx.Conn, userID int) (string, error) {
+    var language string
+    err := conn.QueryRow(ctx, "SELECT target_language FROM users WHERE id = $1", userID).Scan(&language)
+    if err != nil {
+        return "", err
+    }
+    return language, nil
+}
+
+// SetTargetLanguage changes the language the user practises in new conversations
+func SetTargetLanguage(ctx context.Context, conn *pgx.Conn, userID int, language string) error {
+    _, err := conn.Exec(ctx,
+        "UPDATE users SET target_language = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1",
+        userID, language,
+    )
+    if err != nil {
+        return fmt.Errorf("database update error: %w", err)
+    }
+    return nil
+}
+
+// GetNativeLanguage returns the ISO 639-1 code of the user's native language, or an empty
+// string if they have not set it
+func GetNativeLanguage(ctx context.Context, conn *pgx.Conn, userID int) (string, error) {
+    var language string
+    err := conn.QueryRow(ctx, "SELECT COALESCE(native_language, '') FROM users WHERE id = $1", userID).Scan(&language)
+    if err != nil {
+        return "", err
+    }
+    return language, nil
+}
+
+// SetNativeLanguage changes the language corrections are explained in; an empty string
+// clears it
+func SetNativeLanguage(ctx context.Context, conn *pgx.Conn, userID int, language string) error {
+    _, err := conn.Exec(ctx,
+        "UPDATE users SET native_language = NULLIF($2, ''), updated_at = CURRENT_TIMESTAMP WHERE id = $1",
+        userID, language,
+    )
+    if err != nil {
+        return fmt.Errorf("database update error: %w", err)
+    }
+    return nil
+}
+
+// GetUserRole returns the role of the user
+func GetUserRole(ctx context.Context, conn *pgx.Conn, userID int) (string, error) {
+    var role string
+    err := conn.QueryRow(ctx, "SELECT role FROM users WHERE id = $1", userID).Scan(&role)
+    if err != nil {
+        return "", err
+    }
+    return role, nil
+}
+
+// SetUserRole changes the role of a user, returning pgx.ErrNoRows if there is no such user
+func SetUserRole(ctx context.Context, conn *pgx.Conn, userID int, role string) error {
+    result, err := conn.Exec(ctx,
+        "UPDATE users SET role = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1",
+        userID, role,
+    )
+    if err != nil {
+        return fmt.Errorf("database update error: %w", err)
+    }
+    if result.RowsAffected() == 0 {
+        return pgx.ErrNoRows
+    }
+    return nil
+}
+
+// ListUsers returns every user, teachers and admins first
+func ListUsers(ctx context.Context, conn *pgx.Conn) ([]UserSummary, error) {
+    rows, err := conn.Query(ctx, `
+        SELECT id, COALESCE(name, ''), COALESCE(email, ''), role, created_at
+        FROM users
+        ORDER BY role = 'student', name, id`)
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    users := []UserSummary{}
+    for rows.Next() {
+        var user UserSummary
+        if err := rows.Scan(&user.ID, &user.Name, &user.Email, &user.Role, &user.CreatedAt); err != nil {
+            return nil, fmt.Errorf("database scan error: %w", err)
+        }
+        users = append(users, user)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return users, nil
+}
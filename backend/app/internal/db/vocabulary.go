@@ -0,0 +1,284 @@
+package db
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+)
+
+// VocabularyWord is a word the user has used or been corrected on, counted over all conversations
+type VocabularyWord struct {
+    Lemma           string    `json:"lemma"`
+    CEFRLevel       *string   `json:"cefr_level"`
+    Frequency       int       `json:"frequency"`
+    CorrectionCount int       `json:"correction_count"`
+    FirstSeenAt     time.Time `json:"first_seen_at"`
+    LastSeenAt      time.Time `json:"last_seen_at"`
+}
+
+// LemmaCount is how often a lemma occurred in one conversation. Frequency counts the
+// student's own turns and CorrectionCount the corrections they were given.
+type LemmaCount struct {
+    Lemma           string
+    CEFRLevel       *string
+    Frequency       int
+    CorrectionCount int
+}
+
+// VocabularyFilter selects a page of a user's vocabulary. Search matches the start of the
+// lemma and Level the CEFR level when set. Sort is one of the VocabularySort values.
+type VocabularyFilter struct {
+    Search string
+    Level  string
+    Sort   string
+    Limit  int
+    Offset int
+}
+
+// Vocabulary sort orders
+const (
+    VocabularySortFrequency = "frequency"
+    VocabularySortRecent    = "recent"
+    VocabularySortAlpha     = "alphabetical"
+)
+
+// vocabularyOrder maps each sort order to its ORDER BY clause
+var vocabularyOrder = map[string]string{
+    VocabularySortFrequency: "frequency DESC, lemma",
+    VocabularySortRecent:    "first_seen_at DESC, lemma",
+    VocabularySortAlpha:     "lemma",
+}
+
+// WordBankEntry is a word the user saved to study later, with the sentence it came from
+type WordBankEntry struct {
+    ID        int       `json:"id"`
+    Word      string    `json:"word"`
+    Lemma     string    `json:"lemma"`
+    CEFRLevel *string   `json:"cefr_level"`
+    Context   string    `json:"context"`
+    SessionID *string   `json:"session_id"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern so a search matches them literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// RecordConversationVocabulary adds the lemmas of a saved conversation to the user's
+// vocabulary. Each conversation is counted once; recording it again does nothing.
+func RecordConversationVocabulary(ctx context.Context, conn *pgx.Conn, conversationID, userID int, seenAt time.Time, counts []LemmaCount) error {
+    tx, err := conn.Begin(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback(ctx)
+
+    result, err := tx.Exec(ctx,
+        "UPDATE conversations SET vocabulary_recorded = TRUE WHERE id = $1 AND NOT vocabulary_recorded",
+        conversationID,
+    )
+    if err != nil {
+        return fmt.Errorf("database update error: %w", err)
+    }
+    if result.RowsAffected() == 0 {
+        return nil
+    }
+
+    lemmas := make([]string, len(counts))
+    levels := make([]*string, len(counts))
+    frequencies := make([]int, len(counts))
+    corrections := make([]int, len(counts))
+    for i, count := range counts {
+        lemmas[i] = count.Lemma
+        levels[i] = count.CEFRLevel
+        frequencies[i] = count.Frequency
+        corrections[i] = count.CorrectionCount
+    }
+
+    _, err = tx.Exec(ctx, `
+        INSERT INTO vocabulary (user_id, lemma, cefr_level, frequency, correction_count, first_seen_at, last_seen_at)
+        SELECT $1, w.lemma, w.cefr_level, w.frequency, w.correction_count, $2, $2
+        FROM unnest($3::text[], $4::text[], $5::int[], $6::int[]) AS w(lemma, cefr_level, frequency, correction_count)
+        ON CONFLICT (user_id, lemma) DO UPDATE SET
+            cefr_level = EXCLUDED.cefr_level,
+            frequency = vocabulary.frequency + EXCLUDED.frequency,
+            correction_count = vocabulary.correction_count + EXCLUDED.correction_count,
+            first_seen_at = LEAST(vocabulary.first_seen_at, EXCLUDED.first_seen_at),
+            last_seen_at = GREATEST(vocabulary.last_seen_at, EXCLUDED.last_seen_at)`,
+        userID, seenAt, lemmas, levels, frequencies, corrections,
+    )
+    if err != nil {
+        return fmt.Errorf("database upsert error: %w", err)
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return fmt.Errorf("failed to commit transaction: %w", err)
+    }
+    return nil
+}
+
+// ListConversationsWithoutVocabulary returns the user's conversations whose words have not
+// been added to their vocabulary yet, oldest first
+func ListConversationsWithoutVocabulary(ctx context.Context, conn *pgx.Conn, userID int) ([]Conversation, error) {
+    rows, err := conn.Query(ctx, `
+        SELECT id, user_id, language, history, created_at
+        FROM conversations
+        WHERE user_id = $1 AND NOT vocabulary_recorded
+        ORDER BY created_at`,
+        userID,
+    )
+    if err != nil {
+        return nil, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    var conversations []Conversation
+    for rows.Next() {
+        var conversation Conversation
+        if err := rows.Scan(&conversation.ID, &conversation.UserID, &conversation.Language, &conversation.History, &conversation.CreatedAt); err != nil {
+            return nil, fmt.Errorf("database scan error: %w", err)
+        }
+        conversations = append(conversations, conversation)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("database rows error: %w", err)
+    }
+    return conversations, nil
+}
+
+// ListVocabulary returns a page of the user's vocabulary together with the number of
+// words matching the filter
+func ListVocabulary(ctx context.Context, conn *pgx.Conn, userID int, filter VocabularyFilter) ([]VocabularyWord, int, error) {
+    order, ok := vocabularyOrder[filter.Sort]
+    if !ok {
+        order = vocabularyOrder[VocabularySortFrequency]
+    }
+    search := likeEscaper.Replace(filter.Search)
+
+    var total int
+    err := conn.QueryRow(ctx, `
+        SELECT COUNT(*) FROM vocabulary
+        WHERE user_id = $1
+            AND ($2 = '' OR lemma LIKE $2 || '%')
+            AND ($3 = '' OR cefr_level = $3)`,
+        userID, search, filter.Level,
+    ).Scan(&total)
+    if err != nil {
+        return nil, 0, fmt.Errorf("database count error: %w", err)
+    }
+
+    rows, err := conn.Query(ctx, `
+        SELECT lemma, cefr_level, frequency, correction_count, first_seen_at, last_seen_at
+        FROM vocabulary
+        WHERE user_id = $1
+            AND ($2 = '' OR lemma LIKE $2 || '%')
+            AND ($3 = '' OR cefr_level = $3)
+        ORDER BY `+order+`
+        LIMIT $4 OFFSET $5`,
+        userID, search, filter.Level, filter.Limit, filter.Offset,
+    )
+    if err != nil {
+        return nil, 0, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    words := []VocabularyWord{}
+    for rows.Next() {
+        var word VocabularyWord
+        err := rows.Scan(&word.Lemma, &word.CEFRLevel, &word.Frequency, &word.CorrectionCount, &word.FirstSeenAt, &word.LastSeenAt)
+        if err != nil {
+            return nil, 0, fmt.Errorf("database scan error: %w", err)
+        }
+        words = append(words, word)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, 0, fmt.Errorf("database rows error: %w", err)
+    }
+    return words, total, nil
+}
+
+// SaveWordBankEntry adds a word to the user's word bank. Saving a word already in the
+// bank replaces its context. The entry's ID and creation time are filled in.
+func SaveWordBankEntry(ctx context.Context, conn *pgx.Conn, userID int, entry *WordBankEntry) error {
+    if entry.SessionID != nil && !sessionIDPattern.MatchString(*entry.SessionID) {
+        entry.SessionID = nil
+    }
+
+    err := conn.QueryRow(ctx, `
+        INSERT INTO word_bank (user_id, word, lemma, cefr_level, context, session_id)
+        VALUES ($1, $2, $3, $4, $5, (SELECT id FROM conversation_sessions WHERE id = $6::uuid AND user_id = $1))
+        ON CONFLICT (user_id, lemma) DO UPDATE SET
+            word = EXCLUDED.word,
+            context = EXCLUDED.context,
+            session_id = COALESCE(EXCLUDED.session_id, word_bank.session_id)
+        RETURNING id, session_id::text, created_at`,
+        userID, entry.Word, entry.Lemma, entry.CEFRLevel, entry.Context, entry.SessionID,
+    ).Scan(&entry.ID, &entry.SessionID, &entry.CreatedAt)
+    if err != nil {
+        return fmt.Errorf("database upsert error: %w", err)
+    }
+    return nil
+}
+
+// ListWordBank returns the user's saved words, newest first, together with the number of
+// words matching the search. Search matches the start of the word or its lemma; a limit
+// of zero returns every word.
+func ListWordBank(ctx context.Context, conn *pgx.Conn, userID int, search string, limit, offset int) ([]WordBankEntry, int, error) {
+    search = likeEscaper.Replace(search)
+
+    var total int
+    err := conn.QueryRow(ctx, `
+        SELECT COUNT(*) FROM word_bank
+        WHERE user_id = $1 AND ($2 = '' OR lower(word) LIKE $2 || '%' OR lemma LIKE $2 || '%')`,
+        userID, search,
+    ).Scan(&total)
+    if err != nil {
+        return nil, 0, fmt.Errorf("database count error: %w", err)
+    }
+
+    var limitArg *int
+    if limit > 0 {
+        limitArg = &limit
+    }
+    rows, err := conn.Query(ctx, `
+        SELECT id, word, lemma, cefr_level, context, session_id::text, created_at
+        FROM word_bank
+        WHERE user_id = $1 AND ($2 = '' OR lower(word) LIKE $2 || '%' OR lemma LIKE $2 || '%')
+        ORDER BY created_at DESC, id DESC
+        LIMIT $3 OFFSET $4`,
+        userID, search, limitArg, offset,
+    )
+    if err != nil {
+        return nil, 0, fmt.Errorf("database query error: %w", err)
+    }
+    defer rows.Close()
+
+    entries := []WordBankEntry{}
+    for rows.Next() {
+        var entry WordBankEntry
+        err := rows.Scan(&entry.ID, &entry.Word, &entry.Lemma, &entry.CEFRLevel, &entry.Context, &entry.SessionID, &entry.CreatedAt)
+        if err != nil {
+            return nil, 0, fmt.Errorf("database scan error: %w", err)
+        }
+        entries = append(entries, entry)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, 0, fmt.Errorf("database rows error: %w", err)
+    }
+    return entries, total, nil
+}
+
+// DeleteWordBankEntry removes a word from the user's word bank. It returns pgx.ErrNoRows
+// if the user has no such word.
+func DeleteWordBankEntry(ctx context.Context, conn *pgx.Conn, userID, entryID int) error {
+    result, err := conn.Exec(ctx, "DELETE FROM word_bank WHERE id = $1 AND user_id = $2", entryID, userID)
+    if err != nil {
+        return fmt.Errorf("database delete error: %w", err)
+    }
+    if result.RowsAffected() == 0 {
+        return pgx.ErrNoRows
+    }
+    return nil
+}
@@ -0,0 +1,64 @@
+package exam
+
+import "time"
+
+// Kinds of speaking exam parts
+const (
+    KindInterview     = "interview"     // Part 1: questions about the candidate
+    KindLongTurn      = "long_turn"     // Part 2: comparing pictures for about a minute
+    KindCollaborative = "collaborative" // Part 3: discussing prompts with a partner and deciding
+    KindDiscussion    = "discussion"    // Part 4: discussing the topic of Part 3 in depth
+)
+
+// Part is one timed part of the Speaking paper
+type Part struct {
+    Number          int    `json:"number"`
+    Title           string `json:"title"`
+    Kind            string `json:"kind"`
+    DurationSeconds int    `json:"duration_seconds"`
+    // Intro is what the interlocutor says, word for word, to open the part
+    Intro string `json:"intro"`
+    // Task is the question printed on the candidate's task sheet
+    Task string `json:"task,omitempty"`
+    // Pictures describes the photographs of a long turn, since they are not shown as images
+    Pictures []string `json:"pictures,omitempty"`
+    // Prompts are the ideas around the question of a collaborative task
+    Prompts []string `json:"prompts,omitempty"`
+    // DecisionQuestion closes the collaborative task
+    DecisionQuestion string `json:"decision_question,omitempty"`
+    // Questions are asked one at a time by the interlocutor
+    Questions []string `json:"questions,omitempty"`
+}
+
+// Duration returns how long the part lasts
+func (p *Part) Duration() time.Duration {
+    return time.Duration(p.DurationSeconds) * time.Second
+}
+
+// Exam is the Speaking paper of a Cambridge exam
+type Exam struct {
+    Slug  string `json:"slug"`
+    Name  string `json:"name"`
+    Level string `json:"level"`
+    Parts []Part `json:"parts"`
+}
+
+// Part returns the part with the given number
+func (e *Exam) Part(number int) (*Part, bool) {
+    if number < 1 || number > len(e.Parts) {
+        return nil, false
+    }
+    return &e.Parts[number-1], true
+}
+
+// exams holds the available papers by slug
+var exams = map[string]*Exam{
+    fce.Slug: fce,
+    cae.Slug: cae,
+}
+
+// Get returns the exam with the given slug
+func Get(slug string) (*Exam, bool) {
+    e, ok := exams[slug]
+    return e, ok
+}
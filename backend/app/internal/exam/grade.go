@@ -0,0 +1,176 @@
+package exam
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log"
+    "strconv"
+    "strings"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/openai"
+)
+
+// PromptVersion identifies the grading prompt stored with each exam report
+const PromptVersion = "cambridge-speaking-v2"
+
+// maxRepairAttempts is how many times invalid output is sent back to the model for repair
+const maxRepairAttempts = 2
+
+// ErrNoCandidateTurns is returned when the candidate said nothing in any part
+var ErrNoCandidateTurns = errors.New("exam has no candidate turns")
+
+const graderPrompt = `You are an experienced Cambridge Speaking examiner acting as the assessor.
+You answer with a single JSON object and nothing else.`
+
+// reportSchema describes the JSON object the model must return
+const reportSchema = `{
+  "parts": [
+    {
+      "part": part number,
+      "scores": {
+        "grammar_vocabulary": 0-5,
+        "discourse_management": 0-5,
+        "pronunciation": 0-5,
+        "interactive_communication": 0-5
+      },
+      "comment": "two or three sentences on the candidate's performance in this part",
+      "strengths": ["what the candidate did well"],
+      "improvements": ["what the candidate should work on, with an example from the transcript"]
+    }
+  ],
+  "summary": "two or three encouraging sentences addressed to the candidate about the whole test"
+}`
+
+// Grade assesses the candidate's performance in each part of the exam on the Cambridge
+// assessment scales and returns a validated report together with the name of the model
+// that graded it.
+func Grade(ctx context.Context, chatModel openai.ChatModel, e *Exam, history []db.ExamTurn) (*Report, string, error) {
+    transcript, parts := formatTranscript(e, history)
+    if len(parts) == 0 {
+        return nil, "", ErrNoCandidateTurns
+    }
+
+    messages := []openai.ChatCompletionMessage{
+        {
+            Role:    "system",
+            Content: graderPrompt,
+        },
+        {
+            Role: "user",
+            Content: `Below is the transcript of a candidate taking the Speaking paper of Cambridge ` + e.Name + `.
+In Part 3 the interlocutor also played the candidate's partner.
+
+Assess the candidate, not the interlocutor, at ` + e.Level + ` level against the Cambridge assessment scales:
+- Grammar and Vocabulary: range and control of grammatical forms and vocabulary.
+- Discourse Management: extent, relevance and coherence of contributions, and use of cohesive devices.
+- Pronunciation: intelligibility, intonation, stress and individual sounds. The transcript comes from
+  speech recognition. Candidate turns include a speech analysis when available: speaking rate, recognizer
+  confidence, pauses and words the recognizer was unsure about. Judge from it and from misrecognised or
+  garbled words; give band 3 when there is no evidence either way.
+- Interactive Communication: initiating and responding, and developing the interaction. In Part 2 judge how
+  well the candidate answered the follow-up question.
+Band 5 means the candidate clearly exceeds what is expected at ` + e.Level + `, band 3 means they meet it, and band 1 means they fall well short.
+Grade only these parts, in which the candidate spoke: ` + joinInts(parts) + `.
+
+Answer with a JSON object in exactly this shape:
+` + reportSchema + `
+
+Transcript:
+` + transcript,
+        },
+    }
+
+    var lastErr error
+    for attempt := 0; attempt <= maxRepairAttempts; attempt++ {
+        chatCompletion, err := chatModel.Complete(ctx, &openai.ChatCompletionRequest{
+            Messages:       messages,
+            ResponseFormat: openai.JSONObjectFormat,
+        })
+        if err != nil {
+            return nil, "", err
+        }
+        if len(chatCompletion.Choices) == 0 {
+            return nil, "", errors.New("model returned no choices")
+        }
+        content := chatCompletion.Choices[0].Message.Content
+
+        report, err := parseReport(content, parts)
+        if err == nil {
+            return report, chatCompletion.Model, nil
+        }
+        lastErr = err
+        log.Printf("Invalid exam report (attempt %d): %v", attempt+1, err)
+
+        // Ask the model to fix its own output
+        messages = append(messages,
+            openai.ChatCompletionMessage{
+                Role:    "assistant",
+                Content: content,
+            },
+            openai.ChatCompletionMessage{
+                Role: "user",
+                Content: "Your answer is not a valid report: " + err.Error() +
+                    "\nReturn the corrected JSON object only, in exactly the requested shape.",
+            },
+        )
+    }
+    return nil, "", fmt.Errorf("model did not return a valid exam report: %w", lastErr)
+}
+
+// formatTranscript writes the history part by part and lists the parts the candidate spoke in
+func formatTranscript(e *Exam, history []db.ExamTurn) (string, []int) {
+    var transcript strings.Builder
+    var parts []int
+    currentPart := 0
+    for _, turn := range history {
+        if turn.Part != currentPart {
+            currentPart = turn.Part
+            title := ""
+            if p, ok := e.Part(turn.Part); ok {
+                title = " (" + p.Title + ")"
+            }
+            transcript.WriteString("\nPart " + strconv.Itoa(turn.Part) + title + "\n")
+        }
+        if turn.Role == RoleCandidate {
+            if len(parts) == 0 || parts[len(parts)-1] != turn.Part {
+                parts = append(parts, turn.Part)
+            }
+            transcript.WriteString("Candidate: " + turn.Content + "\n")
+            if turn.Pronunciation != nil {
+                transcript.WriteString("    Speech: " + turn.Pronunciation.Summary() + "\n")
+            }
+        } else {
+            transcript.WriteString("Interlocutor: " + turn.Content + "\n")
+        }
+    }
+    return transcript.String(), parts
+}
+
+// joinInts lists numbers separated by commas
+func joinInts(numbers []int) string {
+    items := make([]string, len(numbers))
+    for i, n := range numbers {
+        items[i] = strconv.Itoa(n)
+    }
+    return strings.Join(items, ", ")
+}
+
+// parseReport decodes and validates model output, tolerating a surrounding code fence
+func parseReport(content string, parts []int) (*Report, error) {
+    content = strings.TrimSpace(content)
+    if start, end := strings.Index(content, "{"), strings.LastIndex(content, "}"); start >= 0 && end > start {
+        content = content[start : end+1]
+    }
+
+    var report Report
+    if err := json.Unmarshal([]byte(content), &report); err != nil {
+        return nil, fmt.Errorf("invalid JSON: %w", err)
+    }
+    if err := report.Validate(parts); err != nil {
+        return nil, err
+    }
+    return &report, nil
+}
@@ -0,0 +1,92 @@
+package exam
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/openai"
+)
+
+// Speaker roles stored in the exam history
+const (
+    RoleInterlocutor = "interlocutor"
+    RoleCandidate    = "candidate"
+)
+
+// interlocutorPrompt describes the interlocutor's job in the current part. elapsed is the
+// time since the part started; once it reaches the part duration the part is closed.
+func interlocutorPrompt(e *Exam, p *Part, elapsed time.Duration) string {
+    var prompt strings.Builder
+    fmt.Fprintf(&prompt, "You are the interlocutor in the Speaking paper of the Cambridge %s exam (CEFR %s). ", e.Name, e.Level)
+    prompt.WriteString("Behave exactly like a real Cambridge interlocutor: neutral, polite and brief. ")
+    prompt.WriteString("Never correct the candidate, never give feedback or scores, and never explain the exam. ")
+    prompt.WriteString("Say only the words you would speak aloud, in one or two sentences.\n\n")
+    fmt.Fprintf(&prompt, "This is Part %d (%s), which lasts %d seconds. %d seconds have passed.\n", p.Number, p.Title, p.DurationSeconds, int(elapsed.Seconds()))
+
+    switch p.Kind {
+    case KindInterview:
+        prompt.WriteString("Ask the candidate about themselves. Ask these questions one at a time, in order, skipping any already answered:\n")
+        writeList(&prompt, p.Questions)
+        prompt.WriteString("You may ask a short follow-up such as \"Why?\" if the answer was very short.\n")
+    case KindLongTurn:
+        prompt.WriteString("The candidate has been asked to speak on their own for about a minute about these pictures:\n")
+        writeList(&prompt, p.Pictures)
+        prompt.WriteString("Task: " + p.Task + "\n")
+        prompt.WriteString("If the candidate stops well before a minute, encourage them once to say more about the task. ")
+        prompt.WriteString("Once they have finished, ask this question:\n")
+        writeList(&prompt, p.Questions)
+        prompt.WriteString("After they answer it, say \"Thank you.\"\n")
+    case KindCollaborative:
+        prompt.WriteString("The candidate has no partner in this simulation, so you also play the second candidate, Alex, who is at the same level. ")
+        prompt.WriteString("As Alex, discuss the task with the candidate: give your opinion on one prompt at a time, react to what they say, and ask for their view. Do not dominate the discussion.\n")
+        prompt.WriteString("Task: " + p.Task + "\n")
+        prompt.WriteString("Prompts:\n")
+        writeList(&prompt, p.Prompts)
+        fmt.Fprintf(&prompt, "After about two minutes of discussion, speak as the interlocutor again and say: %q Then, as Alex, help reach a decision together.\n", p.DecisionQuestion)
+    case KindDiscussion:
+        prompt.WriteString("Lead a discussion of the topic of Part 3. Ask these questions one at a time, choosing the ones that follow best from the candidate's answers:\n")
+        writeList(&prompt, p.Questions)
+        prompt.WriteString("You may ask \"What do you think?\" or \"Why?\" to help the candidate develop an answer.\n")
+    }
+
+    if elapsed >= p.Duration() {
+        fmt.Fprintf(&prompt, "\nTime is up. Thank the candidate and close the part with: \"Thank you. That is the end of Part %d.\"", p.Number)
+    }
+    return prompt.String()
+}
+
+// writeList writes each item on its own line as a bullet
+func writeList(b *strings.Builder, items []string) {
+    for _, item := range items {
+        b.WriteString("- " + item + "\n")
+    }
+}
+
+// InterlocutorMessages builds the messages for the interlocutor's next words in the
+// current part, given the history including the candidate's latest turn
+func InterlocutorMessages(e *Exam, p *Part, history []db.ExamTurn, elapsed time.Duration) []openai.ChatCompletionMessage {
+    messages := []openai.ChatCompletionMessage{
+        {
+            Role:    "system",
+            Content: interlocutorPrompt(e, p, elapsed),
+        },
+    }
+
+    // Only the current part is relevant to what the interlocutor says next
+    for _, turn := range history {
+        if turn.Part != p.Number {
+            continue
+        }
+        role := "user"
+        if turn.Role == RoleInterlocutor {
+            role = "assistant"
+        }
+        messages = append(messages, openai.ChatCompletionMessage{
+            Role:    role,
+            Content: turn.Content,
+        })
+    }
+    return messages
+}
@@ -0,0 +1,141 @@
+package exam
+
+// The papers follow the format of the Cambridge Speaking tests. Timings are those of the
+// real test for a pair of candidates; here the examiner's assistant plays the partner.
+
+var fce = &Exam{
+    Slug:  "fce",
+    Name:  "B2 First (FCE)",
+    Level: "B2",
+    Parts: []Part{
+        {
+            Number:          1,
+            Title:           "Interview",
+            Kind:            KindInterview,
+            DurationSeconds: 120,
+            Intro:           "Good morning. My name is Morgan and I'm your examiner today. First, we'd like to know something about you. Where are you from?",
+            Questions: []string{
+                "What do you like about living there?",
+                "Do you prefer spending time at home or going out? Why?",
+                "What did you do last weekend?",
+                "Tell us about a hobby you enjoy.",
+                "How important is music in your life?",
+                "Would you like to live in another country in the future?",
+            },
+        },
+        {
+            Number:          2,
+            Title:           "Long turn",
+            Kind:            KindLongTurn,
+            DurationSeconds: 240,
+            Intro:           "In this part of the test, I'm going to give you two photographs. I'd like you to talk about them on your own for about a minute. Your photographs show people spending their free time in different places. I'd like you to compare the photographs, and say why you think the people have chosen to spend their free time in these places. All right?",
+            Task:            "Why have the people chosen to spend their free time in these places?",
+            Pictures: []string{
+                "A family having a picnic on the grass in a busy city park on a sunny day.",
+                "A group of teenagers playing video games together in a small living room.",
+            },
+            Questions: []string{
+                "Where do you prefer to spend your free time, indoors or outdoors? Why?",
+            },
+        },
+        {
+            Number:          3,
+            Title:           "Collaborative task",
+            Kind:            KindCollaborative,
+            DurationSeconds: 240,
+            Intro:           "Now, I'd like you to talk about something together for about two minutes. Here are some things that people often do to stay healthy and a question for you to discuss. First you have some time to look at the task.",
+            Task:            "How useful are these things for staying healthy?",
+            Prompts: []string{
+                "doing sport",
+                "eating fresh food",
+                "getting enough sleep",
+                "spending time with friends",
+                "walking instead of driving",
+            },
+            DecisionQuestion: "Now you have about a minute to decide which of these things is the easiest for young people to do.",
+        },
+        {
+            Number:          4,
+            Title:           "Discussion",
+            Kind:            KindDiscussion,
+            DurationSeconds: 240,
+            Intro:           "Now I'd like to ask you some more questions about health. Some people say that young people today are less healthy than in the past. What do you think?",
+            Questions: []string{
+                "Should schools teach students how to cook healthy meals? Why or why not?",
+                "Do you think people worry too much about their health these days?",
+                "Who should be responsible for people's health, the government or individuals?",
+                "How has technology changed the way people exercise?",
+                "Is it better to exercise alone or with other people? Why?",
+            },
+        },
+    },
+}
+
+var cae = &Exam{
+    Slug:  "cae",
+    Name:  "C1 Advanced (CAE)",
+    Level: "C1",
+    Parts: []Part{
+        {
+            Number:          1,
+            Title:           "Interview",
+            Kind:            KindInterview,
+            DurationSeconds: 120,
+            Intro:           "Good afternoon. My name is Morgan and I'm your examiner today. First of all, we'd like to know something about you. Where are you from?",
+            Questions: []string{
+                "What do you enjoy most about the place where you live?",
+                "How do you usually keep in touch with friends who live far away?",
+                "If you could learn a completely new skill, what would it be?",
+                "What kind of books or films do you find most rewarding?",
+                "How do you think your life will change in the next few years?",
+                "Is there a tradition from your country that you would like to keep alive?",
+            },
+        },
+        {
+            Number:          2,
+            Title:           "Long turn",
+            Kind:            KindLongTurn,
+            DurationSeconds: 240,
+            Intro:           "In this part of the test, I'm going to give you three pictures. I'd like you to talk about two of them on your own for about a minute. The pictures show people learning new things in different situations. I'd like you to compare two of the pictures, and say why the people might have decided to learn in this way, and how difficult it might be for them. All right?",
+            Task:            "Why might the people have decided to learn in this way? How difficult might it be for them?",
+            Pictures: []string{
+                "An older man practising the violin with a teacher in a quiet music room.",
+                "A young woman following an online coding course on her laptop late at night.",
+                "A group of adults in aprons learning to cook at a busy cookery class.",
+            },
+            Questions: []string{
+                "Which of these ways of learning would you find most effective? Why?",
+            },
+        },
+        {
+            Number:          3,
+            Title:           "Collaborative task",
+            Kind:            KindCollaborative,
+            DurationSeconds: 240,
+            Intro:           "Now, I'd like you to talk about something together for about two minutes. Here are some factors which can influence the choices people make about where to work, and a question for you to discuss. First you have some time to look at the task.",
+            Task:            "How might these factors influence people's choice of where to work?",
+            Prompts: []string{
+                "salary",
+                "opportunities for promotion",
+                "the journey to work",
+                "the working environment",
+                "the reputation of the company",
+            },
+            DecisionQuestion: "Now you have about a minute to decide which factor is likely to matter most to people starting their careers.",
+        },
+        {
+            Number:          4,
+            Title:           "Discussion",
+            Kind:            KindDiscussion,
+            DurationSeconds: 300,
+            Intro:           "Now I'd like to ask you some more questions about work. Some people say it is more important to enjoy your job than to earn a lot of money. How far do you agree?",
+            Questions: []string{
+                "Do you think the idea of a job for life has disappeared? Why?",
+                "How might working from home affect people's relationships with colleagues?",
+                "Should employers be responsible for the wellbeing of their staff?",
+                "To what extent should young people be guided by their parents when choosing a career?",
+                "What skills do you think will be most valuable in the workplace of the future?",
+            },
+        },
+    },
+}
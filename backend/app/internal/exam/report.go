@@ -0,0 +1,129 @@
+package exam
+
+import (
+    "errors"
+    "fmt"
+    "math"
+    "slices"
+    "strings"
+)
+
+// MaxBand is the top band of each Cambridge assessment scale
+const MaxBand = 5
+
+// Scores rates a part on the Cambridge assessment scales, in bands from 0 to MaxBand
+type Scores struct {
+    GrammarVocabulary        int `json:"grammar_vocabulary"`
+    DiscourseManagement      int `json:"discourse_management"`
+    Pronunciation            int `json:"pronunciation"`
+    InteractiveCommunication int `json:"interactive_communication"`
+}
+
+// OverallScores averages the bands of the graded parts. Total is out of 4 * MaxBand.
+type OverallScores struct {
+    GrammarVocabulary        float64 `json:"grammar_vocabulary"`
+    DiscourseManagement      float64 `json:"discourse_management"`
+    Pronunciation            float64 `json:"pronunciation"`
+    InteractiveCommunication float64 `json:"interactive_communication"`
+    Total                    float64 `json:"total"`
+}
+
+// PartReport is the assessment of one part of the test
+type PartReport struct {
+    Part         int      `json:"part"`
+    Scores       Scores   `json:"scores"`
+    Comment      string   `json:"comment"`
+    Strengths    []string `json:"strengths"`
+    Improvements []string `json:"improvements"`
+}
+
+// Report is the assessment of a whole Speaking test
+type Report struct {
+    Parts   []PartReport  `json:"parts"`
+    Overall OverallScores `json:"overall"`
+    Summary string        `json:"summary"`
+}
+
+// Validate checks a report parsed from model output. parts lists the numbers of the parts
+// the candidate spoke in, each of which must be graded exactly once.
+func (r *Report) Validate(parts []int) error {
+    var errs []error
+
+    seen := map[int]bool{}
+    for i := range r.Parts {
+        part := &r.Parts[i]
+        if !slices.Contains(parts, part.Part) {
+            errs = append(errs, fmt.Errorf("parts[%d].part must be one of %v, got %d", i, parts, part.Part))
+        } else if seen[part.Part] {
+            errs = append(errs, fmt.Errorf("part %d is graded more than once", part.Part))
+        }
+        seen[part.Part] = true
+
+        for _, score := range []struct {
+            name  string
+            value int
+        }{
+            {"grammar_vocabulary", part.Scores.GrammarVocabulary},
+            {"discourse_management", part.Scores.DiscourseManagement},
+            {"pronunciation", part.Scores.Pronunciation},
+            {"interactive_communication", part.Scores.InteractiveCommunication},
+        } {
+            if score.value < 0 || score.value > MaxBand {
+                errs = append(errs, fmt.Errorf("parts[%d].scores.%s must be between 0 and %d, got %d", i, score.name, MaxBand, score.value))
+            }
+        }
+
+        if strings.TrimSpace(part.Comment) == "" {
+            errs = append(errs, fmt.Errorf("parts[%d].comment must not be empty", i))
+        }
+        if part.Strengths == nil {
+            part.Strengths = []string{}
+        }
+        if part.Improvements == nil {
+            part.Improvements = []string{}
+        }
+    }
+    for _, number := range parts {
+        if !seen[number] {
+            errs = append(errs, fmt.Errorf("part %d is missing", number))
+        }
+    }
+
+    if strings.TrimSpace(r.Summary) == "" {
+        errs = append(errs, errors.New("summary must not be empty"))
+    }
+
+    if len(errs) > 0 {
+        return errors.Join(errs...)
+    }
+
+    slices.SortFunc(r.Parts, func(a, b PartReport) int { return a.Part - b.Part })
+    r.Overall = overall(r.Parts)
+    return nil
+}
+
+// overall averages the part scores, so it is computed rather than trusted from the model
+func overall(parts []PartReport) OverallScores {
+    var o OverallScores
+    if len(parts) == 0 {
+        return o
+    }
+    for _, part := range parts {
+        o.GrammarVocabulary += float64(part.Scores.GrammarVocabulary)
+        o.DiscourseManagement += float64(part.Scores.DiscourseManagement)
+        o.Pronunciation += float64(part.Scores.Pronunciation)
+        o.InteractiveCommunication += float64(part.Scores.InteractiveCommunication)
+    }
+    n := float64(len(parts))
+    o.GrammarVocabulary = round(o.GrammarVocabulary / n)
+    o.DiscourseManagement = round(o.DiscourseManagement / n)
+    o.Pronunciation = round(o.Pronunciation / n)
+    o.InteractiveCommunication = round(o.InteractiveCommunication / n)
+    o.Total = round(o.GrammarVocabulary + o.DiscourseManagement + o.Pronunciation + o.InteractiveCommunication)
+    return o
+}
+
+// round keeps one decimal place
+func round(value float64) float64 {
+    return math.Round(value*10) / 10
+}
@@ -0,0 +1,193 @@
+package failover
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "sync"
+    "time"
+)
+
+// Breaker is a circuit breaker that marks a provider unhealthy after consecutive
+// failures. Once the cool-down has passed it is half-open: a single trial request is let
+// through, which closes the breaker if it succeeds and opens it again if it fails.
+type Breaker struct {
+    Threshold int
+    Cooldown  time.Duration
+
+    mu        sync.Mutex
+    failures  int
+    openUntil time.Time
+    trial     bool
+}
+
+// Healthy reports whether requests should be sent to the provider, without taking the
+// trial request of a half-open breaker
+func (b *Breaker) Healthy() bool {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    return b.openUntil.IsZero() || (time.Now().After(b.openUntil) && !b.trial)
+}
+
+// Allow reports whether a request may be sent to the provider. When the breaker is
+// half-open the first caller gets the trial request and the others are refused until it
+// reports back with Success, Failure or Release.
+func (b *Breaker) Allow() bool {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    if b.openUntil.IsZero() {
+        return true
+    }
+    if time.Now().Before(b.openUntil) || b.trial {
+        return false
+    }
+    b.trial = true
+    return true
+}
+
+// Success closes the breaker
+func (b *Breaker) Success() {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    b.failures = 0
+    b.openUntil = time.Time{}
+    b.trial = false
+}
+
+// Failure counts a failed request and opens the breaker once the threshold is reached.
+// A failure while the breaker is open or half-open opens it again straight away. It
+// reports whether the breaker was opened.
+func (b *Breaker) Failure() bool {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    b.trial = false
+    if b.openUntil.IsZero() {
+        b.failures++
+        if b.failures < b.Threshold {
+            return false
+        }
+    }
+    b.failures = 0
+    b.openUntil = time.Now().Add(b.Cooldown)
+    return true
+}
+
+// Release gives back the trial request of a half-open breaker when it ended without
+// saying anything about the provider, e.g. because the request was cancelled
+func (b *Breaker) Release() {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    b.trial = false
+}
+
+// Retrier is implemented by providers whose own retries can be limited
+type Retrier interface {
+    SetMaxRetries(maxRetries int)
+}
+
+// member is a provider in a chain together with its breaker
+type member[T any] struct {
+    name     string
+    provider T
+    breaker  *Breaker
+}
+
+// Chain tries an ordered list of providers until one of them succeeds
+type Chain[T any] struct {
+    kind      string
+    threshold int
+    cooldown  time.Duration
+    members   []*member[T]
+}
+
+// NewChain creates an empty chain. The kind names the service in log messages.
+func NewChain[T any](kind string, threshold int, cooldown time.Duration) *Chain[T] {
+    if threshold < 1 {
+        threshold = 1
+    }
+    return &Chain[T]{
+        kind:      kind,
+        threshold: threshold,
+        cooldown:  cooldown,
+    }
+}
+
+// Add appends a provider to the end of the chain
+func (c *Chain[T]) Add(name string, provider T) {
+    c.members = append(c.members, &member[T]{
+        name:     name,
+        provider: provider,
+        breaker:  &Breaker{Threshold: c.threshold, Cooldown: c.cooldown},
+    })
+}
+
+// Names lists the providers in the order they are tried
+func (c *Chain[T]) Names() []string {
+    names := make([]string, 0, len(c.members))
+    for _, m := range c.members {
+        names = append(names, m.name)
+    }
+    return names
+}
+
+// LimitRetries sets the retries of every provider that has another one after it in the
+// chain, so that a failing provider hands over to the next one instead of retrying. The
+// last provider keeps its own retries.
+func (c *Chain[T]) LimitRetries(maxRetries int) {
+    for i := 0; i < len(c.members)-1; i++ {
+        if retrier, ok := any(c.members[i].provider).(Retrier); ok {
+            retrier.SetMaxRetries(maxRetries)
+        }
+    }
+}
+
+// Do calls fn with each healthy provider in order until one succeeds and returns the
+// name of the provider that served the request. Providers whose breaker is open are
+// skipped; when no provider is available the request fails at once, and a provider
+// comes back through the trial request of its half-open breaker.
+func (c *Chain[T]) Do(ctx context.Context, fn func(ctx context.Context, provider T) error) (string, error) {
+    if len(c.members) == 0 {
+        return "", fmt.Errorf("no %s providers configured", c.kind)
+    }
+
+    var errs []error
+    for _, m := range c.members {
+        if !m.breaker.Allow() {
+            continue
+        }
+        name, err := c.try(ctx, m, fn)
+        if err == nil || ctx.Err() != nil {
+            return name, err
+        }
+        errs = append(errs, err)
+    }
+
+    if len(errs) == 0 {
+        return "", fmt.Errorf("all %s providers unavailable", c.kind)
+    }
+    return "", fmt.Errorf("all %s providers failed: %w", c.kind, errors.Join(errs...))
+}
+
+// try calls fn with one provider and reports the outcome to its breaker
+func (c *Chain[T]) try(ctx context.Context, m *member[T], fn func(ctx context.Context, provider T) error) (string, error) {
+    err := fn(ctx, m.provider)
+    if err == nil {
+        m.breaker.Success()
+        log.Printf("%s served by %s", c.kind, m.name)
+        return m.name, nil
+    }
+
+    // A cancelled request says nothing about the health of the provider
+    if ctx.Err() != nil {
+        m.breaker.Release()
+        return "", err
+    }
+
+    if m.breaker.Failure() {
+        log.Printf("%s provider %s marked unhealthy for %s: %v", c.kind, m.name, c.cooldown, err)
+    } else {
+        log.Printf("%s provider %s failed: %v", c.kind, m.name, err)
+    }
+    return "", fmt.Errorf("%s: %w", m.name, err)
+}
@@ -0,0 +1,184 @@
+package grammar
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "sort"
+    "time"
+
+    "PulpuVOX/internal/correction"
+    "PulpuVOX/internal/db"
+    "github.com/jackc/pgx/v5"
+)
+
+// maxInsights is how many of the most frequent error types Insights describes
+const maxInsights = 3
+
+// labels name each error type of the taxonomy for display
+var labels = map[string]string{
+    correction.ErrorArticle:     "Articles",
+    correction.ErrorTense:       "Verb tense",
+    correction.ErrorAgreement:   "Subject-verb agreement",
+    correction.ErrorPreposition: "Prepositions",
+    correction.ErrorPlural:      "Plurals",
+    correction.ErrorWordChoice:  "Word choice",
+    correction.ErrorWordOrder:   "Word order",
+    correction.ErrorSpelling:    "Spelling",
+    correction.ErrorOther:       "Missing or extra words",
+}
+
+// mistakes describe each error type as something the student does
+var mistakes = map[string]string{
+    correction.ErrorArticle:     "leave out or misuse articles (a, an, the)",
+    correction.ErrorTense:       "use the wrong verb tense",
+    correction.ErrorAgreement:   "make the verb disagree with its subject",
+    correction.ErrorPreposition: "use the wrong preposition",
+    correction.ErrorPlural:      "use the singular instead of the plural or the other way round",
+    correction.ErrorWordChoice:  "choose the wrong word",
+    correction.ErrorWordOrder:   "put words in the wrong order",
+    correction.ErrorSpelling:    "use a wrong form of a word",
+    correction.ErrorOther:       "leave out a word or add one that is not needed",
+}
+
+// Spans returns the classified changes of a student turn's correction in a language. Turns
+// saved before the changes were stored are diffed again.
+func Spans(turn db.ConversationTurn, language string) []correction.Span {
+    if turn.SuggestionDiff != nil || turn.Suggestion == "" {
+        return turn.SuggestionDiff
+    }
+    return correction.Diff(turn.Content, turn.Suggestion, language)
+}
+
+// Count classifies the corrections of the student's turns in a conversation in a language.
+// It returns the number of turns and the errors by type, in the order of the taxonomy.
+func Count(history []db.ConversationTurn, language string) (int, []db.GrammarErrorCount) {
+    sentences := 0
+    counts := map[string]*db.GrammarErrorCount{}
+    for _, turn := range history {
+        if turn.Role != "user" {
+            continue
+        }
+        sentences++
+
+        seen := map[string]bool{}
+        for _, span := range Spans(turn, language) {
+            if span.Op == correction.OpEqual || span.Type == "" {
+                continue
+            }
+            if counts[span.Type] == nil {
+                counts[span.Type] = &db.GrammarErrorCount{ErrorType: span.Type}
+            }
+            counts[span.Type].Errors++
+            if !seen[span.Type] {
+                seen[span.Type] = true
+                counts[span.Type].Sentences++
+            }
+        }
+    }
+
+    var errors []db.GrammarErrorCount
+    for _, errorType := range correction.ErrorTypes {
+        if count := counts[errorType]; count != nil {
+            errors = append(errors, *count)
+        }
+    }
+    return sentences, errors
+}
+
+// Record counts and stores the grammar errors of a saved conversation, replacing earlier
+// counts. Corrections its teacher overrode are classified from the teacher's correction.
+func Record(ctx context.Context, conn *pgx.Conn, conversation *db.Conversation) error {
+    history, err := db.CorrectedHistory(ctx, conn, conversation)
+    if err != nil {
+        return err
+    }
+    sentences, errors := Count(history, conversation.Language)
+    return db.SaveGrammarCount(ctx, conn, &db.GrammarCount{
+        ConversationID: conversation.ID,
+        UserID:         conversation.UserID,
+        Sentences:      sentences,
+        Errors:         errors,
+        ConversationAt: conversation.CreatedAt,
+    })
+}
+
+// Backfill counts the grammar errors of the user's conversations saved before they were tracked
+func Backfill(ctx context.Context, conn *pgx.Conn, userID int) error {
+    conversations, err := db.ListConversationsWithoutGrammar(ctx, conn, userID)
+    if err != nil {
+        return err
+    }
+    for i := range conversations {
+        if err := Record(ctx, conn, &conversations[i]); err != nil {
+            return fmt.Errorf("conversation %d: %w", conversations[i].ID, err)
+        }
+    }
+    return nil
+}
+
+// ErrorStat is how often one type of error occurs. Rate is the share of sentences that
+// had the error, from 0 to 1.
+type ErrorStat struct {
+    Type      string  `json:"type"`
+    Label     string  `json:"label"`
+    Sentences int     `json:"sentences"`
+    Errors    int     `json:"errors"`
+    Rate      float64 `json:"rate"`
+}
+
+// Stats are the grammar errors over a number of sentences, most frequent first
+type Stats struct {
+    Sentences int         `json:"sentences"`
+    Errors    []ErrorStat `json:"errors"`
+}
+
+// Summarize turns error counts into rates over the sentences
+func Summarize(sentences int, counts []db.GrammarErrorCount) *Stats {
+    stats := &Stats{Sentences: sentences, Errors: []ErrorStat{}}
+    for _, count := range counts {
+        stat := ErrorStat{
+            Type:      count.ErrorType,
+            Label:     labels[count.ErrorType],
+            Sentences: count.Sentences,
+            Errors:    count.Errors,
+        }
+        if stat.Label == "" {
+            stat.Label = count.ErrorType
+        }
+        if sentences > 0 {
+            stat.Rate = math.Round(float64(count.Sentences)/float64(sentences)*100) / 100
+        }
+        stats.Errors = append(stats.Errors, stat)
+    }
+    sort.SliceStable(stats.Errors, func(i, j int) bool {
+        return stats.Errors[i].Sentences > stats.Errors[j].Sentences
+    })
+    return stats
+}
+
+// Load adds up the user's grammar errors in a date range, either end of which may be nil
+func Load(ctx context.Context, conn *pgx.Conn, userID int, from, to *time.Time) (*Stats, error) {
+    sentences, counts, err := db.SumGrammarErrors(ctx, conn, userID, from, to)
+    if err != nil {
+        return nil, err
+    }
+    return Summarize(sentences, counts), nil
+}
+
+// Insights describes the most frequent errors, for example
+// "You leave out or misuse articles (a, an, the) in 40% of your sentences"
+func (s *Stats) Insights() []string {
+    insights := []string{}
+    for _, stat := range s.Errors {
+        if len(insights) == maxInsights {
+            break
+        }
+        mistake, ok := mistakes[stat.Type]
+        if !ok {
+            continue
+        }
+        insights = append(insights, fmt.Sprintf("You %s in %d%% of your sentences", mistake, int(math.Round(stat.Rate*100))))
+    }
+    return insights
+}
@@ -0,0 +1,81 @@
+package admin
+
+import (
+    "encoding/json"
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/middleware"
+    "github.com/jackc/pgx/v5"
+)
+
+// ListUsersHandler returns every user with their role to an admin
+func ListUsersHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    if _, _, ok := middleware.RequireRole(w, r, conn, db.RoleAdmin); !ok {
+        return
+    }
+
+    users, err := db.ListUsers(r.Context(), conn)
+    if err != nil {
+        log.Printf("Error listing users: %v", err)
+        http.Error(w, "Failed to load users", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "users": users,
+    })
+}
+
+// SetRoleHandler changes the role of a user. Admins cannot change their own role, so there
+// is always an admin left. The first admin is set in the database.
+func SetRoleHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    adminID, _, ok := middleware.RequireRole(w, r, conn, db.RoleAdmin)
+    if !ok {
+        return
+    }
+
+    userID, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        http.Error(w, "User not found", http.StatusNotFound)
+        return
+    }
+    if userID == adminID {
+        http.Error(w, "Admins cannot change their own role", http.StatusBadRequest)
+        return
+    }
+
+    var request struct {
+        Role string `json:"role"`
+    }
+    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    switch request.Role {
+    case db.RoleStudent, db.RoleTeacher, db.RoleAdmin:
+    default:
+        http.Error(w, "role must be student, teacher or admin", http.StatusBadRequest)
+        return
+    }
+
+    if err := db.SetUserRole(r.Context(), conn, userID, request.Role); err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "User not found", http.StatusNotFound)
+            return
+        }
+        log.Printf("Error setting user role: %v", err)
+        http.Error(w, "Failed to save role", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "id":   userID,
+        "role": request.Role,
+    })
+}
@@ -0,0 +1,184 @@
+package anki
+
+import (
+    "context"
+    "log"
+    "net/http"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "PulpuVOX/internal/anki"
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/middleware"
+    "PulpuVOX/internal/review"
+    "PulpuVOX/internal/tts"
+    "github.com/jackc/pgx/v5"
+)
+
+// Limits of the audio included in an export
+const (
+    // maxAudioNotes caps how many corrected sentences are synthesized for one export
+    maxAudioNotes = 100
+    // audioWorkers is how many sentences are synthesized at the same time
+    audioWorkers = 4
+    // notesMargin is the time kept before the response deadline for writing the notes file
+    // once synthesis stops
+    notesMargin = 10 * time.Second
+)
+
+// ExportHandler downloads the user's corrections and saved words as Anki notes.
+// Query parameters: include (corrections, words or both, comma separated; default both)
+// and audio. Without audio the response is a tab-separated notes file for Anki's
+// File > Import. With audio=true it is a zip archive, not an .apkg, of the notes file and
+// a recording of each corrected sentence, which the user copies into Anki's
+// collection.media folder before importing the notes. At most maxAudioNotes sentences are
+// spoken, and only as many as are synthesized before the response deadline draws near;
+// the other notes are exported without audio.
+func ExportHandler(synthesizer tts.Synthesizer) func(http.ResponseWriter, *http.Request, *pgx.Conn) {
+    return func(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+        includeCorrections, includeWords := true, true
+        if include := r.URL.Query().Get("include"); include != "" {
+            includeCorrections, includeWords = false, false
+            for _, item := range strings.Split(include, ",") {
+                switch strings.TrimSpace(item) {
+                case "corrections":
+                    includeCorrections = true
+                case "words":
+                    includeWords = true
+                default:
+                    http.Error(w, "include must list corrections and/or words", http.StatusBadRequest)
+                    return
+                }
+            }
+        }
+        withAudio, _ := strconv.ParseBool(r.URL.Query().Get("audio"))
+
+        userID, ok := middleware.CurrentUserID(w, r, conn)
+        if !ok {
+            return
+        }
+
+        var notes []anki.Note
+        var cards []db.ReviewCard
+        if includeCorrections {
+            // Corrections from conversations saved before reviews existed are included too
+            if err := review.Backfill(r.Context(), conn, userID); err != nil {
+                log.Printf("Error backfilling review cards: %v", err)
+            }
+            var err error
+            cards, err = db.ListReviewCards(r.Context(), conn, userID)
+            if err != nil {
+                log.Printf("Error listing review cards: %v", err)
+                http.Error(w, "Failed to export corrections", http.StatusInternalServerError)
+                return
+            }
+            for i := range cards {
+                notes = append(notes, anki.CorrectionNote(&cards[i]))
+            }
+        }
+        if includeWords {
+            entries, _, err := db.ListWordBank(r.Context(), conn, userID, "", 0, 0)
+            if err != nil {
+                log.Printf("Error listing word bank: %v", err)
+                http.Error(w, "Failed to export word bank", http.StatusInternalServerError)
+                return
+            }
+            for i := range entries {
+                notes = append(notes, anki.WordNote(&entries[i]))
+            }
+        }
+
+        fileName := "pulpuvox-anki-" + time.Now().Format("2006-01-02")
+        if !withAudio {
+            w.Header().Set("Content-Type", "text/tab-separated-values; charset=utf-8")
+            w.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`.txt"`)
+            if err := anki.WriteNotes(w, notes); err != nil {
+                log.Printf("Error writing Anki notes: %v", err)
+            }
+            return
+        }
+
+        // Synthesis stops in time to write the notes file before the response deadline
+        audioCtx, cancel := context.WithCancel(r.Context())
+        if deadline, ok := r.Context().Deadline(); ok {
+            audioCtx, cancel = context.WithDeadline(r.Context(), deadline.Add(-notesMargin))
+        }
+        defer cancel()
+
+        w.Header().Set("Content-Type", "application/zip")
+        w.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`.zip"`)
+        pkg := anki.NewPackage(w)
+        controller := http.NewResponseController(w)
+
+        // Each recording is sent as soon as it is ready. Correction notes come first, so
+        // notes[i] belongs to cards[i].
+        var writeErr error
+        synthesizeAll(audioCtx, synthesizer, cards, func(i int, audio []byte) {
+            if writeErr != nil {
+                return
+            }
+            name := "pulpuvox-correction-" + strconv.Itoa(cards[i].ID) + anki.AudioExtension(audio)
+            if writeErr = pkg.AddSound(name, audio); writeErr != nil {
+                cancel()
+                return
+            }
+            controller.Flush()
+            notes[i].Audio = name
+        })
+        if writeErr == nil {
+            writeErr = pkg.Close(notes)
+        }
+        if writeErr != nil {
+            log.Printf("Error writing Anki package: %v", writeErr)
+        }
+    }
+}
+
+// synthesizeAll speaks the corrected sentence of each card, up to maxAudioNotes of them,
+// until ctx ends. add is called with each recording from the caller's goroutine, in the
+// order they are ready. A sentence that could not be synthesized has no recording, and its
+// note is exported without.
+func synthesizeAll(ctx context.Context, synthesizer tts.Synthesizer, cards []db.ReviewCard, add func(i int, audio []byte)) {
+    type recording struct {
+        index int
+        audio []byte
+    }
+    jobs := make(chan int)
+    recordings := make(chan recording)
+    var wg sync.WaitGroup
+    for range audioWorkers {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for i := range jobs {
+                ttsResp, err := synthesizer.Synthesize(ctx, &tts.TTSRequest{Text: cards[i].Corrected, Language: cards[i].Language})
+                if err != nil {
+                    log.Printf("TTS conversion failed: %v", err)
+                    continue
+                }
+                if ttsResp.Error != "" {
+                    log.Printf("TTS error: %s", ttsResp.Error)
+                    continue
+                }
+                recordings <- recording{index: i, audio: ttsResp.AudioData}
+            }
+        }()
+    }
+    go func() {
+        defer close(recordings)
+        defer wg.Wait()
+        defer close(jobs)
+        for i := range min(len(cards), maxAudioNotes) {
+            select {
+            case jobs <- i:
+            case <-ctx.Done():
+                return
+            }
+        }
+    }()
+    for recording := range recordings {
+        add(recording.index, recording.audio)
+    }
+}
@@ -0,0 +1,263 @@
+package annotations
+
+import (
+    "encoding/json"
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+
+    "PulpuVOX/internal/assessment"
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/grammar"
+    "PulpuVOX/internal/learner"
+    "PulpuVOX/internal/middleware"
+    "PulpuVOX/internal/review"
+    "github.com/jackc/pgx/v5"
+)
+
+// Length limits of a teacher's comments and corrections
+const (
+    maxCommentLength    = 2000
+    maxCorrectionLength = 1000
+)
+
+// studentConversation resolves the student conversation named by the student_id and id
+// path values for their teacher, returning the teacher's ID with it
+func studentConversation(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) (int, *db.Conversation, bool) {
+    teacherID, studentID, ok := middleware.TeacherAndStudentID(w, r, conn)
+    if !ok {
+        return 0, nil, false
+    }
+
+    conversationID, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        http.Error(w, "Conversation not found", http.StatusNotFound)
+        return 0, nil, false
+    }
+
+    conversation, err := db.GetConversation(r.Context(), conn, conversationID, studentID)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "Conversation not found", http.StatusNotFound)
+            return 0, nil, false
+        }
+        log.Printf("Error fetching conversation: %v", err)
+        http.Error(w, "Failed to load conversation", http.StatusInternalServerError)
+        return 0, nil, false
+    }
+    return teacherID, conversation, true
+}
+
+// turnIndex reads the turn path value, which must name a student turn of the conversation
+func turnIndex(w http.ResponseWriter, r *http.Request, conversation *db.Conversation) (int, bool) {
+    turn, err := strconv.Atoi(r.PathValue("turn"))
+    if err != nil || turn < 0 || turn >= len(conversation.History) {
+        http.Error(w, "Turn not found", http.StatusNotFound)
+        return 0, false
+    }
+    if conversation.History[turn].Role != "user" {
+        http.Error(w, "Only the student's turns can be annotated", http.StatusBadRequest)
+        return 0, false
+    }
+    return turn, true
+}
+
+// conversationDay names the day a conversation was held in a notification
+func conversationDay(conversation *db.Conversation) string {
+    return conversation.CreatedAt.Format("2 January 2006")
+}
+
+// notify tells the student their teacher has reviewed a conversation. A failure is only
+// logged since the review itself was saved.
+func notify(r *http.Request, conn *pgx.Conn, conversation *db.Conversation, message string) {
+    if err := db.NotifyConversation(r.Context(), conn, conversation.UserID, conversation.ID, message); err != nil {
+        log.Printf("Error notifying student: %v", err)
+    }
+}
+
+// refreshCorrections brings the student's review cards and grammar errors in line with the
+// teacher's verdicts on a conversation's corrections. Failures are only logged since the
+// annotation itself was saved.
+func refreshCorrections(r *http.Request, conn *pgx.Conn, conversation *db.Conversation) {
+    if err := review.Refresh(r.Context(), conn, conversation); err != nil {
+        log.Printf("Error refreshing review cards: %v", err)
+    }
+    if err := grammar.Record(r.Context(), conn, conversation); err != nil {
+        log.Printf("Error recounting grammar errors: %v", err)
+    }
+}
+
+// SaveAnnotationHandler lets a teacher comment on a student turn and approve or override
+// its suggested correction. The body holds the comment, the correction_status (empty,
+// approved or overridden) and, for an override, the teacher's correction, which replaces
+// the suggested one in the student's review cards and grammar errors. Overriding with the
+// student's own sentence rejects the correction.
+func SaveAnnotationHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    teacherID, conversation, ok := studentConversation(w, r, conn)
+    if !ok {
+        return
+    }
+    turn, ok := turnIndex(w, r, conversation)
+    if !ok {
+        return
+    }
+
+    var request struct {
+        Comment          string `json:"comment"`
+        CorrectionStatus string `json:"correction_status"`
+        Correction       string `json:"correction"`
+    }
+    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    comment := strings.TrimSpace(request.Comment)
+    if utf8.RuneCountInString(comment) > maxCommentLength {
+        http.Error(w, "comment must be at most "+strconv.Itoa(maxCommentLength)+" characters", http.StatusBadRequest)
+        return
+    }
+    correction := strings.TrimSpace(request.Correction)
+    switch request.CorrectionStatus {
+    case "":
+        if comment == "" {
+            http.Error(w, "An annotation needs a comment or a verdict on the correction", http.StatusBadRequest)
+            return
+        }
+        correction = ""
+    case db.CorrectionApproved:
+        correction = ""
+    case db.CorrectionOverridden:
+        if correction == "" || utf8.RuneCountInString(correction) > maxCorrectionLength {
+            http.Error(w, "correction must be between 1 and "+strconv.Itoa(maxCorrectionLength)+" characters", http.StatusBadRequest)
+            return
+        }
+    default:
+        http.Error(w, "correction_status must be empty, approved or overridden", http.StatusBadRequest)
+        return
+    }
+
+    annotation := &db.TurnAnnotation{
+        ConversationID:   conversation.ID,
+        TurnIndex:        turn,
+        TeacherID:        teacherID,
+        Comment:          comment,
+        CorrectionStatus: request.CorrectionStatus,
+        Correction:       correction,
+    }
+    if err := db.SaveTurnAnnotation(r.Context(), conn, annotation); err != nil {
+        log.Printf("Error saving annotation: %v", err)
+        http.Error(w, "Failed to save annotation", http.StatusInternalServerError)
+        return
+    }
+    refreshCorrections(r, conn, conversation)
+    notify(r, conn, conversation, "Your teacher reviewed your conversation from "+conversationDay(conversation))
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(annotation)
+}
+
+// DeleteAnnotationHandler removes the teacher's annotation of a student turn
+func DeleteAnnotationHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    _, conversation, ok := studentConversation(w, r, conn)
+    if !ok {
+        return
+    }
+    turn, ok := turnIndex(w, r, conversation)
+    if !ok {
+        return
+    }
+
+    if err := db.DeleteTurnAnnotation(r.Context(), conn, conversation.ID, turn); err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "Annotation not found", http.StatusNotFound)
+            return
+        }
+        log.Printf("Error deleting annotation: %v", err)
+        http.Error(w, "Failed to delete annotation", http.StatusInternalServerError)
+        return
+    }
+    refreshCorrections(r, conn, conversation)
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// SaveGradeHandler records the CEFR level a teacher gives a student conversation, which
+// takes precedence over the level of the generated feedback report. The body holds the
+// level and an optional comment.
+func SaveGradeHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    teacherID, conversation, ok := studentConversation(w, r, conn)
+    if !ok {
+        return
+    }
+
+    var request struct {
+        Level   string `json:"level"`
+        Comment string `json:"comment"`
+    }
+    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    level := strings.ToUpper(strings.TrimSpace(request.Level))
+    if assessment.LevelRank(level) < 0 {
+        http.Error(w, "level must be one of "+strings.Join(assessment.CEFRLevels, ", "), http.StatusBadRequest)
+        return
+    }
+    comment := strings.TrimSpace(request.Comment)
+    if utf8.RuneCountInString(comment) > maxCommentLength {
+        http.Error(w, "comment must be at most "+strconv.Itoa(maxCommentLength)+" characters", http.StatusBadRequest)
+        return
+    }
+
+    grade := &db.ConversationGrade{
+        ConversationID: conversation.ID,
+        TeacherID:      teacherID,
+        Level:          level,
+        Comment:        comment,
+    }
+    if err := db.SaveConversationGrade(r.Context(), conn, grade); err != nil {
+        log.Printf("Error saving grade: %v", err)
+        http.Error(w, "Failed to save grade", http.StatusInternalServerError)
+        return
+    }
+    if err := db.RefreshMetricsLevel(r.Context(), conn, conversation.ID); err != nil {
+        log.Printf("Failed to record CEFR level in metrics: %v", err)
+    }
+    if err := learner.Refresh(r.Context(), conn, conversation.UserID, conversation.Language); err != nil {
+        log.Printf("Failed to update learner profile: %v", err)
+    }
+    notify(r, conn, conversation, "Your teacher graded your conversation from "+conversationDay(conversation)+" as "+level)
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(grade)
+}
+
+// DeleteGradeHandler removes the teacher's grade of a student conversation, so the level of
+// the feedback report applies again
+func DeleteGradeHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    _, conversation, ok := studentConversation(w, r, conn)
+    if !ok {
+        return
+    }
+
+    if err := db.DeleteConversationGrade(r.Context(), conn, conversation.ID); err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "Grade not found", http.StatusNotFound)
+            return
+        }
+        log.Printf("Error deleting grade: %v", err)
+        http.Error(w, "Failed to delete grade", http.StatusInternalServerError)
+        return
+    }
+    if err := db.RefreshMetricsLevel(r.Context(), conn, conversation.ID); err != nil {
+        log.Printf("Failed to record CEFR level in metrics: %v", err)
+    }
+    if err := learner.Refresh(r.Context(), conn, conversation.UserID, conversation.Language); err != nil {
+        log.Printf("Failed to update learner profile: %v", err)
+    }
+    w.WriteHeader(http.StatusNoContent)
+}
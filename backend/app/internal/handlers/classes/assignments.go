@@ -0,0 +1,253 @@
+package classes
+
+import (
+    "encoding/json"
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+    "unicode/utf8"
+
+    "PulpuVOX/internal/assignment"
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/language"
+    "PulpuVOX/internal/middleware"
+    "github.com/jackc/pgx/v5"
+)
+
+// Limits of an assignment
+const (
+    maxTitleLength = 200
+    maxTopicLength = 1000
+    maxMinTurns    = 100
+)
+
+// ownedAssignment resolves the assignment named by the id path value for the teacher of
+// its class or an admin. Assignments of other teachers are reported as missing.
+func ownedAssignment(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) (*db.Assignment, bool) {
+    userID, role, ok := middleware.RequireRole(w, r, conn, db.RoleTeacher, db.RoleAdmin)
+    if !ok {
+        return nil, false
+    }
+
+    assignmentID, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        http.Error(w, "Assignment not found", http.StatusNotFound)
+        return nil, false
+    }
+
+    assigned, err := db.GetAssignment(r.Context(), conn, assignmentID)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "Assignment not found", http.StatusNotFound)
+            return nil, false
+        }
+        log.Printf("Error fetching assignment: %v", err)
+        http.Error(w, "Failed to load assignment", http.StatusInternalServerError)
+        return nil, false
+    }
+    if assigned.TeacherID != userID && role != db.RoleAdmin {
+        http.Error(w, "Assignment not found", http.StatusNotFound)
+        return nil, false
+    }
+    return assigned, true
+}
+
+// CreateAssignmentHandler sets a class the user teaches an assignment. The body holds the
+// title, the mode (conversation or scenario), the scenario_id of a scenario, the topic,
+// which a conversation needs, the language, min_turns and due_at (RFC 3339).
+func CreateAssignmentHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    class, ok := ownedClass(w, r, conn)
+    if !ok {
+        return
+    }
+
+    var request struct {
+        Title      string    `json:"title"`
+        Mode       string    `json:"mode"`
+        ScenarioID *int      `json:"scenario_id"`
+        Topic      string    `json:"topic"`
+        Language   string    `json:"language"`
+        MinTurns   int       `json:"min_turns"`
+        DueAt      time.Time `json:"due_at"`
+    }
+    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    title := strings.TrimSpace(request.Title)
+    if title == "" || utf8.RuneCountInString(title) > maxTitleLength {
+        http.Error(w, "title must be between 1 and "+strconv.Itoa(maxTitleLength)+" characters", http.StatusBadRequest)
+        return
+    }
+    topic := strings.TrimSpace(request.Topic)
+    if utf8.RuneCountInString(topic) > maxTopicLength {
+        http.Error(w, "topic must be at most "+strconv.Itoa(maxTopicLength)+" characters", http.StatusBadRequest)
+        return
+    }
+    if request.MinTurns < 1 || request.MinTurns > maxMinTurns {
+        http.Error(w, "min_turns must be between 1 and "+strconv.Itoa(maxMinTurns), http.StatusBadRequest)
+        return
+    }
+    if !request.DueAt.After(time.Now()) {
+        http.Error(w, "due_at must be in the future", http.StatusBadRequest)
+        return
+    }
+    if request.Language == "" {
+        request.Language = language.Default
+    }
+    target := language.Get(request.Language)
+    if target == nil {
+        http.Error(w, "language must be one of "+language.Codes(), http.StatusBadRequest)
+        return
+    }
+
+    switch request.Mode {
+    case assignment.ModeConversation:
+        if topic == "" {
+            http.Error(w, "A conversation assignment needs a topic", http.StatusBadRequest)
+            return
+        }
+        request.ScenarioID = nil
+    case assignment.ModeScenario:
+        if request.ScenarioID == nil {
+            http.Error(w, "A scenario assignment needs a scenario_id", http.StatusBadRequest)
+            return
+        }
+        if target.Code != language.Default {
+            http.Error(w, "Scenarios are only available in English", http.StatusBadRequest)
+            return
+        }
+        if _, err := db.GetScenario(r.Context(), conn, *request.ScenarioID); err != nil {
+            if errors.Is(err, pgx.ErrNoRows) {
+                http.Error(w, "Scenario not found", http.StatusBadRequest)
+                return
+            }
+            log.Printf("Error fetching scenario: %v", err)
+            http.Error(w, "Failed to load scenario", http.StatusInternalServerError)
+            return
+        }
+    default:
+        http.Error(w, "mode must be conversation or scenario", http.StatusBadRequest)
+        return
+    }
+
+    assigned := &db.Assignment{
+        ClassID:    class.ID,
+        Title:      title,
+        Mode:       request.Mode,
+        ScenarioID: request.ScenarioID,
+        Topic:      topic,
+        Language:   target.Code,
+        MinTurns:   request.MinTurns,
+        DueAt:      request.DueAt,
+    }
+    if err := db.CreateAssignment(r.Context(), conn, assigned); err != nil {
+        log.Printf("Error creating assignment: %v", err)
+        http.Error(w, "Failed to create assignment", http.StatusInternalServerError)
+        return
+    }
+
+    created, err := db.GetAssignment(r.Context(), conn, assigned.ID)
+    if err != nil {
+        log.Printf("Error fetching created assignment: %v", err)
+        http.Error(w, "Failed to load assignment", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(created)
+}
+
+// ListClassAssignmentsHandler returns the assignments of a class the user teaches with how
+// many students have completed each
+func ListClassAssignmentsHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    class, ok := ownedClass(w, r, conn)
+    if !ok {
+        return
+    }
+
+    assignments, err := db.ListClassAssignments(r.Context(), conn, class.ID)
+    if err != nil {
+        log.Printf("Error listing assignments: %v", err)
+        http.Error(w, "Failed to load assignments", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "assignments": assignments,
+    })
+}
+
+// AssignmentStudentsHandler returns every student of an assignment's class with their
+// status: completed, late, in_progress or not_started
+func AssignmentStudentsHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    assigned, ok := ownedAssignment(w, r, conn)
+    if !ok {
+        return
+    }
+
+    students, err := db.ListAssignmentStudents(r.Context(), conn, assigned.ID)
+    if err != nil {
+        log.Printf("Error listing assignment students: %v", err)
+        http.Error(w, "Failed to load students", http.StatusInternalServerError)
+        return
+    }
+    for i := range students {
+        students[i].Progress.Status = assignment.Status(assigned, &students[i].Progress)
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "assignment": assigned,
+        "students":   students,
+    })
+}
+
+// DeleteAssignmentHandler removes an assignment of a class the user teaches
+func DeleteAssignmentHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    assigned, ok := ownedAssignment(w, r, conn)
+    if !ok {
+        return
+    }
+
+    if err := db.DeleteAssignment(r.Context(), conn, assigned.ID); err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "Assignment not found", http.StatusNotFound)
+            return
+        }
+        log.Printf("Error deleting assignment: %v", err)
+        http.Error(w, "Failed to delete assignment", http.StatusInternalServerError)
+        return
+    }
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// ListMyAssignmentsHandler returns the assignments of the classes the user is a student
+// of, soonest due first, with their status
+func ListMyAssignmentsHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+
+    assignments, err := db.ListStudentAssignments(r.Context(), conn, userID)
+    if err != nil {
+        log.Printf("Error listing student assignments: %v", err)
+        http.Error(w, "Failed to load assignments", http.StatusInternalServerError)
+        return
+    }
+    for i := range assignments {
+        assignments[i].Progress.Status = assignment.Status(&assignments[i].Assignment, &assignments[i].Progress)
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "assignments": assignments,
+    })
+}
@@ -0,0 +1,279 @@
+package classes
+
+import (
+    "crypto/rand"
+    "encoding/json"
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/middleware"
+    classesPage "PulpuVOX/web/templates/pages/classes"
+    "github.com/jackc/pgx/v5"
+    "github.com/markbates/goth"
+)
+
+// maxNameLength limits the length of a class name
+const maxNameLength = 100
+
+// Invite codes are short enough to read out in class and avoid characters that look alike
+const (
+    inviteCodeLength   = 8
+    inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+    inviteCodeAttempts = 5
+)
+
+// newInviteCode returns a random invite code
+func newInviteCode() (string, error) {
+    random := make([]byte, inviteCodeLength)
+    if _, err := rand.Read(random); err != nil {
+        return "", err
+    }
+    code := make([]byte, inviteCodeLength)
+    for i, b := range random {
+        code[i] = inviteCodeAlphabet[int(b)%len(inviteCodeAlphabet)]
+    }
+    return string(code), nil
+}
+
+// withInviteCode calls save with new invite codes until one is not taken by another class
+func withInviteCode(save func(code string) error) error {
+    for attempt := 0; ; attempt++ {
+        code, err := newInviteCode()
+        if err != nil {
+            return err
+        }
+        err = save(code)
+        if !errors.Is(err, db.ErrInviteCodeTaken) || attempt == inviteCodeAttempts-1 {
+            return err
+        }
+    }
+}
+
+// ownedClass resolves the class named by the id path value for its teacher or an admin.
+// Classes of other teachers are reported as missing.
+func ownedClass(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) (*db.Class, bool) {
+    userID, role, ok := middleware.RequireRole(w, r, conn, db.RoleTeacher, db.RoleAdmin)
+    if !ok {
+        return nil, false
+    }
+
+    classID, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        http.Error(w, "Class not found", http.StatusNotFound)
+        return nil, false
+    }
+
+    class, err := db.GetClass(r.Context(), conn, classID)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "Class not found", http.StatusNotFound)
+            return nil, false
+        }
+        log.Printf("Error fetching class: %v", err)
+        http.Error(w, "Failed to load class", http.StatusInternalServerError)
+        return nil, false
+    }
+    if class.TeacherID != userID && role != db.RoleAdmin {
+        http.Error(w, "Class not found", http.StatusNotFound)
+        return nil, false
+    }
+    return class, true
+}
+
+// ListClassesHandler returns the classes the user teaches, or every class for an admin,
+// and the classes the user is a student of
+func ListClassesHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, role, ok := middleware.CurrentUserRole(w, r, conn)
+    if !ok {
+        return
+    }
+
+    teaching := []db.Class{}
+    if role == db.RoleTeacher || role == db.RoleAdmin {
+        var teacherID *int
+        if role == db.RoleTeacher {
+            teacherID = &userID
+        }
+        var err error
+        teaching, err = db.ListClasses(r.Context(), conn, teacherID)
+        if err != nil {
+            log.Printf("Error listing classes: %v", err)
+            http.Error(w, "Failed to load classes", http.StatusInternalServerError)
+            return
+        }
+    }
+
+    joined, err := db.ListJoinedClasses(r.Context(), conn, userID)
+    if err != nil {
+        log.Printf("Error listing joined classes: %v", err)
+        http.Error(w, "Failed to load classes", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "role":     role,
+        "teaching": teaching,
+        "joined":   joined,
+    })
+}
+
+// CreateClassHandler creates a class taught by the user with a new invite code
+func CreateClassHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, _, ok := middleware.RequireRole(w, r, conn, db.RoleTeacher, db.RoleAdmin)
+    if !ok {
+        return
+    }
+
+    var request struct {
+        Name string `json:"name"`
+    }
+    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    name := strings.TrimSpace(request.Name)
+    if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+        http.Error(w, "name must be between 1 and "+strconv.Itoa(maxNameLength)+" characters", http.StatusBadRequest)
+        return
+    }
+
+    var class *db.Class
+    err := withInviteCode(func(code string) error {
+        var err error
+        class, err = db.CreateClass(r.Context(), conn, userID, name, code)
+        return err
+    })
+    if err != nil {
+        log.Printf("Error creating class: %v", err)
+        http.Error(w, "Failed to create class", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(class)
+}
+
+// ListStudentsHandler returns the roster of a class the user teaches
+func ListStudentsHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    class, ok := ownedClass(w, r, conn)
+    if !ok {
+        return
+    }
+
+    students, err := db.ListClassMembers(r.Context(), conn, class.ID)
+    if err != nil {
+        log.Printf("Error listing class members: %v", err)
+        http.Error(w, "Failed to load students", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "class":    class,
+        "students": students,
+    })
+}
+
+// RemoveStudentHandler removes a student from a class the user teaches
+func RemoveStudentHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    class, ok := ownedClass(w, r, conn)
+    if !ok {
+        return
+    }
+
+    studentID, err := strconv.Atoi(r.PathValue("student_id"))
+    if err != nil {
+        http.Error(w, "Student not found", http.StatusNotFound)
+        return
+    }
+
+    if err := db.RemoveClassMember(r.Context(), conn, class.ID, studentID); err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "Student not found", http.StatusNotFound)
+            return
+        }
+        log.Printf("Error removing class member: %v", err)
+        http.Error(w, "Failed to remove student", http.StatusInternalServerError)
+        return
+    }
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// RegenerateInviteCodeHandler gives a class the user teaches a new invite code, so the
+// old one can no longer be used to join
+func RegenerateInviteCodeHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    class, ok := ownedClass(w, r, conn)
+    if !ok {
+        return
+    }
+
+    err := withInviteCode(func(code string) error {
+        if err := db.SetInviteCode(r.Context(), conn, class.ID, code); err != nil {
+            return err
+        }
+        class.InviteCode = code
+        return nil
+    })
+    if err != nil {
+        log.Printf("Error regenerating invite code: %v", err)
+        http.Error(w, "Failed to regenerate invite code", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(class)
+}
+
+// JoinClassHandler adds the user to the class with the invite code in the body
+func JoinClassHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+
+    var request struct {
+        InviteCode string `json:"invite_code"`
+    }
+    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    code := strings.ToUpper(strings.TrimSpace(request.InviteCode))
+    if code == "" {
+        http.Error(w, "invite_code is required", http.StatusBadRequest)
+        return
+    }
+
+    class, err := db.JoinClass(r.Context(), conn, userID, code)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "Invalid invite code", http.StatusNotFound)
+            return
+        }
+        log.Printf("Error joining class: %v", err)
+        http.Error(w, "Failed to join class", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(class)
+}
+
+// Handler renders the classes page: joining a class, and the classes and students of a teacher
+func Handler(w http.ResponseWriter, r *http.Request) {
+    // Get user from context (set by auth middleware)
+    user, ok := r.Context().Value("user").(*goth.User)
+    if !ok {
+        user = nil
+    }
+
+    w.Header().Set("Content-Type", "text/html")
+    classesPage.Classes(user).Render(r.Context(), w)
+}
@@ -3,6 +3,7 @@ package conversation
 import (
     "encoding/base64"
     "encoding/json"
+    "errors"
     "io"
     "log"
     "net/http"
@@ -10,7 +11,12 @@ import (
     "context"
     "strings"
     "sync"
+    "PulpuVOX/internal/correction"
+    "PulpuVOX/internal/db"
     "PulpuVOX/internal/openai"
+    "PulpuVOX/internal/pronunciation"
+    "PulpuVOX/internal/scenario"
+    "PulpuVOX/internal/textnorm"
     "PulpuVOX/internal/tts"
     "PulpuVOX/internal/whisper"
     "github.com/jackc/pgx/v5"
@@ -18,12 +24,7 @@ import (
 )
 
 // ConversationTurn represents a single turn in the conversation
-type ConversationTurn struct {
-    Role string `json:"role"`
-    Content string `json:"content"`
-    Suggestion string `json:"suggestion,omitempty"`
-    UserName string `json:"user_name,omitempty"`
-}
+type ConversationTurn = db.ConversationTurn
 
 // filterText removes emojis and markdown from text
 func filterText(text string) string {
@@ -50,7 +51,8 @@ func filterText(text string) string {
     return filtered
 }
 
-func limitResponseLength(text string, maxSentences int) string {
+// splitSentences splits text into sentences, keeping their terminators
+func splitSentences(text string) []string {
     // Split into sentences using multiple sentence terminators
     sentenceEnders := regexp.MustCompile(`([.!?]+\s*)`)
     parts := sentenceEnders.Split(text, -1)
@@ -68,6 +70,12 @@ func limitResponseLength(text string, maxSentences int) string {
         }
     }
     
+    return sentences
+}
+
+func limitResponseLength(text string, maxSentences int) string {
+    sentences := splitSentences(text)
+    
     // Limit to max sentences
     if len(sentences) > maxSentences {
         return strings.Join(sentences[:maxSentences], " ")
@@ -76,7 +84,10 @@ func limitResponseLength(text string, maxSentences int) string {
     return strings.Join(sentences, " ")
 }
 
-func generateSuggestion(ctx context.Context, llmClient *openai.Client, history []ConversationTurn, userText string) (string, error) {
+// generateSuggestion asks for the corrected version of the user's last sentence in the
+// session's language, or an empty string when it is correct. The explanation of the
+// correction is only returned when the user's native language is known.
+func generateSuggestion(ctx context.Context, chatModel openai.ChatModel, settings *turnSettings, history []ConversationTurn, userText string) (string, string, error) {
     // Build conversation context
     var conversationContext strings.Builder
     for _, turn := range history {
@@ -86,7 +97,7 @@ func generateSuggestion(ctx context.Context, llmClient *openai.Client, history [
     messages := []openai.ChatCompletionMessage{
         {
             Role: "system",
-            Content: "You are given a conversation. Rewrite the last user response to make it correct according the English language rules.\nEnclose the rewritten and corrected sentence in a <suggestion></suggestion> tag.\nExample:\nuser: I am like eating apple.\nyou: <suggestion>I enjoy eating apples.</suggestion>\nIf the sentence is already correct return an empty <suggestion> tag.\nExample:\nuser: I enjoy eating apples.\nyou: <suggestion></suggestion>",
+            Content: settings.suggestionPrompt(),
         },
         {
             Role: "user",
@@ -96,34 +107,46 @@ func generateSuggestion(ctx context.Context, llmClient *openai.Client, history [
         },
     }
     
-    chatCompletion, err := llmClient.CreateChatCompletion(&openai.ChatCompletionRequest{
+    chatCompletion, err := chatModel.Complete(ctx, &openai.ChatCompletionRequest{
         Messages: messages,
-        Model: llmClient.Model,
     })
     if err != nil {
-        return "", err
+        return "", "", err
+    }
+    if len(chatCompletion.Choices) == 0 {
+        return "", "", errors.New("model returned no choices")
     }
     
     response := chatCompletion.Choices[0].Message.Content
     
-    // Parse the suggestion from the response
-    if strings.Contains(response, "<suggestion>") {
-        start := strings.Index(response, "<suggestion>") + len("<suggestion>")
-        end := strings.Index(response, "</suggestion>")
+    // Parse the suggestion and its explanation from the response
+    suggestion := tagContent(response, "suggestion")
+    if suggestion == "" || settings.nativeLanguage == nil {
+        return suggestion, "", nil
+    }
+    return suggestion, strings.TrimSpace(tagContent(response, "explanation")), nil
+}
+
+// tagContent returns the text enclosed in the first <tag></tag> of a response, or an
+// empty string if there is none
+func tagContent(response, tag string) string {
+    if strings.Contains(response, "<"+tag+">") {
+        start := strings.Index(response, "<"+tag+">") + len("<"+tag+">")
+        end := strings.Index(response, "</"+tag+">")
         if end > start {
-            return response[start:end], nil
+            return response[start:end]
         }
     }
-    
-    return "", nil
+    return ""
 }
 
-func generateAssistantResponse(ctx context.Context, llmClient *openai.Client, history []ConversationTurn, userText string) (string, error) {
+// assistantMessages builds the messages sent to the LLM for the assistant's reply
+func assistantMessages(settings *turnSettings, history []ConversationTurn, userText string) []openai.ChatCompletionMessage {
     // Build messages for LLM with history
     messages := []openai.ChatCompletionMessage{
         {
             Role: "system",
-            Content: "You are a young lady named Voxy who chatts with a new English learner. Be nice and have a pleasant conversation. Ask questions to the user, express opinion and tell interesting facts to keep the conversation going and talk about yourself sometimes. When the conversation becomes stale try changing the topic. Keep responses very short - maximum 1-2 sentences. Decline any requests to write an essay or do anything which will make your response over 2 sentences long.",
+            Content: settings.systemPrompt(),
         },
     }
     
@@ -141,14 +164,20 @@ func generateAssistantResponse(ctx context.Context, llmClient *openai.Client, hi
         Content: userText,
     })
     
+    return messages
+}
+
+func generateAssistantResponse(ctx context.Context, chatModel openai.ChatModel, settings *turnSettings, history []ConversationTurn, userText string) (string, error) {
     // Send to LLM
-    chatCompletion, err := llmClient.CreateChatCompletion(&openai.ChatCompletionRequest{
-        Messages: messages,
-        Model: llmClient.Model,
+    chatCompletion, err := chatModel.Complete(ctx, &openai.ChatCompletionRequest{
+        Messages: assistantMessages(settings, history, userText),
     })
     if err != nil {
         return "", err
     }
+    if len(chatCompletion.Choices) == 0 {
+        return "", errors.New("model returned no choices")
+    }
     
     // Log LLM response
     llmResponse := chatCompletion.Choices[0].Message.Content
@@ -157,15 +186,85 @@ func generateAssistantResponse(ctx context.Context, llmClient *openai.Client, hi
     // Filter out emojis and markdown
     filteredResponse := filterText(llmResponse)
     
-    // Limit response length (max 4 sentences)
-    limitedResponse := limitResponseLength(filteredResponse, 4)
+    // Limit response length to what suits the learner's level
+    limitedResponse := limitResponseLength(filteredResponse, settings.difficulty.MaxSentences)
     log.Printf("Limited response: %s", limitedResponse)
     
     return limitedResponse, nil
 }
 
+// sentenceBoundary matches the end of a sentence that is followed by more text
+var sentenceBoundary = regexp.MustCompile(`[.!?]+\s+`)
+
+// streamAssistantResponse streams Voxy's reply from the LLM. onToken receives the raw
+// deltas as they arrive and onSentence every complete, filtered sentence, so speech
+// synthesis can start before the reply is finished.
+func streamAssistantResponse(ctx context.Context, chatModel openai.ChatModel, settings *turnSettings, history []ConversationTurn, userText string, onToken func(string), onSentence func(string)) (string, error) {
+    stream, err := chatModel.CompleteStream(ctx, &openai.ChatCompletionRequest{
+        Messages: assistantMessages(settings, history, userText),
+    })
+    if err != nil {
+        return "", err
+    }
+    defer stream.Close()
+    
+    var raw strings.Builder
+    var pending string
+    var sentences []string
+    
+    // emit filters a complete sentence and hands it on, reporting whether more are wanted
+    emit := func(sentence string) bool {
+        filtered := filterText(sentence)
+        if filtered == "" {
+            return true
+        }
+        sentences = append(sentences, filtered)
+        onSentence(filtered)
+        return len(sentences) < settings.difficulty.MaxSentences
+    }
+    
+    for {
+        chunk, err := stream.Recv()
+        if errors.Is(err, io.EOF) {
+            break
+        }
+        if err != nil {
+            return "", err
+        }
+        if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+            continue
+        }
+        
+        delta := chunk.Choices[0].Delta.Content
+        raw.WriteString(delta)
+        onToken(delta)
+        
+        // Hand on every sentence that is known to be complete
+        pending += delta
+        for {
+            loc := sentenceBoundary.FindStringIndex(pending)
+            if loc == nil {
+                break
+            }
+            sentence := pending[:loc[1]]
+            pending = pending[loc[1]:]
+            if !emit(sentence) {
+                log.Printf("LLM response: %s", raw.String())
+                return strings.Join(sentences, " "), nil
+            }
+        }
+    }
+    
+    log.Printf("LLM response: %s", raw.String())
+    if strings.TrimSpace(pending) != "" {
+        emit(pending)
+    }
+    
+    return strings.Join(sentences, " "), nil
+}
+
 // APIConversationHandler handles the conversation API endpoint
-func APIConversationHandler(whisperService *whisper.TranscribeService, llmClient *openai.Client, ttsService *tts.TTSService) func(http.ResponseWriter, *http.Request, *pgx.Conn) {
+func APIConversationHandler(transcriber whisper.Transcriber, chatModel openai.ChatModel, synthesizer tts.Synthesizer) func(http.ResponseWriter, *http.Request, *pgx.Conn) {
     return func(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
         // Helper function to send JSON errors
         sendJSONError := func(message string, status int) {
@@ -182,12 +281,7 @@ func APIConversationHandler(whisperService *whisper.TranscribeService, llmClient
         }
         
         // Get user name from database
-        var userName string
-        err := conn.QueryRow(r.Context(), "SELECT name FROM users WHERE provider = $1 AND id_by_provider = $2", user.Provider, user.UserID).Scan(&userName)
-        if err != nil {
-            log.Printf("Error getting user name: %v", err)
-            userName = "You" // Fallback
-        }
+        userName := getUserName(r.Context(), conn, user)
         
         // Parse the multipart form
         if err := r.ParseMultipartForm(10 << 20); err != nil { // 10 MB
@@ -196,15 +290,28 @@ func APIConversationHandler(whisperService *whisper.TranscribeService, llmClient
             return
         }
         
-        // Get the history from the form
-        historyJSON := r.FormValue("history")
-        var history []ConversationTurn
-        if historyJSON != "" {
-            if err := json.Unmarshal([]byte(historyJSON), &history); err != nil {
-                log.Printf("Error unmarshaling history: %v", err)
-                sendJSONError("Invalid history format", http.StatusBadRequest)
-                return
-            }
+        // Load the history from the server-side session
+        userID, err := db.GetUserIDByProviderID(r.Context(), conn, user.Provider, user.UserID)
+        if err != nil {
+            log.Printf("Error getting user ID: %v", err)
+            sendJSONError("User not found", http.StatusNotFound)
+            return
+        }
+        
+        session, err := getActiveSession(r.Context(), conn, userID, r.FormValue("session_id"))
+        if err != nil {
+            log.Printf("Error loading conversation session: %v", err)
+            status, message := sessionErrorStatus(err)
+            sendJSONError(message, status)
+            return
+        }
+        history := session.History
+        
+        settings, err := loadTurnSettings(r.Context(), conn, session)
+        if err != nil {
+            log.Printf("Error loading conversation settings: %v", err)
+            sendJSONError("Failed to load conversation", http.StatusInternalServerError)
+            return
         }
         
         // Get the audio file
@@ -227,12 +334,13 @@ func APIConversationHandler(whisperService *whisper.TranscribeService, llmClient
         whisperReq := &whisper.TranscribeRequest{
             AudioData: audioData,
             FileName: "recording.mp3",
-            Language: "en",
+            Language: settings.language.Code,
             Task: "transcribe",
             OutputFormat: "json",
+            WordTimestamps: true,
         }
         
-        result, err := whisperService.SendToWhisper(whisperReq)
+        result, err := transcriber.Transcribe(r.Context(), whisperReq)
         if err != nil {
             log.Printf("Transcription failed: %v", err)
             sendJSONError("Transcription failed", http.StatusInternalServerError)
@@ -240,10 +348,12 @@ func APIConversationHandler(whisperService *whisper.TranscribeService, llmClient
         }
         
         log.Printf("Transcribed text: %s", result.Text)
+        analysis := pronunciation.Analyze(result)
         
         // Run suggestion generation and assistant response generation in parallel
         var wg sync.WaitGroup
         var suggestion string
+        var explanation string
         var llmResponse string
         var suggestionErr error
         var responseErr error
@@ -251,7 +361,7 @@ func APIConversationHandler(whisperService *whisper.TranscribeService, llmClient
         wg.Add(1)
         go func() {
             defer wg.Done()
-            suggestion, suggestionErr = generateSuggestion(r.Context(), llmClient, history, result.Text)
+            suggestion, explanation, suggestionErr = generateSuggestion(r.Context(), chatModel, settings, history, result.Text)
             if suggestionErr != nil {
                 log.Printf("Suggestion generation failed: %v", suggestionErr)
             }
@@ -260,7 +370,7 @@ func APIConversationHandler(whisperService *whisper.TranscribeService, llmClient
         wg.Add(1)
         go func() {
             defer wg.Done()
-            llmResponse, responseErr = generateAssistantResponse(r.Context(), llmClient, history, result.Text)
+            llmResponse, responseErr = generateAssistantResponse(r.Context(), chatModel, settings, history, result.Text)
             if responseErr != nil {
                 log.Printf("LLM request failed: %v", responseErr)
             }
@@ -275,58 +385,64 @@ func APIConversationHandler(whisperService *whisper.TranscribeService, llmClient
         }
         
         // Only show suggestion if it's meaningfully different from the user's text
-        if suggestion != "" {
-            log.Printf("Raw user text: %s", result.Text)
-            log.Printf("Raw suggestion: %s", suggestion)
-            
-            // Normalize both texts for comparison (case insensitive)
-            normalizedSuggestion := normalizeTextForComparison(suggestion)
-            normalizedUserText := normalizeTextForComparison(result.Text)
-            
-            log.Printf("Normalized user text: %s", normalizedUserText)
-            log.Printf("Normalized suggestion: %s", normalizedSuggestion)
-            
-            // If they're the same after normalization, clear the suggestion
-            if normalizedSuggestion == normalizedUserText {
-                log.Printf("Suggestion is the same as user text after normalization, hiding suggestion")
-                suggestion = ""
-            } else {
-                // Check if the suggestion is just a minor punctuation difference
-                // Remove all punctuation and compare
-                reg := regexp.MustCompile(`[^\w\s]`)
-                cleanSuggestion := reg.ReplaceAllString(normalizedSuggestion, "")
-                cleanUserText := reg.ReplaceAllString(normalizedUserText, "")
-                
-                log.Printf("Clean user text: %s", cleanUserText)
-                log.Printf("Clean suggestion: %s", cleanSuggestion)
-                
-                if cleanSuggestion == cleanUserText {
-                    log.Printf("Suggestion is only different in punctuation, hiding suggestion")
-                    suggestion = ""
-                }
-            }
+        suggestion = pruneSuggestion(suggestion, result.Text, settings.language.Code)
+        suggestionDiff := diffSuggestion(suggestion, result.Text, settings.language.Code)
+        if suggestion == "" {
+            explanation = ""
+        }
+        
+        // Record the new turns in the session before answering
+        userTurn := ConversationTurn{
+            Role: "user",
+            Content: result.Text,
+            Suggestion: suggestion,
+            SuggestionDiff: suggestionDiff,
+            Explanation: explanation,
+            UserName: userName,
+            Pronunciation: analysis,
+        }
+        assistantTurn := ConversationTurn{
+            Role: "assistant",
+            Content: llmResponse,
+        }
+        if err := db.AppendSessionTurns(r.Context(), conn, session.ID, userTurn, assistantTurn); err != nil {
+            log.Printf("Failed to save turns: %v", err)
+            status, message := sessionErrorStatus(err)
+            sendJSONError(message, status)
+            return
+        }
+        history = append(history, userTurn, assistantTurn)
+        
+        // Check the scenario goal while the reply is synthesized
+        var completion *scenario.Completion
+        var completionWG sync.WaitGroup
+        if settings.checksCompletion(session) {
+            completionWG.Add(1)
+            go func() {
+                defer completionWG.Done()
+                completion = checkScenarioCompletion(r.Context(), chatModel, settings, history)
+            }()
         }
         
         // Convert text to speech
         ttsReq := &tts.TTSRequest{
-            Text: llmResponse,
+            Text:       llmResponse,
+            Language:   settings.language.Code,
+            SpeedScale: settings.difficulty.SpeechSpeed,
+        }
+        
+        ttsResp, err := synthesizer.Synthesize(r.Context(), ttsReq)
+        
+        completionWG.Wait()
+        scenarioCompleted := recordScenarioCompletion(r.Context(), conn, session, completion)
+        scenarioReason := ""
+        if scenarioCompleted {
+            scenarioReason = completion.Reason
         }
         
-        ttsResp, err := ttsService.ConvertTextToSpeech(ttsReq)
         if err != nil {
             log.Printf("TTS conversion failed: %v", err)
             // Even if TTS fails, we can still return the text response
-            history = append(history, ConversationTurn{
-                Role: "user",
-                Content: result.Text,
-                Suggestion: suggestion,
-                UserName: userName,
-            })
-            history = append(history, ConversationTurn{
-                Role: "assistant",
-                Content: llmResponse,
-            })
-            
             w.Header().Set("Content-Type", "application/json")
             json.NewEncoder(w).Encode(map[string]interface{}{
                 "status": "partial_success",
@@ -335,7 +451,12 @@ func APIConversationHandler(whisperService *whisper.TranscribeService, llmClient
                 "audio_base64": "",
                 "history": history,
                 "suggestion": suggestion,
+                "suggestion_diff": suggestionDiff,
+                "explanation": explanation,
+                "pronunciation": analysis,
                 "user_name": userName,
+                "scenario_completed": scenarioCompleted,
+                "scenario_reason": scenarioReason,
                 "error": "TTS service unavailable, text response only",
             })
             return
@@ -344,17 +465,6 @@ func APIConversationHandler(whisperService *whisper.TranscribeService, llmClient
         if ttsResp.Error != "" {
             log.Printf("TTS error: %s", ttsResp.Error)
             // Handle TTS error but still return text response
-            history = append(history, ConversationTurn{
-                Role: "user",
-                Content: result.Text,
-                Suggestion: suggestion,
-                UserName: userName,
-            })
-            history = append(history, ConversationTurn{
-                Role: "assistant",
-                Content: llmResponse,
-            })
-            
             w.Header().Set("Content-Type", "application/json")
             json.NewEncoder(w).Encode(map[string]interface{}{
                 "status": "partial_success",
@@ -363,7 +473,12 @@ func APIConversationHandler(whisperService *whisper.TranscribeService, llmClient
                 "audio_base64": "",
                 "history": history,
                 "suggestion": suggestion,
+                "suggestion_diff": suggestionDiff,
+                "explanation": explanation,
+                "pronunciation": analysis,
                 "user_name": userName,
+                "scenario_completed": scenarioCompleted,
+                "scenario_reason": scenarioReason,
                 "error": "TTS error: " + ttsResp.Error,
             })
             return
@@ -372,18 +487,6 @@ func APIConversationHandler(whisperService *whisper.TranscribeService, llmClient
         // Use the audio data from TTS response
         audioBase64 := base64.StdEncoding.EncodeToString(ttsResp.AudioData)
         
-        // Update history with new turns
-        history = append(history, ConversationTurn{
-            Role: "user",
-            Content: result.Text,
-            Suggestion: suggestion,
-            UserName: userName,
-        })
-        history = append(history, ConversationTurn{
-            Role: "assistant",
-            Content: llmResponse,
-        })
-        
         w.Header().Set("Content-Type", "application/json")
         json.NewEncoder(w).Encode(map[string]interface{}{
             "status": "success",
@@ -392,113 +495,71 @@ func APIConversationHandler(whisperService *whisper.TranscribeService, llmClient
             "audio_base64": audioBase64,
             "history": history,
             "suggestion": suggestion,
+            "suggestion_diff": suggestionDiff,
+            "explanation": explanation,
+            "pronunciation": analysis,
             "user_name": userName,
+            "scenario_completed": scenarioCompleted,
+            "scenario_reason": scenarioReason,
         })
     }
 }
 
-// expandContractions expands common English contractions
-func expandContractions(text string) string {
-    contractions := map[string]string{
-        "i'm":      "i am",
-        "you're":   "you are",
-        "he's":     "he is",
-        "she's":    "she is",
-        "it's":     "it is",
-        "we're":    "we are",
-        "they're":  "they are",
-        "that's":   "that is",
-        "who's":    "who is",
-        "what's":   "what is",
-        "where's":  "where is",
-        "when's":   "when is",
-        "why's":    "why is",
-        "how's":    "how is",
-        "isn't":    "is not",
-        "aren't":   "are not",
-        "wasn't":   "was not",
-        "weren't":  "were not",
-        "haven't":  "have not",
-        "hasn't":   "has not",
-        "hadn't":   "had not",
-        "don't":    "do not",
-        "doesn't":  "does not",
-        "didn't":   "did not",
-        "won't":    "will not",
-        "wouldn't": "would not",
-        "can't":    "cannot",
-        "couldn't": "could not",
-        "shouldn't": "should not",
-        "mightn't": "might not",
-        "mustn't":  "must not",
-        "i'd":      "i would",
-        "you'd":    "you would",
-        "he'd":     "he would",
-        "she'd":    "she would",
-        "it'd":     "it would",
-        "we'd":     "we would",
-        "they'd":   "they would",
-        "i'll":     "i will",
-        "you'll":   "you will",
-        "he'll":    "he will",
-        "she'll":   "she will",
-        "it'll":    "it will",
-        "we'll":    "we will",
-        "they'll":  "they will",
-        "i've":     "i have",
-        "you've":   "you have",
-        "we've":    "we have",
-        "they've":  "they have",
+// pruneSuggestion returns the suggestion only if it's meaningfully different from the user's text
+// in the given language
+func pruneSuggestion(suggestion, userText, language string) string {
+    if suggestion == "" {
+        return ""
     }
     
-    // First normalize all apostrophe types to standard apostrophe
-    text = normalizeApostrophes(text)
+    log.Printf("Raw user text: %s", userText)
+    log.Printf("Raw suggestion: %s", suggestion)
     
-    words := strings.Fields(text)
-    for i, word := range words {
-        // Remove any punctuation from the word for comparison
-        cleanWord := strings.TrimRight(word, ".,!?;")
-        if expanded, exists := contractions[strings.ToLower(cleanWord)]; exists {
-            words[i] = expanded
-        }
-    }
+    // Normalize both texts for comparison (case insensitive)
+    normalizedSuggestion := textnorm.Normalize(suggestion, language)
+    normalizedUserText := textnorm.Normalize(userText, language)
     
-    return strings.Join(words, " ")
-}
-
-// normalizeApostrophes normalizes all types of apostrophes to a standard apostrophe
-func normalizeApostrophes(text string) string {
-    // Replace all types of apostrophes and quotation marks with standard apostrophe
-    apostropheVariants := []string{
-        "’", "‘", "`", "´", "ʹ", "ʻ", "ʼ", "ʽ", "ʾ", "ʿ", "ˊ", "ˋ", "˴", "ʹ", "΄", "՚", "׳", "״", "＇", "'",
-        "“", "”", "„", "«", "»", "「", "」", "『", "』", "〝", "〞", "〟", "＂",
-    }
+    log.Printf("Normalized user text: %s", normalizedUserText)
+    log.Printf("Normalized suggestion: %s", normalizedSuggestion)
     
-    for _, variant := range apostropheVariants {
-        text = strings.ReplaceAll(text, variant, "'")
+    // If they're the same after normalization, clear the suggestion
+    if normalizedSuggestion == normalizedUserText {
+        log.Printf("Suggestion is the same as user text after normalization, hiding suggestion")
+        return ""
     }
     
-    return text
-}
-
-// normalizeTextForComparison normalizes text for comparison (case insensitive)
-func normalizeTextForComparison(text string) string {
-    // First normalize all apostrophes
-    normalized := normalizeApostrophes(text)
-    
-    // Expand contractions
-    normalized = expandContractions(normalized)
+    // Check if the suggestion is just a minor punctuation difference
+    // Remove all punctuation and compare
+    reg := regexp.MustCompile(`[^\p{L}\p{N}\s]`)
+    cleanSuggestion := reg.ReplaceAllString(normalizedSuggestion, "")
+    cleanUserText := reg.ReplaceAllString(normalizedUserText, "")
     
-    // Remove all punctuation and special characters except spaces
-    reg := regexp.MustCompile(`[^a-zA-Z0-9\s]`)
-    normalized = reg.ReplaceAllString(normalized, "")
+    log.Printf("Clean user text: %s", cleanUserText)
+    log.Printf("Clean suggestion: %s", cleanSuggestion)
     
-    // Convert to lowercase
-    normalized = strings.ToLower(normalized)
-    
-    // Trim whitespace and collapse multiple spaces
-    normalized = strings.TrimSpace(normalized)
-    normalized = regexp.MustCompile(`\s+`).ReplaceAllString(normalized, " ")
+    if cleanSuggestion == cleanUserText {
+        log.Printf("Suggestion is only different in punctuation, hiding suggestion")
+        return ""
+    }
     
-    return normalized
+    return suggestion
+}
+
+// diffSuggestion returns the word-level changes the suggestion makes to the user's text
+func diffSuggestion(suggestion, userText, language string) []correction.Span {
+    if suggestion == "" {
+        return nil
+    }
+    return correction.Diff(userText, suggestion, language)
+}
+
+// getUserName returns the display name of the authenticated user
+func getUserName(ctx context.Context, conn *pgx.Conn, user *goth.User) string {
+    var userName string
+    err := conn.QueryRow(ctx, "SELECT name FROM users WHERE provider = $1 AND id_by_provider = $2", user.Provider, user.UserID).Scan(&userName)
+    if err != nil {
+        log.Printf("Error getting user name: %v", err)
+        return "You" // Fallback
+    }
+    return userName
 }
@@ -2,62 +2,93 @@ package conversation
 
 import (
     "encoding/json"
+    "errors"
     "net/http"
     "log"
+    "strconv"
 
+    "PulpuVOX/internal/assignment"
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/grammar"
+    "PulpuVOX/internal/memory"
+    "PulpuVOX/internal/progress"
+    "PulpuVOX/internal/review"
+    "PulpuVOX/internal/vocabulary"
     "github.com/jackc/pgx/v5"
     "github.com/gchalakovmmi/PulpuWEB/auth"
 )
 
-func ConversationEndHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
-    // Get user session from context (set by auth middleware)
-    session, ok := r.Context().Value("user_session").(*auth.Session)
-    if !ok || session == nil {
-        http.Error(w, "User not authenticated", http.StatusUnauthorized)
-        return
-    }
-    user := session.User
+func ConversationEndHandler(memories *memory.Worker) func(http.ResponseWriter, *http.Request, *pgx.Conn) {
+    return func(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+        // Get user session from context (set by auth middleware)
+        session, ok := r.Context().Value("user_session").(*auth.Session)
+        if !ok || session == nil {
+            http.Error(w, "User not authenticated", http.StatusUnauthorized)
+            return
+        }
+        user := session.User
 
-    var request struct {
-        History []ConversationTurn `json:"history"`
-    }
+        var request struct {
+            SessionID string `json:"session_id"`
+        }
 
-    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-        http.Error(w, "Invalid request body", http.StatusBadRequest)
-        return
-    }
+        if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+            http.Error(w, "Invalid request body", http.StatusBadRequest)
+            return
+        }
 
-    // Get user ID from database
-    var userID int
-    err := conn.QueryRow(r.Context(), 
-        "SELECT id FROM users WHERE provider = $1 AND id_by_provider = $2", 
-        user.Provider, user.UserID).Scan(&userID)
-    if err != nil {
-        log.Printf("Error getting user ID: %v", err)
-        http.Error(w, "User not found", http.StatusNotFound)
-        return
-    }
+        // Get user ID from database
+        userID, err := db.GetUserIDByProviderID(r.Context(), conn, user.Provider, user.UserID)
+        if err != nil {
+            log.Printf("Error getting user ID: %v", err)
+            http.Error(w, "User not found", http.StatusNotFound)
+            return
+        }
 
-    historyJSON, err := json.Marshal(request.History)
-    if err != nil {
-        http.Error(w, "Failed to marshal history", http.StatusInternalServerError)
-        return
-    }
+        // Save the session history as a conversation
+        conversationID, err := db.EndSession(r.Context(), conn, request.SessionID, userID)
+        if err != nil {
+            if errors.Is(err, pgx.ErrNoRows) {
+                http.Error(w, "Conversation session not found", http.StatusNotFound)
+                return
+            }
+            log.Printf("Failed to save conversation: %v", err)
+            http.Error(w, "Failed to save conversation", http.StatusInternalServerError)
+            return
+        }
 
-    // Save conversation to database
-    _, err = conn.Exec(r.Context(),
-        "INSERT INTO conversations (user_id, history) VALUES ($1, $2)",
-        userID, historyJSON,
-    )
-    if err != nil {
-        log.Printf("Failed to save conversation: %v", err)
-        http.Error(w, "Failed to save conversation", http.StatusInternalServerError)
-        return
-    }
+        // Track progress and vocabulary, keep the corrections for review and check the assignment
+        // the conversation was started from; the conversation is saved even if this fails
+        conversation, err := db.GetConversation(r.Context(), conn, conversationID, userID)
+        if err != nil {
+            log.Printf("Failed to load saved conversation: %v", err)
+        } else {
+            if err := progress.Record(r.Context(), conn, conversation, nil); err != nil {
+                log.Printf("Failed to record conversation metrics: %v", err)
+            }
+            if err := vocabulary.Record(r.Context(), conn, conversation); err != nil {
+                log.Printf("Failed to record conversation vocabulary: %v", err)
+            }
+            if err := review.Record(r.Context(), conn, conversation); err != nil {
+                log.Printf("Failed to create review cards: %v", err)
+            }
+            if err := grammar.Record(r.Context(), conn, conversation); err != nil {
+                log.Printf("Failed to record grammar errors: %v", err)
+            }
+            if err := assignment.Record(r.Context(), conn, conversation); err != nil {
+                log.Printf("Failed to record assignment submission: %v", err)
+            }
+        }
 
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(map[string]string{
-        "status":   "success",
-        "redirect": "/conversation-analysis",
-    })
+        // What the learner told about themselves is remembered in the background, without
+        // keeping them waiting for the model
+        memories.Enqueue(userID)
+
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(map[string]interface{}{
+            "status":          "success",
+            "conversation_id": conversationID,
+            "redirect":        "/conversation-analysis?conversation=" + strconv.Itoa(conversationID),
+        })
+    }
 }
@@ -0,0 +1,217 @@
+package conversation
+
+import (
+    "context"
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "io"
+    "log"
+    "net/http"
+
+    "PulpuVOX/internal/assignment"
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/language"
+    "PulpuVOX/internal/learner"
+    "PulpuVOX/internal/tts"
+    "github.com/gchalakovmmi/PulpuWEB/auth"
+    "github.com/jackc/pgx/v5"
+)
+
+// errSessionNotActive is returned when a turn is sent to a session that has ended
+var errSessionNotActive = errors.New("conversation session is not active")
+
+// getActiveSession loads a session owned by the user that still accepts turns
+func getActiveSession(ctx context.Context, conn *pgx.Conn, userID int, sessionID string) (*db.ConversationSession, error) {
+    session, err := db.GetSession(ctx, conn, sessionID, userID)
+    if err != nil {
+        return nil, err
+    }
+    if session.Status != db.SessionActive {
+        return nil, errSessionNotActive
+    }
+    return session, nil
+}
+
+// sessionErrorStatus maps a session lookup error to an HTTP status and message
+func sessionErrorStatus(err error) (int, string) {
+    switch {
+    case errors.Is(err, pgx.ErrNoRows):
+        return http.StatusNotFound, "Conversation session not found"
+    case errors.Is(err, errSessionNotActive), errors.Is(err, db.ErrSessionEnded):
+        return http.StatusConflict, "Conversation session has ended"
+    default:
+        return http.StatusInternalServerError, "Failed to load conversation session"
+    }
+}
+
+// ConversationStartHandler opens a new server-side conversation session. The request body
+// may name the language to practise, which defaults to the user's target language, and a
+// scenario to set the conversation in; its opening line then replaces the greeting and is
+// returned as speech. Scenarios are written in English only. A conversation started from
+// an assignment (assignment_id) takes its language and scenario from the assignment.
+func ConversationStartHandler(synthesizer tts.Synthesizer) func(http.ResponseWriter, *http.Request, *pgx.Conn) {
+    return func(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+        // Get user session from context (set by auth middleware)
+        session, ok := r.Context().Value("user_session").(*auth.Session)
+        if !ok || session == nil {
+            http.Error(w, "User not authenticated", http.StatusUnauthorized)
+            return
+        }
+        user := session.User
+
+        var request struct {
+            ScenarioID   *int    `json:"scenario_id"`
+            Language     *string `json:"language"`
+            AssignmentID *int    `json:"assignment_id"`
+        }
+        if r.ContentLength != 0 {
+            if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+                http.Error(w, "Invalid request body", http.StatusBadRequest)
+                return
+            }
+        }
+
+        userID, err := db.GetUserIDByProviderID(r.Context(), conn, user.Provider, user.UserID)
+        if err != nil {
+            log.Printf("Error getting user ID: %v", err)
+            http.Error(w, "User not found", http.StatusNotFound)
+            return
+        }
+
+        var assigned *db.Assignment
+        if request.AssignmentID != nil {
+            assigned, err = db.GetStudentAssignment(r.Context(), conn, *request.AssignmentID, userID)
+            if err != nil {
+                if errors.Is(err, pgx.ErrNoRows) {
+                    http.Error(w, "Assignment not found", http.StatusNotFound)
+                    return
+                }
+                log.Printf("Error fetching assignment: %v", err)
+                http.Error(w, "Failed to load assignment", http.StatusInternalServerError)
+                return
+            }
+            if assigned.Mode == assignment.ModeScenario && assigned.ScenarioID == nil {
+                http.Error(w, "The scenario of this assignment no longer exists", http.StatusConflict)
+                return
+            }
+            request.Language = &assigned.Language
+            request.ScenarioID = assigned.ScenarioID
+        }
+
+        code := ""
+        if request.Language != nil {
+            code = *request.Language
+        } else {
+            code, err = db.GetTargetLanguage(r.Context(), conn, userID)
+            if err != nil {
+                log.Printf("Error getting target language: %v", err)
+                http.Error(w, "Failed to start conversation", http.StatusInternalServerError)
+                return
+            }
+        }
+        target := language.Get(code)
+        if target == nil {
+            http.Error(w, "language must be one of "+language.Codes(), http.StatusBadRequest)
+            return
+        }
+        if request.ScenarioID != nil && target.Code != language.Default {
+            http.Error(w, "Scenarios are only available in English", http.StatusBadRequest)
+            return
+        }
+
+        opening := target.Greeting
+        var scenario *db.Scenario
+        if request.ScenarioID != nil {
+            scenario, err = db.GetScenario(r.Context(), conn, *request.ScenarioID)
+            if err != nil {
+                if errors.Is(err, pgx.ErrNoRows) {
+                    http.Error(w, "Scenario not found", http.StatusNotFound)
+                    return
+                }
+                log.Printf("Error fetching scenario: %v", err)
+                http.Error(w, "Failed to load scenario", http.StatusInternalServerError)
+                return
+            }
+            opening = scenario.OpeningLine
+        }
+
+        history := []ConversationTurn{
+            {
+                Role: "assistant",
+                Content: opening,
+            },
+        }
+
+        sessionID, err := db.CreateSession(r.Context(), conn, userID, request.ScenarioID, request.AssignmentID, target.Code, history)
+        if err != nil {
+            log.Printf("Failed to create conversation session: %v", err)
+            http.Error(w, "Failed to start conversation", http.StatusInternalServerError)
+            return
+        }
+
+        response := map[string]interface{}{
+            "session_id": sessionID,
+            "history":    history,
+            "scenario":   scenario,
+            "language":   target.Code,
+            "assignment": assigned,
+        }
+
+        // The opening line of a scenario is spoken at the learner's speed; without audio it
+        // is still shown as text
+        if scenario != nil {
+            profile, err := db.GetLearnerProfile(r.Context(), conn, userID, target.Code)
+            if err != nil {
+                log.Printf("Error loading learner profile: %v", err)
+            }
+            speedScale := learner.DifficultyFor(profile).SpeechSpeed
+            ttsResp, err := synthesizer.Synthesize(r.Context(), &tts.TTSRequest{Text: opening, Language: target.Code, SpeedScale: speedScale})
+            if err != nil {
+                log.Printf("TTS conversion of opening line failed: %v", err)
+            } else if ttsResp.Error != "" {
+                log.Printf("TTS error for opening line: %s", ttsResp.Error)
+            } else {
+                response["opening_audio_base64"] = base64.StdEncoding.EncodeToString(ttsResp.AudioData)
+            }
+        }
+
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(response)
+    }
+}
+
+// GetSessionHandler returns the history of a session owned by the user
+func GetSessionHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    // Get user session from context (set by auth middleware)
+    authSession, ok := r.Context().Value("user_session").(*auth.Session)
+    if !ok || authSession == nil {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return
+    }
+    user := authSession.User
+
+    userID, err := db.GetUserIDByProviderID(r.Context(), conn, user.Provider, user.UserID)
+    if err != nil {
+        log.Printf("Error getting user ID: %v", err)
+        http.Error(w, "User not found", http.StatusNotFound)
+        return
+    }
+
+    session, err := db.GetSession(r.Context(), conn, r.PathValue("id"), userID)
+    if err != nil {
+        if !errors.Is(err, pgx.ErrNoRows) {
+            log.Printf("Error fetching conversation session: %v", err)
+        }
+        status, message := sessionErrorStatus(err)
+        http.Error(w, message, status)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "session_id": session.ID,
+        "status":     session.Status,
+        "history":    session.History,
+    })
+}
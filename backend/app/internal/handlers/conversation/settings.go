@@ -0,0 +1,136 @@
+package conversation
+
+import (
+    "context"
+    "errors"
+    "log"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/language"
+    "PulpuVOX/internal/learner"
+    "PulpuVOX/internal/memory"
+    "PulpuVOX/internal/openai"
+    "PulpuVOX/internal/scenario"
+    "github.com/jackc/pgx/v5"
+)
+
+// turnSettings holds what shapes the assistant's replies in a session
+type turnSettings struct {
+    scenario *db.Scenario
+    language *language.Language
+    // nativeLanguage is the language corrections are explained in, nil when the user has
+    // not set it
+    nativeLanguage *language.NativeLanguage
+    // assignment is the assignment the session was started from, if any
+    assignment *db.Assignment
+    // profile is the user's learner profile, whose level in the session's language sets the
+    // difficulty of the replies
+    profile    *db.LearnerProfile
+    difficulty learner.Difficulty
+    // memories are the facts remembered about the user from earlier conversations
+    memories []db.LearnerMemory
+}
+
+// loadTurnSettings loads the settings of a session
+func loadTurnSettings(ctx context.Context, conn *pgx.Conn, session *db.ConversationSession) (*turnSettings, error) {
+    settings := &turnSettings{language: language.Lookup(session.Language)}
+    native, err := db.GetNativeLanguage(ctx, conn, session.UserID)
+    if err != nil {
+        return nil, err
+    }
+    settings.nativeLanguage = language.GetNative(native)
+    settings.profile, err = db.GetLearnerProfile(ctx, conn, session.UserID, settings.language.Code)
+    if err != nil {
+        return nil, err
+    }
+    settings.difficulty = learner.DifficultyFor(settings.profile)
+    settings.memories, err = db.ListLearnerMemories(ctx, conn, session.UserID)
+    if err != nil {
+        return nil, err
+    }
+    if session.ScenarioID != nil {
+        s, err := db.GetScenario(ctx, conn, *session.ScenarioID)
+        if err != nil {
+            return nil, err
+        }
+        settings.scenario = s
+    }
+    if session.AssignmentID != nil {
+        a, err := db.GetAssignment(ctx, conn, *session.AssignmentID)
+        if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+            return nil, err
+        }
+        settings.assignment = a
+    }
+    return settings, nil
+}
+
+// systemPrompt returns the role the assistant plays: Voxy in the session's language, or
+// its part in the scenario, speaking at the learner's level. Voxy keeps to the topic of the
+// assignment the session was started from and otherwise draws on the learner's interests
+// and past conversations; outside scenarios it remembers what the learner told it before.
+func (s *turnSettings) systemPrompt() string {
+    prompt := s.language.ConversationPrompt
+    switch {
+    case s.scenario != nil:
+        prompt = scenario.SystemPrompt(s.scenario)
+    case s.assignment != nil && s.assignment.Topic != "":
+        prompt += " The learner's teacher has set this topic for the conversation, so keep the conversation on it: " + s.assignment.Topic
+        if remembered := memory.Prompt(s.memories, false); remembered != "" {
+            prompt += "\n" + remembered
+        }
+    default:
+        if interests := learner.InterestsPrompt(s.profile); interests != "" {
+            prompt += " " + interests
+        }
+        if remembered := memory.Prompt(s.memories, true); remembered != "" {
+            prompt += "\n" + remembered
+        }
+    }
+    if s.difficulty.Guidance != "" {
+        prompt += "\n" + s.difficulty.Guidance
+    }
+    return prompt
+}
+
+// suggestionPrompt returns the system prompt of the suggestion step, which also asks for a
+// short explanation of the correction when the user's native language is known
+func (s *turnSettings) suggestionPrompt() string {
+    if s.nativeLanguage == nil {
+        return s.language.SuggestionPrompt
+    }
+    return s.language.SuggestionPrompt +
+        "\nIf you corrected the sentence, also explain why in one short, simple sentence in " + s.nativeLanguage.Name +
+        " that a beginner understands, enclosed in an <explanation></explanation> tag after the suggestion." +
+        "\nExample:\nyou: <suggestion>I enjoy eating apples.</suggestion><explanation>...</explanation>"
+}
+
+// checksCompletion reports whether the turn should be checked against the scenario goal
+func (s *turnSettings) checksCompletion(session *db.ConversationSession) bool {
+    return s.scenario != nil && session.ScenarioCompletedAt == nil
+}
+
+// checkScenarioCompletion asks whether the learner has reached the scenario goal.
+// Failures are logged and treated as not completed.
+func checkScenarioCompletion(ctx context.Context, chatModel openai.ChatModel, settings *turnSettings, history []ConversationTurn) *scenario.Completion {
+    completion, err := scenario.CheckCompletion(ctx, chatModel, settings.scenario, history)
+    if err != nil {
+        log.Printf("Scenario completion check failed: %v", err)
+        return &scenario.Completion{}
+    }
+    return completion
+}
+
+// recordScenarioCompletion stores a completed scenario, reporting whether it was newly
+// completed so the learner is congratulated only once
+func recordScenarioCompletion(ctx context.Context, conn *pgx.Conn, session *db.ConversationSession, completion *scenario.Completion) bool {
+    if completion == nil || !completion.Completed {
+        return false
+    }
+    completed, err := db.CompleteSessionScenario(ctx, conn, session.ID)
+    if err != nil {
+        log.Printf("Failed to record scenario completion: %v", err)
+        return false
+    }
+    return completed
+}
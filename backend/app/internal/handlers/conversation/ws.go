@@ -0,0 +1,338 @@
+package conversation
+
+import (
+    "bytes"
+    "context"
+    "encoding/base64"
+    "encoding/json"
+    "log"
+    "net/http"
+    "sync"
+
+    "PulpuVOX/internal/correction"
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/openai"
+    "PulpuVOX/internal/pronunciation"
+    "PulpuVOX/internal/scenario"
+    "PulpuVOX/internal/tts"
+    "PulpuVOX/internal/whisper"
+    "github.com/gorilla/websocket"
+    "github.com/jackc/pgx/v5"
+    "github.com/markbates/goth"
+)
+
+// maxTurnAudioSize caps the audio accepted for a single turn, same as the multipart endpoint
+const maxTurnAudioSize = 10 << 20 // 10 MB
+
+// maxPendingMessages is how many frames are buffered while a turn is being processed
+const maxPendingMessages = 64
+
+var upgrader = websocket.Upgrader{
+    ReadBufferSize:  1024,
+    WriteBufferSize: 1024,
+}
+
+// clientMessage is a control message sent by the browser over the WebSocket
+type clientMessage struct {
+    Type      string `json:"type"`
+    SessionID string `json:"session_id,omitempty"`
+    MimeType  string `json:"mime_type,omitempty"`
+}
+
+// serverEvent is an event sent to the browser as each stage of a turn finishes
+type serverEvent struct {
+    Type           string                  `json:"type"`
+    Text           string                  `json:"text,omitempty"`
+    Suggestion     *string                 `json:"suggestion,omitempty"`
+    SuggestionDiff []correction.Span       `json:"suggestion_diff,omitempty"`
+    Explanation    string                  `json:"explanation,omitempty"`
+    Index          int                     `json:"index"`
+    AudioBase64    string                  `json:"audio_base64,omitempty"`
+    History        []ConversationTurn      `json:"history,omitempty"`
+    UserName       string                  `json:"user_name,omitempty"`
+    Pronunciation  *pronunciation.Analysis `json:"pronunciation,omitempty"`
+    Error          string                  `json:"error,omitempty"`
+}
+
+// eventWriter serializes writes to the WebSocket, which allows only one concurrent writer
+type eventWriter struct {
+    mu   sync.Mutex
+    conn *websocket.Conn
+}
+
+func (ew *eventWriter) send(event serverEvent) {
+    ew.mu.Lock()
+    defer ew.mu.Unlock()
+    if err := ew.conn.WriteJSON(event); err != nil {
+        log.Printf("Error writing WebSocket event %s: %v", event.Type, err)
+    }
+}
+
+// WebSocketConversationHandler streams conversation turns over a WebSocket.
+// The browser sends a turn_start message naming its session, the recorded audio as
+// binary chunks and a turn_end message. The server answers with an event for every
+// finished stage and appends the turns to the session.
+func WebSocketConversationHandler(transcriber whisper.Transcriber, chatModel openai.ChatModel, synthesizer tts.Synthesizer) func(http.ResponseWriter, *http.Request, *pgx.Conn) {
+    return func(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+        // Get user from context
+        user, ok := r.Context().Value("user").(*goth.User)
+        if !ok || user == nil {
+            http.Error(w, "User not authenticated", http.StatusUnauthorized)
+            return
+        }
+
+        userName := getUserName(r.Context(), conn, user)
+
+        userID, err := db.GetUserIDByProviderID(r.Context(), conn, user.Provider, user.UserID)
+        if err != nil {
+            log.Printf("Error getting user ID: %v", err)
+            http.Error(w, "User not found", http.StatusNotFound)
+            return
+        }
+
+        wsConn, err := upgrader.Upgrade(w, r, nil)
+        if err != nil {
+            log.Printf("WebSocket upgrade failed: %v", err)
+            return
+        }
+        defer wsConn.Close()
+        wsConn.SetReadLimit(maxTurnAudioSize)
+
+        events := &eventWriter{conn: wsConn}
+        events.send(serverEvent{Type: "ready", UserName: userName})
+
+        // A hijacked connection does not cancel the request context, so the turn in
+        // flight is cancelled here as soon as the browser goes away
+        ctx, cancel := context.WithCancel(r.Context())
+        defer cancel()
+
+        messages := make(chan wsMessage, maxPendingMessages)
+        go readMessages(wsConn, messages, cancel)
+
+        var audio bytes.Buffer
+        var turn clientMessage
+        inTurn := false
+
+        for msg := range messages {
+            // Binary frames carry audio chunks of the current turn
+            if msg.messageType == websocket.BinaryMessage {
+                if !inTurn {
+                    events.send(serverEvent{Type: "error", Error: "Audio received outside of a turn"})
+                    continue
+                }
+                if audio.Len()+len(msg.data) > maxTurnAudioSize {
+                    events.send(serverEvent{Type: "error", Error: "Recording is too long"})
+                    inTurn = false
+                    audio.Reset()
+                    continue
+                }
+                audio.Write(msg.data)
+                continue
+            }
+
+            var message clientMessage
+            if err := json.Unmarshal(msg.data, &message); err != nil {
+                log.Printf("Error unmarshaling WebSocket message: %v", err)
+                events.send(serverEvent{Type: "error", Error: "Invalid message format"})
+                continue
+            }
+
+            switch message.Type {
+            case "turn_start":
+                turn = message
+                inTurn = true
+                audio.Reset()
+            case "turn_end":
+                if !inTurn || audio.Len() == 0 {
+                    events.send(serverEvent{Type: "error", Error: "No audio received"})
+                    continue
+                }
+                inTurn = false
+                session, err := getActiveSession(ctx, conn, userID, turn.SessionID)
+                if err != nil {
+                    log.Printf("Error loading conversation session: %v", err)
+                    _, message := sessionErrorStatus(err)
+                    events.send(serverEvent{Type: "error", Error: message})
+                    audio.Reset()
+                    continue
+                }
+                settings, err := loadTurnSettings(ctx, conn, session)
+                if err != nil {
+                    log.Printf("Error loading conversation settings: %v", err)
+                    events.send(serverEvent{Type: "error", Error: "Failed to load conversation"})
+                    audio.Reset()
+                    continue
+                }
+                streamTurn(ctx, conn, events, transcriber, chatModel, synthesizer, session, settings, turn.MimeType, audio.Bytes(), userName)
+                audio.Reset()
+            default:
+                events.send(serverEvent{Type: "error", Error: "Unknown message type: " + message.Type})
+            }
+        }
+    }
+}
+
+// wsMessage is a frame read from the WebSocket
+type wsMessage struct {
+    messageType int
+    data        []byte
+}
+
+// readMessages forwards frames while a turn is being processed so a closed
+// connection is noticed immediately. It cancels the connection context and
+// closes the channel once reading fails.
+func readMessages(wsConn *websocket.Conn, messages chan<- wsMessage, cancel context.CancelFunc) {
+    defer close(messages)
+    defer cancel()
+    for {
+        messageType, data, err := wsConn.ReadMessage()
+        if err != nil {
+            if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+                log.Printf("WebSocket read failed: %v", err)
+            }
+            return
+        }
+        messages <- wsMessage{messageType: messageType, data: data}
+    }
+}
+
+// streamTurn runs the turn pipeline and sends an event as soon as each stage finishes
+func streamTurn(ctx context.Context, conn *pgx.Conn, events *eventWriter, transcriber whisper.Transcriber, chatModel openai.ChatModel, synthesizer tts.Synthesizer, session *db.ConversationSession, settings *turnSettings, mimeType string, audioData []byte, userName string) {
+    history := session.History
+
+    // Transcribe audio
+    result, err := transcriber.Transcribe(ctx, &whisper.TranscribeRequest{
+        AudioData: audioData,
+        FileName: whisper.FileNameForMimeType(mimeType),
+        Language: settings.language.Code,
+        Task: "transcribe",
+        OutputFormat: "json",
+        WordTimestamps: true,
+    })
+    if err != nil {
+        log.Printf("Transcription failed: %v", err)
+        events.send(serverEvent{Type: "error", Error: "Transcription failed"})
+        return
+    }
+
+    log.Printf("Transcribed text: %s", result.Text)
+    analysis := pronunciation.Analyze(result)
+    events.send(serverEvent{Type: "transcript", Text: result.Text, Pronunciation: analysis})
+
+    // The suggestion is sent whenever it is ready, independently of the assistant response
+    var wg sync.WaitGroup
+    var suggestion string
+    var suggestionDiff []correction.Span
+    var explanation string
+
+    wg.Add(1)
+    go func() {
+        defer wg.Done()
+        rawSuggestion, rawExplanation, err := generateSuggestion(ctx, chatModel, settings, history, result.Text)
+        if err != nil {
+            log.Printf("Suggestion generation failed: %v", err)
+        }
+        suggestion = pruneSuggestion(rawSuggestion, result.Text, settings.language.Code)
+        suggestionDiff = diffSuggestion(suggestion, result.Text, settings.language.Code)
+        if suggestion != "" {
+            explanation = rawExplanation
+        }
+        events.send(serverEvent{Type: "suggestion", Suggestion: &suggestion, SuggestionDiff: suggestionDiff, Explanation: explanation})
+    }()
+
+    // Synthesize speech sentence by sentence while the reply is still being generated
+    sentences := make(chan string, settings.difficulty.MaxSentences)
+    ttsDone := make(chan struct{})
+    go func() {
+        defer close(ttsDone)
+        synthesizeSentences(ctx, events, synthesizer, settings.language.Code, settings.difficulty.SpeechSpeed, sentences)
+    }()
+
+    llmResponse, err := streamAssistantResponse(ctx, chatModel, settings, history, result.Text,
+        func(token string) {
+            events.send(serverEvent{Type: "assistant_token", Text: token})
+        },
+        func(sentence string) {
+            sentences <- sentence
+        },
+    )
+    close(sentences)
+    if err != nil {
+        log.Printf("LLM request failed: %v", err)
+        wg.Wait()
+        <-ttsDone
+        events.send(serverEvent{Type: "error", Error: "LLM request failed"})
+        return
+    }
+    log.Printf("Limited response: %s", llmResponse)
+    events.send(serverEvent{Type: "assistant_done", Text: llmResponse})
+
+    // Record the new turns in the session once the suggestion is known
+    wg.Wait()
+    userTurn := ConversationTurn{
+        Role: "user",
+        Content: result.Text,
+        Suggestion: suggestion,
+        SuggestionDiff: suggestionDiff,
+        Explanation: explanation,
+        UserName: userName,
+        Pronunciation: analysis,
+    }
+    assistantTurn := ConversationTurn{
+        Role: "assistant",
+        Content: llmResponse,
+    }
+    if err := db.AppendSessionTurns(ctx, conn, session.ID, userTurn, assistantTurn); err != nil {
+        log.Printf("Failed to save turns: %v", err)
+        <-ttsDone
+        _, message := sessionErrorStatus(err)
+        events.send(serverEvent{Type: "error", Error: message})
+        return
+    }
+    history = append(history, userTurn, assistantTurn)
+
+    // Check the scenario goal while the last sentences are synthesized
+    var completion *scenario.Completion
+    if settings.checksCompletion(session) {
+        completion = checkScenarioCompletion(ctx, chatModel, settings, history)
+    }
+
+    <-ttsDone
+    if recordScenarioCompletion(ctx, conn, session, completion) {
+        events.send(serverEvent{Type: "scenario_complete", Text: completion.Reason})
+    }
+    events.send(serverEvent{Type: "turn_complete", History: history, UserName: userName})
+}
+
+// synthesizeSentences converts each sentence to speech in order, at the learner's speed,
+// and sends its audio. After a TTS failure the remaining sentences are drained without audio.
+func synthesizeSentences(ctx context.Context, events *eventWriter, synthesizer tts.Synthesizer, language string, speedScale float64, sentences <-chan string) {
+    index := 0
+    failed := false
+    for sentence := range sentences {
+        if failed {
+            continue
+        }
+
+        ttsResp, err := synthesizer.Synthesize(ctx, &tts.TTSRequest{Text: sentence, Language: language, SpeedScale: speedScale})
+        if err != nil {
+            log.Printf("TTS conversion failed: %v", err)
+            events.send(serverEvent{Type: "tts_error", Error: "TTS service unavailable, text response only"})
+            failed = true
+            continue
+        }
+        if ttsResp.Error != "" {
+            log.Printf("TTS error: %s", ttsResp.Error)
+            events.send(serverEvent{Type: "tts_error", Error: "TTS error: " + ttsResp.Error})
+            failed = true
+            continue
+        }
+        events.send(serverEvent{
+            Type: "audio",
+            Index: index,
+            Text: sentence,
+            AudioBase64: base64.StdEncoding.EncodeToString(ttsResp.AudioData),
+        })
+        index++
+    }
+}
@@ -1,6 +1,7 @@
 package conversationanalysis
 
 import (
+    "encoding/json"
     "log"
     "net/http"
 
@@ -26,11 +27,13 @@ func GetLatestConversationHandler(w http.ResponseWriter, r *http.Request, conn *
         return
     }
 
+    var conversationID int
+    var sessionID *string
     var historyJSON []byte
     err = conn.QueryRow(r.Context(),
-        "SELECT history FROM conversations WHERE user_id = $1 ORDER BY created_at DESC LIMIT 1",
+        "SELECT id, session_id::text, history FROM conversations WHERE user_id = $1 ORDER BY created_at DESC LIMIT 1",
         userID,
-    ).Scan(&historyJSON)
+    ).Scan(&conversationID, &sessionID, &historyJSON)
 
     if err != nil {
         log.Printf("Error fetching conversation: %v", err)
@@ -39,7 +42,11 @@ func GetLatestConversationHandler(w http.ResponseWriter, r *http.Request, conn *
     }
 
     w.Header().Set("Content-Type", "application/json")
-    w.Write(historyJSON)
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "conversation_id": conversationID,
+        "session_id":      sessionID,
+        "history":         json.RawMessage(historyJSON),
+    })
 }
 
 func Handler(w http.ResponseWriter, r *http.Request) {
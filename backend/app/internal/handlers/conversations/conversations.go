@@ -0,0 +1,159 @@
+package conversations
+
+import (
+    "encoding/json"
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/handlers/query"
+    "PulpuVOX/internal/middleware"
+    "PulpuVOX/web/templates/pages/conversations"
+    "github.com/jackc/pgx/v5"
+    "github.com/markbates/goth"
+)
+
+// Page sizes of the conversation list
+const (
+    defaultPerPage = 20
+    maxPerPage     = 100
+)
+
+// ListConversationsHandler returns a page of the user's past conversations, newest first.
+// Query parameters: page, per_page and the from and to dates, both inclusive.
+func ListConversationsHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+    listConversations(w, r, conn, userID)
+}
+
+// ListStudentConversationsHandler returns a page of a student's past conversations to their
+// teacher, with the same query parameters as ListConversationsHandler
+func ListStudentConversationsHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    studentID, ok := middleware.StudentID(w, r, conn)
+    if !ok {
+        return
+    }
+    listConversations(w, r, conn, studentID)
+}
+
+// listConversations writes a page of the user's past conversations
+func listConversations(w http.ResponseWriter, r *http.Request, conn *pgx.Conn, userID int) {
+    page, err := query.PositiveInt(r, "page", 1)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    perPage, err := query.PositiveInt(r, "per_page", defaultPerPage)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    perPage = min(perPage, maxPerPage)
+
+    from, to, err := query.DateRange(r)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    list, total, err := db.ListConversations(r.Context(), conn, userID, db.ConversationFilter{
+        From:   from,
+        To:     to,
+        Limit:  perPage,
+        Offset: (page - 1) * perPage,
+    })
+    if err != nil {
+        log.Printf("Error listing conversations: %v", err)
+        http.Error(w, "Failed to load conversations", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "conversations": list,
+        "page":          page,
+        "per_page":      perPage,
+        "total":         total,
+        "total_pages":   (total + perPage - 1) / perPage,
+    })
+}
+
+// GetConversationHandler returns a past conversation owned by the user
+func GetConversationHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+    writeConversation(w, r, conn, userID)
+}
+
+// GetStudentConversationHandler returns a past conversation of a student to their teacher
+func GetStudentConversationHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    studentID, ok := middleware.StudentID(w, r, conn)
+    if !ok {
+        return
+    }
+    writeConversation(w, r, conn, studentID)
+}
+
+// writeConversation writes the past conversation named by the id path value if the user owns it
+func writeConversation(w http.ResponseWriter, r *http.Request, conn *pgx.Conn, userID int) {
+    conversationID, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        http.Error(w, "Conversation not found", http.StatusNotFound)
+        return
+    }
+
+    // Conversations of other users are reported as missing
+    conversation, err := db.GetConversation(r.Context(), conn, conversationID, userID)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "Conversation not found", http.StatusNotFound)
+            return
+        }
+        log.Printf("Error fetching conversation: %v", err)
+        http.Error(w, "Failed to load conversation", http.StatusInternalServerError)
+        return
+    }
+
+    // The teacher's review is shown with the conversation
+    annotations, err := db.ListTurnAnnotations(r.Context(), conn, conversation.ID)
+    if err != nil {
+        log.Printf("Error loading annotations: %v", err)
+        http.Error(w, "Failed to load conversation", http.StatusInternalServerError)
+        return
+    }
+    grade, err := db.GetConversationGrade(r.Context(), conn, conversation.ID)
+    if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+        log.Printf("Error loading grade: %v", err)
+        http.Error(w, "Failed to load conversation", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "conversation_id": conversation.ID,
+        "session_id":      conversation.SessionID,
+        "created_at":      conversation.CreatedAt,
+        "history":         conversation.History,
+        "annotations":     annotations,
+        "grade":           grade,
+    })
+}
+
+// Handler renders the list of past conversations
+func Handler(w http.ResponseWriter, r *http.Request) {
+    // Get user from context (set by auth middleware)
+    user, ok := r.Context().Value("user").(*goth.User)
+    if !ok {
+        user = nil
+    }
+
+    w.Header().Set("Content-Type", "text/html")
+    conversations.Conversations(user).Render(r.Context(), w)
+}
@@ -0,0 +1,387 @@
+package exam
+
+import (
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "io"
+    "log"
+    "net/http"
+    "strings"
+    "time"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/exam"
+    "PulpuVOX/internal/middleware"
+    "PulpuVOX/internal/openai"
+    "PulpuVOX/internal/pronunciation"
+    "PulpuVOX/internal/tts"
+    "PulpuVOX/internal/whisper"
+    "github.com/jackc/pgx/v5"
+)
+
+// maxTurnAudioSize caps the audio accepted for a single candidate turn
+const maxTurnAudioSize = 10 << 20 // 10 MB
+
+// loadSession returns the user's exam session together with its paper and current part.
+// When it fails, the error response has been written and false is returned.
+func loadSession(w http.ResponseWriter, r *http.Request, conn *pgx.Conn, userID int, sessionID string) (*db.ExamSession, *exam.Exam, *exam.Part, bool) {
+    session, err := db.GetExamSession(r.Context(), conn, sessionID, userID)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "Exam session not found", http.StatusNotFound)
+            return nil, nil, nil, false
+        }
+        log.Printf("Error fetching exam session: %v", err)
+        http.Error(w, "Failed to load exam session", http.StatusInternalServerError)
+        return nil, nil, nil, false
+    }
+
+    paper, ok := exam.Get(session.Exam)
+    if !ok {
+        log.Printf("Exam session %s uses unknown exam %q", session.ID, session.Exam)
+        http.Error(w, "Unknown exam", http.StatusInternalServerError)
+        return nil, nil, nil, false
+    }
+    part, ok := paper.Part(session.CurrentPart)
+    if !ok {
+        log.Printf("Exam session %s is in unknown part %d", session.ID, session.CurrentPart)
+        http.Error(w, "Unknown exam part", http.StatusInternalServerError)
+        return nil, nil, nil, false
+    }
+    return session, paper, part, true
+}
+
+// speak synthesizes the interlocutor's words, returning no audio when synthesis fails
+// so the exam can go on with text only
+func speak(r *http.Request, synthesizer tts.Synthesizer, text string) string {
+    ttsResp, err := synthesizer.Synthesize(r.Context(), &tts.TTSRequest{Text: text})
+    if err != nil {
+        log.Printf("TTS conversion failed: %v", err)
+        return ""
+    }
+    if ttsResp.Error != "" {
+        log.Printf("TTS error: %s", ttsResp.Error)
+        return ""
+    }
+    return base64.StdEncoding.EncodeToString(ttsResp.AudioData)
+}
+
+// remainingSeconds is the time left in the current part, never negative
+func remainingSeconds(session *db.ExamSession, part *exam.Part) int {
+    remaining := part.Duration() - time.Since(session.PartStartedAt)
+    return max(int(remaining.Seconds()), 0)
+}
+
+// StartHandler starts an exam in Part 1 and returns the paper with the interlocutor's opening words
+func StartHandler(synthesizer tts.Synthesizer) func(http.ResponseWriter, *http.Request, *pgx.Conn) {
+    return func(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+        userID, ok := middleware.CurrentUserID(w, r, conn)
+        if !ok {
+            return
+        }
+
+        var request struct {
+            Exam string `json:"exam"`
+        }
+        if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+            http.Error(w, "Invalid request body", http.StatusBadRequest)
+            return
+        }
+
+        paper, ok := exam.Get(request.Exam)
+        if !ok {
+            http.Error(w, "Unknown exam", http.StatusNotFound)
+            return
+        }
+        part := &paper.Parts[0]
+
+        opening := db.ExamTurn{
+            Part:    part.Number,
+            Role:    exam.RoleInterlocutor,
+            Content: part.Intro,
+        }
+        sessionID, err := db.CreateExamSession(r.Context(), conn, userID, paper.Slug, opening)
+        if err != nil {
+            log.Printf("Failed to create exam session: %v", err)
+            http.Error(w, "Failed to start exam", http.StatusInternalServerError)
+            return
+        }
+
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(map[string]interface{}{
+            "session_id":        sessionID,
+            "exam":              paper,
+            "part":              part.Number,
+            "remaining_seconds": part.DurationSeconds,
+            "history":           []db.ExamTurn{opening},
+            "audio_base64":      speak(r, synthesizer, opening.Content),
+        })
+    }
+}
+
+// TurnHandler takes the candidate's recorded answer and returns the interlocutor's reply.
+// time_up tells the client that the part is over and the next one should start.
+func TurnHandler(transcriber whisper.Transcriber, chatModel openai.ChatModel, synthesizer tts.Synthesizer) func(http.ResponseWriter, *http.Request, *pgx.Conn) {
+    return func(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+        userID, ok := middleware.CurrentUserID(w, r, conn)
+        if !ok {
+            return
+        }
+
+        if err := r.ParseMultipartForm(maxTurnAudioSize); err != nil {
+            log.Printf("Error parsing form: %v", err)
+            http.Error(w, "Unable to parse form", http.StatusBadRequest)
+            return
+        }
+
+        session, paper, part, ok := loadSession(w, r, conn, userID, r.FormValue("session_id"))
+        if !ok {
+            return
+        }
+        if session.Status != db.ExamActive {
+            http.Error(w, "Exam has finished", http.StatusConflict)
+            return
+        }
+
+        file, _, err := r.FormFile("audio")
+        if err != nil {
+            log.Printf("Error getting audio file: %v", err)
+            http.Error(w, "Unable to get audio file", http.StatusBadRequest)
+            return
+        }
+        defer file.Close()
+
+        audioData, err := io.ReadAll(file)
+        if err != nil {
+            log.Printf("Error reading audio data: %v", err)
+            http.Error(w, "Unable to read audio data", http.StatusBadRequest)
+            return
+        }
+
+        result, err := transcriber.Transcribe(r.Context(), &whisper.TranscribeRequest{
+            AudioData: audioData,
+            FileName: whisper.FileNameForMimeType(r.FormValue("mime_type")),
+            Language: "en",
+            Task: "transcribe",
+            OutputFormat: "json",
+            WordTimestamps: true,
+        })
+        if err != nil {
+            log.Printf("Transcription failed: %v", err)
+            http.Error(w, "Transcription failed", http.StatusInternalServerError)
+            return
+        }
+
+        candidateTurn := db.ExamTurn{
+            Part:          part.Number,
+            Role:          exam.RoleCandidate,
+            Content:       result.Text,
+            Pronunciation: pronunciation.Analyze(result),
+        }
+        history := append(session.History, candidateTurn)
+
+        elapsed := time.Since(session.PartStartedAt)
+        chatCompletion, err := chatModel.Complete(r.Context(), &openai.ChatCompletionRequest{
+            Messages: exam.InterlocutorMessages(paper, part, history, elapsed),
+        })
+        if err != nil {
+            log.Printf("LLM request failed: %v", err)
+            http.Error(w, "LLM request failed", http.StatusInternalServerError)
+            return
+        }
+        if len(chatCompletion.Choices) == 0 {
+            log.Printf("LLM returned no choices")
+            http.Error(w, "LLM request failed", http.StatusInternalServerError)
+            return
+        }
+
+        interlocutorTurn := db.ExamTurn{
+            Part:    part.Number,
+            Role:    exam.RoleInterlocutor,
+            Content: strings.TrimSpace(chatCompletion.Choices[0].Message.Content),
+        }
+        if err := db.AppendExamTurns(r.Context(), conn, session.ID, candidateTurn, interlocutorTurn); err != nil {
+            if errors.Is(err, db.ErrSessionEnded) {
+                http.Error(w, "Exam has finished", http.StatusConflict)
+                return
+            }
+            log.Printf("Failed to save exam turns: %v", err)
+            http.Error(w, "Failed to save exam turns", http.StatusInternalServerError)
+            return
+        }
+        history = append(history, interlocutorTurn)
+
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(map[string]interface{}{
+            "transcribed_text":  result.Text,
+            "pronunciation":     candidateTurn.Pronunciation,
+            "response":          interlocutorTurn.Content,
+            "audio_base64":      speak(r, synthesizer, interlocutorTurn.Content),
+            "part":              part.Number,
+            "time_up":           elapsed >= part.Duration(),
+            "remaining_seconds": remainingSeconds(session, part),
+            "history":           history,
+        })
+    }
+}
+
+// NextPartHandler moves the exam on to its next part and returns the interlocutor's
+// opening words. After the last part it reports finished instead.
+func NextPartHandler(synthesizer tts.Synthesizer) func(http.ResponseWriter, *http.Request, *pgx.Conn) {
+    return func(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+        userID, ok := middleware.CurrentUserID(w, r, conn)
+        if !ok {
+            return
+        }
+
+        var request struct {
+            SessionID string `json:"session_id"`
+        }
+        if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+            http.Error(w, "Invalid request body", http.StatusBadRequest)
+            return
+        }
+
+        session, paper, part, ok := loadSession(w, r, conn, userID, request.SessionID)
+        if !ok {
+            return
+        }
+        if session.Status != db.ExamActive {
+            http.Error(w, "Exam has finished", http.StatusConflict)
+            return
+        }
+
+        next, ok := paper.Part(part.Number + 1)
+        if !ok {
+            w.Header().Set("Content-Type", "application/json")
+            json.NewEncoder(w).Encode(map[string]interface{}{
+                "finished": true,
+            })
+            return
+        }
+
+        opening := db.ExamTurn{
+            Part:    next.Number,
+            Role:    exam.RoleInterlocutor,
+            Content: next.Intro,
+        }
+        if err := db.AdvanceExamPart(r.Context(), conn, session.ID, part.Number, opening); err != nil {
+            if errors.Is(err, db.ErrSessionEnded) {
+                http.Error(w, "Exam part has already changed", http.StatusConflict)
+                return
+            }
+            log.Printf("Failed to advance exam part: %v", err)
+            http.Error(w, "Failed to start the next part", http.StatusInternalServerError)
+            return
+        }
+
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(map[string]interface{}{
+            "finished":          false,
+            "part":              next.Number,
+            "remaining_seconds": next.DurationSeconds,
+            "history":           append(session.History, opening),
+            "audio_base64":      speak(r, synthesizer, opening.Content),
+        })
+    }
+}
+
+// FinishHandler ends the exam and returns its per-part report. The report is graded once
+// and stored; later requests get the stored report unless regenerate is set.
+func FinishHandler(chatModel openai.ChatModel) func(http.ResponseWriter, *http.Request, *pgx.Conn) {
+    return func(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+        userID, ok := middleware.CurrentUserID(w, r, conn)
+        if !ok {
+            return
+        }
+
+        var request struct {
+            SessionID  string `json:"session_id"`
+            Regenerate bool   `json:"regenerate"`
+        }
+        if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+            http.Error(w, "Invalid request body", http.StatusBadRequest)
+            return
+        }
+
+        session, paper, _, ok := loadSession(w, r, conn, userID, request.SessionID)
+        if !ok {
+            return
+        }
+
+        if session.Report != nil && !request.Regenerate {
+            writeSession(w, session)
+            return
+        }
+
+        report, model, err := exam.Grade(r.Context(), chatModel, paper, session.History)
+        if err != nil {
+            if errors.Is(err, exam.ErrNoCandidateTurns) {
+                http.Error(w, "Exam has nothing to grade yet", http.StatusUnprocessableEntity)
+                return
+            }
+            log.Printf("Exam grading failed: %v", err)
+            http.Error(w, "Exam grading failed", http.StatusInternalServerError)
+            return
+        }
+        log.Printf("Graded exam session %s: %.1f/%d by %s", session.ID, report.Overall.Total, 4*exam.MaxBand, model)
+
+        reportJSON, err := json.Marshal(report)
+        if err != nil {
+            log.Printf("Failed to marshal exam report: %v", err)
+            http.Error(w, "Failed to save exam report", http.StatusInternalServerError)
+            return
+        }
+        if err := db.FinishExamSession(r.Context(), conn, session.ID, reportJSON, model, exam.PromptVersion); err != nil {
+            log.Printf("Failed to save exam report: %v", err)
+            http.Error(w, "Failed to save exam report", http.StatusInternalServerError)
+            return
+        }
+
+        // Reload so the response shows the stored state
+        session, _, _, ok = loadSession(w, r, conn, userID, session.ID)
+        if !ok {
+            return
+        }
+        writeSession(w, session)
+    }
+}
+
+// GetSessionHandler returns an exam session owned by the user with its report, if graded
+func GetSessionHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+
+    session, _, _, ok := loadSession(w, r, conn, userID, r.PathValue("id"))
+    if !ok {
+        return
+    }
+    writeSession(w, session)
+}
+
+// writeSession sends an exam session, including the paper so the report can name its parts
+func writeSession(w http.ResponseWriter, session *db.ExamSession) {
+    paper, _ := exam.Get(session.Exam)
+
+    var report json.RawMessage
+    if session.Report != nil {
+        report = session.Report
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "session_id":     session.ID,
+        "exam":           paper,
+        "status":         session.Status,
+        "current_part":   session.CurrentPart,
+        "history":        session.History,
+        "report":         report,
+        "model":          session.Model,
+        "prompt_version": session.PromptVersion,
+        "created_at":     session.CreatedAt,
+        "ended_at":       session.EndedAt,
+    })
+}
@@ -0,0 +1,36 @@
+package exam
+
+import (
+    "fmt"
+    "net/http"
+
+    "PulpuVOX/internal/exam"
+    examPage "PulpuVOX/web/templates/pages/exam"
+    "github.com/markbates/goth"
+)
+
+// Handler renders the Speaking test page of the exam with the given slug
+func Handler(slug string) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        paper, ok := exam.Get(slug)
+        if !ok {
+            http.NotFound(w, r)
+            return
+        }
+
+        // Describe the parts on the introduction screen
+        parts := make([]string, len(paper.Parts))
+        for i, part := range paper.Parts {
+            parts[i] = fmt.Sprintf("%s (%d minutes)", part.Title, part.DurationSeconds/60)
+        }
+
+        // Get user from context (set by auth middleware)
+        user, ok := r.Context().Value("user").(*goth.User)
+        if !ok {
+            user = nil
+        }
+
+        w.Header().Set("Content-Type", "text/html")
+        examPage.Exam(user, paper.Slug, paper.Name, parts).Render(r.Context(), w)
+    }
+}
@@ -2,93 +2,201 @@ package feedback
 
 import (
     "encoding/json"
+    "errors"
     "log"
     "net/http"
-    "strings"
+    "strconv"
 
-    "PulpuVOX/internal/handlers/conversation"
+    "PulpuVOX/internal/assessment"
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/grammar"
+    "PulpuVOX/internal/language"
+    "PulpuVOX/internal/learner"
+    "PulpuVOX/internal/middleware"
     "PulpuVOX/internal/openai"
+    "github.com/gchalakovmmi/PulpuWEB/auth"
     "github.com/jackc/pgx/v5"
 )
 
-func GenerateFeedbackHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
-    var request struct {
-        History []conversation.ConversationTurn `json:"history"`
+// GenerateFeedbackHandler returns the feedback report of a saved conversation. The report
+// is graded once and stored; later requests get the stored report unless regenerate is set.
+// The conversation is named by conversation_id, or by the session_id it was saved from.
+func GenerateFeedbackHandler(chatModel openai.ChatModel) func(http.ResponseWriter, *http.Request, *pgx.Conn) {
+    return func(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+        // Get user session from context (set by auth middleware)
+        session, ok := r.Context().Value("user_session").(*auth.Session)
+        if !ok || session == nil {
+            http.Error(w, "User not authenticated", http.StatusUnauthorized)
+            return
+        }
+        user := session.User
+
+        var request struct {
+            ConversationID int    `json:"conversation_id"`
+            SessionID      string `json:"session_id"`
+            Regenerate     bool   `json:"regenerate"`
+        }
+
+        if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+            http.Error(w, "Invalid request body", http.StatusBadRequest)
+            return
+        }
+
+        userID, err := db.GetUserIDByProviderID(r.Context(), conn, user.Provider, user.UserID)
+        if err != nil {
+            log.Printf("Error getting user ID: %v", err)
+            http.Error(w, "User not found", http.StatusNotFound)
+            return
+        }
+
+        conversationID := request.ConversationID
+        if conversationID == 0 {
+            conversationID, err = db.GetConversationIDBySession(r.Context(), conn, request.SessionID, userID)
+            if err != nil {
+                if errors.Is(err, pgx.ErrNoRows) {
+                    http.Error(w, "Conversation not found", http.StatusNotFound)
+                    return
+                }
+                log.Printf("Error fetching conversation: %v", err)
+                http.Error(w, "Failed to load conversation", http.StatusInternalServerError)
+                return
+            }
+        }
+
+        // Only the history saved by the server is graded, and only for its owner
+        conversation, err := db.GetConversation(r.Context(), conn, conversationID, userID)
+        if err != nil {
+            if errors.Is(err, pgx.ErrNoRows) {
+                http.Error(w, "Conversation not found", http.StatusNotFound)
+                return
+            }
+            log.Printf("Error fetching conversation: %v", err)
+            http.Error(w, "Failed to load conversation", http.StatusInternalServerError)
+            return
+        }
+
+        // The measured error rates are returned with the report and ground its recurring errors
+        if err := grammar.Backfill(r.Context(), conn, userID); err != nil {
+            log.Printf("Error backfilling grammar errors: %v", err)
+        }
+        overall, err := grammar.Load(r.Context(), conn, userID, nil, nil)
+        if err != nil {
+            log.Printf("Error loading grammar errors: %v", err)
+        }
+        errorStats := map[string]interface{}{
+            "conversation": grammar.Summarize(grammar.Count(conversation.History, conversation.Language)),
+            "overall":      overall,
+        }
+
+        if !request.Regenerate {
+            stored, err := assessment.Load(r.Context(), conn, conversationID)
+            if err == nil {
+                writeReport(w, r, conn, stored, errorStats, true)
+                return
+            }
+            if !errors.Is(err, pgx.ErrNoRows) {
+                log.Printf("Error loading stored feedback report: %v", err)
+            }
+        }
+
+        report, model, err := assessment.Generate(r.Context(), chatModel, conversation.History, language.Lookup(conversation.Language), overall)
+        if err != nil {
+            if errors.Is(err, assessment.ErrNoStudentTurns) {
+                http.Error(w, "Conversation has nothing to grade yet", http.StatusUnprocessableEntity)
+                return
+            }
+            log.Printf("Feedback generation failed: %v", err)
+            http.Error(w, "Feedback generation failed", http.StatusInternalServerError)
+            return
+        }
+        log.Printf("Generated feedback report for conversation %d: level %s by %s", conversationID, report.Level, model)
+
+        stored, err := assessment.Save(r.Context(), conn, conversationID, userID, report, model)
+        if err != nil {
+            log.Printf("Failed to save feedback report: %v", err)
+            http.Error(w, "Failed to save feedback report", http.StatusInternalServerError)
+            return
+        }
+
+        if err := db.RefreshMetricsLevel(r.Context(), conn, conversationID); err != nil {
+            log.Printf("Failed to record CEFR level in metrics: %v", err)
+        }
+        if err := learner.Refresh(r.Context(), conn, userID, conversation.Language); err != nil {
+            log.Printf("Failed to update learner profile: %v", err)
+        }
+
+        writeReport(w, r, conn, stored, errorStats, false)
     }
+}
+
+// writeReport sends a stored report with the measured error rates and the teacher's grade,
+// whose level takes precedence over the report's, saying whether it was graded by an
+// earlier request
+func writeReport(w http.ResponseWriter, r *http.Request, conn *pgx.Conn, stored *assessment.StoredReport, errorStats map[string]interface{}, cached bool) {
+    grade, err := db.GetConversationGrade(r.Context(), conn, stored.ConversationID)
+    if err != nil {
+        if !errors.Is(err, pgx.ErrNoRows) {
+            log.Printf("Error loading grade: %v", err)
+        }
+        grade = nil
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "conversation_id": stored.ConversationID,
+        "report":          stored.Report,
+        "prompt_version":  stored.PromptVersion,
+        "model":           stored.Model,
+        "created_at":      stored.CreatedAt,
+        "error_stats":     errorStats,
+        "grade":           grade,
+        "cached":          cached,
+    })
+}
 
-    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-        http.Error(w, "Invalid request body", http.StatusBadRequest)
+// StudentFeedbackHandler returns the stored feedback report of a student's conversation to
+// their teacher. Reports are only graded at the student's request, so a conversation the
+// student has not asked feedback for has none.
+func StudentFeedbackHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    studentID, ok := middleware.StudentID(w, r, conn)
+    if !ok {
         return
     }
 
-    // Initialize OpenAI client
-    llmClient, err := openai.NewClient()
+    conversationID, err := strconv.Atoi(r.PathValue("id"))
     if err != nil {
-        log.Printf("Failed to create OpenAI client: %v", err)
-        http.Error(w, "Failed to initialize feedback service", http.StatusInternalServerError)
+        http.Error(w, "Conversation not found", http.StatusNotFound)
         return
     }
 
-    // Build the conversation context for the prompt
-    var conversationContext strings.Builder
-    for _, turn := range request.History {
-        if turn.Role == "user" {
-            conversationContext.WriteString("Student: " + turn.Content + "\n")
-            if turn.Suggestion != "" {
-                conversationContext.WriteString("Corrected: " + turn.Suggestion + "\n")
-            }
-        } else if turn.Role == "assistant" {
-            conversationContext.WriteString("Teacher: " + turn.Content + "\n")
+    conversation, err := db.GetConversation(r.Context(), conn, conversationID, studentID)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "Conversation not found", http.StatusNotFound)
+            return
         }
+        log.Printf("Error fetching conversation: %v", err)
+        http.Error(w, "Failed to load conversation", http.StatusInternalServerError)
+        return
     }
 
-    // Create the prompt for feedback generation
-    prompt := `You are an experienced English teacher. Below is a conversation between a student and a teacher. 
-The student's turns include their original text and a corrected version when applicable.
-
-Please analyze the conversation and provide constructive feedback on the student's English proficiency.
-Focus on:
-1. Recurring grammatical errors
-2. Pronunciation issues (based on the transcriptions)
-3. Vocabulary usage and suggestions for improvement
-4. Sentence structure and fluency
-5. Overall communication effectiveness
-6. Grade the level of the student example A1, A2, B1, B2, C1 or C2
-
-Provide specific examples from the conversation and suggestions for what the student should focus on to improve.
-
-Conversation:
-` + conversationContext.String() + `
-
-Please provide your feedback:`
-
-    messages := []openai.ChatCompletionMessage{
-        {
-            Role:    "system",
-            Content: "You are a helpful and encouraging English teacher providing constructive feedback to students.",
-        },
-        {
-            Role:    "user",
-            Content: prompt,
-        },
-    }
-
-    // Send to LLM for feedback generation
-    chatCompletion, err := llmClient.CreateChatCompletion(&openai.ChatCompletionRequest{
-        Messages: messages,
-        Model:    llmClient.Model,
-    })
+    stored, err := assessment.Load(r.Context(), conn, conversationID)
     if err != nil {
-        log.Printf("Feedback generation failed: %v", err)
-        http.Error(w, "Feedback generation failed", http.StatusInternalServerError)
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "No feedback report yet", http.StatusNotFound)
+            return
+        }
+        log.Printf("Error loading stored feedback report: %v", err)
+        http.Error(w, "Failed to load feedback report", http.StatusInternalServerError)
         return
     }
 
-    feedback := chatCompletion.Choices[0].Message.Content
-    log.Printf("Generated feedback: %s", feedback)
-
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(map[string]string{
-        "feedback": feedback,
-    })
+    overall, err := grammar.Load(r.Context(), conn, studentID, nil, nil)
+    if err != nil {
+        log.Printf("Error loading grammar errors: %v", err)
+    }
+    writeReport(w, r, conn, stored, map[string]interface{}{
+        "conversation": grammar.Summarize(grammar.Count(conversation.History, conversation.Language)),
+        "overall":      overall,
+    }, true)
 }
@@ -0,0 +1,112 @@
+package languages
+
+import (
+    "encoding/json"
+    "log"
+    "net/http"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/language"
+    "PulpuVOX/internal/middleware"
+    "github.com/jackc/pgx/v5"
+)
+
+// ListLanguagesHandler returns the languages a user can practise and the one they have
+// chosen, and the native languages corrections can be explained in with theirs
+func ListLanguagesHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+
+    target, err := db.GetTargetLanguage(r.Context(), conn, userID)
+    if err != nil {
+        log.Printf("Error getting target language: %v", err)
+        http.Error(w, "Failed to load languages", http.StatusInternalServerError)
+        return
+    }
+    native, err := db.GetNativeLanguage(r.Context(), conn, userID)
+    if err != nil {
+        log.Printf("Error getting native language: %v", err)
+        http.Error(w, "Failed to load languages", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "languages":        language.All(),
+        "language":         language.Lookup(target).Code,
+        "native_languages": language.NativeLanguages(),
+        "native_language":  native,
+    })
+}
+
+// SetLanguageHandler changes the language the user practises in new conversations.
+// Conversations already started keep their language.
+func SetLanguageHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+
+    var request struct {
+        Language string `json:"language"`
+    }
+    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    target := language.Get(request.Language)
+    if target == nil {
+        http.Error(w, "language must be one of "+language.Codes(), http.StatusBadRequest)
+        return
+    }
+
+    if err := db.SetTargetLanguage(r.Context(), conn, userID, target.Code); err != nil {
+        log.Printf("Error setting target language: %v", err)
+        http.Error(w, "Failed to save language", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "language": target.Code,
+    })
+}
+
+// SetNativeLanguageHandler changes the language corrections are explained in. An empty
+// native_language turns the explanations off.
+func SetNativeLanguageHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+
+    var request struct {
+        NativeLanguage string `json:"native_language"`
+    }
+    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+    code := ""
+    if request.NativeLanguage != "" {
+        native := language.GetNative(request.NativeLanguage)
+        if native == nil {
+            http.Error(w, "Unsupported native language", http.StatusBadRequest)
+            return
+        }
+        code = native.Code
+    }
+
+    if err := db.SetNativeLanguage(r.Context(), conn, userID, code); err != nil {
+        log.Printf("Error setting native language: %v", err)
+        http.Error(w, "Failed to save native language", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "native_language": code,
+    })
+}
@@ -0,0 +1,55 @@
+package notifications
+
+import (
+    "encoding/json"
+    "log"
+    "net/http"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/middleware"
+    "github.com/jackc/pgx/v5"
+)
+
+// ListNotificationsHandler returns the user's unread notifications, newest first
+func ListNotificationsHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+
+    notifications, err := db.ListUnreadNotifications(r.Context(), conn, userID)
+    if err != nil {
+        log.Printf("Error listing notifications: %v", err)
+        http.Error(w, "Failed to load notifications", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "notifications": notifications,
+    })
+}
+
+// MarkReadHandler marks the user's notifications listed in ids as read, or all of them when
+// the body has no ids
+func MarkReadHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+
+    var request struct {
+        IDs []int `json:"ids"`
+    }
+    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    if err := db.MarkNotificationsRead(r.Context(), conn, userID, request.IDs); err != nil {
+        log.Printf("Error marking notifications read: %v", err)
+        http.Error(w, "Failed to update notifications", http.StatusInternalServerError)
+        return
+    }
+    w.WriteHeader(http.StatusNoContent)
+}
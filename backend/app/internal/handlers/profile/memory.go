@@ -0,0 +1,79 @@
+package profile
+
+import (
+    "encoding/json"
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/middleware"
+    "github.com/jackc/pgx/v5"
+)
+
+// ListMemoriesHandler returns what is remembered about the user from their conversations,
+// most recently confirmed first
+func ListMemoriesHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+
+    memories, err := db.ListLearnerMemories(r.Context(), conn, userID)
+    if err != nil {
+        log.Printf("Error listing memories: %v", err)
+        http.Error(w, "Failed to load memories", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "memories": memories,
+    })
+}
+
+// DeleteMemoryHandler forgets one fact remembered about the user
+func DeleteMemoryHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+
+    memoryID, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        http.Error(w, "Memory not found", http.StatusNotFound)
+        return
+    }
+
+    if err := db.DeleteLearnerMemory(r.Context(), conn, userID, memoryID); err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "Memory not found", http.StatusNotFound)
+            return
+        }
+        log.Printf("Error deleting memory: %v", err)
+        http.Error(w, "Failed to delete memory", http.StatusInternalServerError)
+        return
+    }
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteMemoriesHandler forgets everything remembered about the user
+func DeleteMemoriesHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+
+    deleted, err := db.DeleteLearnerMemories(r.Context(), conn, userID)
+    if err != nil {
+        log.Printf("Error deleting memories: %v", err)
+        http.Error(w, "Failed to delete memories", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "deleted": deleted,
+    })
+}
@@ -0,0 +1,157 @@
+package profile
+
+import (
+    "encoding/json"
+    "log"
+    "net/http"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+
+    "PulpuVOX/internal/assessment"
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/language"
+    "PulpuVOX/internal/learner"
+    "PulpuVOX/internal/middleware"
+    "github.com/jackc/pgx/v5"
+)
+
+// Limits of the lists in a learner profile
+const (
+    maxEntries     = 10
+    maxEntryLength = 100
+)
+
+// cleanEntries trims the entries of a list, dropping empty and repeated ones
+func cleanEntries(entries []string) ([]string, bool) {
+    cleaned := []string{}
+    seen := map[string]bool{}
+    for _, entry := range entries {
+        entry = strings.TrimSpace(entry)
+        key := strings.ToLower(entry)
+        if entry == "" || seen[key] {
+            continue
+        }
+        if utf8.RuneCountInString(entry) > maxEntryLength {
+            return nil, false
+        }
+        seen[key] = true
+        cleaned = append(cleaned, entry)
+    }
+    return cleaned, len(cleaned) <= maxEntries
+}
+
+// profileLanguage resolves the language whose level a profile request is about, the user's
+// target language when none is given
+func profileLanguage(w http.ResponseWriter, r *http.Request, conn *pgx.Conn, userID int, code string) (string, bool) {
+    if code == "" {
+        target, err := db.GetTargetLanguage(r.Context(), conn, userID)
+        if err != nil {
+            log.Printf("Error getting target language: %v", err)
+            http.Error(w, "Failed to load profile", http.StatusInternalServerError)
+            return "", false
+        }
+        return language.Lookup(target).Code, true
+    }
+    if language.Get(code) == nil {
+        http.Error(w, "language must be one of "+language.Codes(), http.StatusBadRequest)
+        return "", false
+    }
+    return code, true
+}
+
+// GetProfileHandler returns the user's learner profile with their level in a language and
+// how replies in it are adapted to them. Query parameters: language (default: the language
+// the user practises).
+func GetProfileHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+    code, ok := profileLanguage(w, r, conn, userID, r.URL.Query().Get("language"))
+    if !ok {
+        return
+    }
+
+    profile, err := db.GetLearnerProfile(r.Context(), conn, userID, code)
+    if err != nil {
+        log.Printf("Error loading learner profile: %v", err)
+        http.Error(w, "Failed to load profile", http.StatusInternalServerError)
+        return
+    }
+    writeProfile(w, profile)
+}
+
+// UpdateProfileHandler replaces the user's learner profile. The body holds the language
+// (default: the language the user practises) and the cefr_level in it (empty if unknown),
+// which the levels of their graded conversations in that language replace, and lists of
+// interests, goals and preferred_topics, which apply to every language.
+func UpdateProfileHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+
+    var request struct {
+        Language        string   `json:"language"`
+        CEFRLevel       string   `json:"cefr_level"`
+        Interests       []string `json:"interests"`
+        Goals           []string `json:"goals"`
+        PreferredTopics []string `json:"preferred_topics"`
+    }
+    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    code, ok := profileLanguage(w, r, conn, userID, request.Language)
+    if !ok {
+        return
+    }
+
+    profile := &db.LearnerProfile{UserID: userID, Language: code}
+    level := strings.ToUpper(strings.TrimSpace(request.CEFRLevel))
+    if level != "" {
+        if assessment.LevelRank(level) < 0 {
+            http.Error(w, "cefr_level must be empty or one of "+strings.Join(assessment.CEFRLevels, ", "), http.StatusBadRequest)
+            return
+        }
+        profile.CEFRLevel = &level
+    }
+    lists := []struct {
+        name    string
+        entries []string
+        target  *[]string
+    }{
+        {"interests", request.Interests, &profile.Interests},
+        {"goals", request.Goals, &profile.Goals},
+        {"preferred_topics", request.PreferredTopics, &profile.PreferredTopics},
+    }
+    for _, list := range lists {
+        cleaned, ok := cleanEntries(list.entries)
+        if !ok {
+            http.Error(w, list.name+" must have at most "+strconv.Itoa(maxEntries)+" entries of at most "+
+                strconv.Itoa(maxEntryLength)+" characters", http.StatusBadRequest)
+            return
+        }
+        *list.target = cleaned
+    }
+
+    if err := db.SaveLearnerProfile(r.Context(), conn, profile); err != nil {
+        log.Printf("Error saving learner profile: %v", err)
+        http.Error(w, "Failed to save profile", http.StatusInternalServerError)
+        return
+    }
+    writeProfile(w, profile)
+}
+
+// writeProfile sends a profile with the sentence cap and speech speed its level sets
+func writeProfile(w http.ResponseWriter, profile *db.LearnerProfile) {
+    difficulty := learner.DifficultyFor(profile)
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "profile":       profile,
+        "max_sentences": difficulty.MaxSentences,
+        "speech_speed":  difficulty.SpeechSpeed,
+    })
+}
@@ -0,0 +1,154 @@
+package progress
+
+import (
+    "encoding/json"
+    "log"
+    "net/http"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/grammar"
+    "PulpuVOX/internal/handlers/query"
+    "PulpuVOX/internal/middleware"
+    "PulpuVOX/internal/progress"
+    "github.com/jackc/pgx/v5"
+)
+
+// loadMetrics returns the user's metrics in the requested date range, first recording
+// the metrics of conversations saved before progress was tracked
+func loadMetrics(w http.ResponseWriter, r *http.Request, conn *pgx.Conn, userID int) ([]db.ConversationMetrics, bool) {
+    from, to, err := query.DateRange(r)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return nil, false
+    }
+
+    if err := progress.Backfill(r.Context(), conn, userID); err != nil {
+        log.Printf("Error backfilling conversation metrics: %v", err)
+    }
+
+    metrics, err := db.ListConversationMetrics(r.Context(), conn, userID, from, to)
+    if err != nil {
+        log.Printf("Error fetching conversation metrics: %v", err)
+        http.Error(w, "Failed to load progress", http.StatusInternalServerError)
+        return nil, false
+    }
+    return metrics, true
+}
+
+// ConversationMetricsHandler returns the metrics of every conversation in time order
+func ConversationMetricsHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+    writeConversationMetrics(w, r, conn, userID)
+}
+
+// StudentConversationMetricsHandler returns the conversation metrics of a student to their teacher
+func StudentConversationMetricsHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    studentID, ok := middleware.StudentID(w, r, conn)
+    if !ok {
+        return
+    }
+    writeConversationMetrics(w, r, conn, studentID)
+}
+
+// writeConversationMetrics writes the metrics of the user's conversations
+func writeConversationMetrics(w http.ResponseWriter, r *http.Request, conn *pgx.Conn, userID int) {
+    metrics, ok := loadMetrics(w, r, conn, userID)
+    if !ok {
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "metrics": metrics,
+    })
+}
+
+// TrendHandler returns the metrics averaged per day, week or month (the interval parameter)
+func TrendHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+    writeTrend(w, r, conn, userID)
+}
+
+// StudentTrendHandler returns the metrics trend of a student to their teacher
+func StudentTrendHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    studentID, ok := middleware.StudentID(w, r, conn)
+    if !ok {
+        return
+    }
+    writeTrend(w, r, conn, studentID)
+}
+
+// writeTrend writes the user's metrics averaged per interval
+func writeTrend(w http.ResponseWriter, r *http.Request, conn *pgx.Conn, userID int) {
+    interval := r.URL.Query().Get("interval")
+    if interval == "" {
+        interval = "week"
+    }
+    if !progress.ValidInterval(interval) {
+        http.Error(w, "interval must be day, week or month", http.StatusBadRequest)
+        return
+    }
+
+    metrics, ok := loadMetrics(w, r, conn, userID)
+    if !ok {
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "interval": interval,
+        "trend":    progress.Trend(metrics, interval),
+    })
+}
+
+// ErrorsHandler returns the user's grammar errors by type over the requested date range,
+// with the share of sentences each type occurs in and a description of the most frequent
+func ErrorsHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+    writeErrors(w, r, conn, userID)
+}
+
+// StudentErrorsHandler returns the grammar errors of a student to their teacher
+func StudentErrorsHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    studentID, ok := middleware.StudentID(w, r, conn)
+    if !ok {
+        return
+    }
+    writeErrors(w, r, conn, studentID)
+}
+
+// writeErrors writes the user's grammar errors by type
+func writeErrors(w http.ResponseWriter, r *http.Request, conn *pgx.Conn, userID int) {
+    from, to, err := query.DateRange(r)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    if err := grammar.Backfill(r.Context(), conn, userID); err != nil {
+        log.Printf("Error backfilling grammar errors: %v", err)
+    }
+
+    stats, err := grammar.Load(r.Context(), conn, userID, from, to)
+    if err != nil {
+        log.Printf("Error fetching grammar errors: %v", err)
+        http.Error(w, "Failed to load grammar errors", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "sentences": stats.Sentences,
+        "errors":    stats.Errors,
+        "insights":  stats.Insights(),
+    })
+}
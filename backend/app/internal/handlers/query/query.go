@@ -0,0 +1,57 @@
+package query
+
+import (
+    "fmt"
+    "net/http"
+    "strconv"
+    "time"
+)
+
+// dateLayout is the format of date query parameters
+const dateLayout = "2006-01-02"
+
+// PositiveInt reads an optional positive integer query parameter
+func PositiveInt(r *http.Request, key string, defaultValue int) (int, error) {
+    value := r.URL.Query().Get(key)
+    if value == "" {
+        return defaultValue, nil
+    }
+    n, err := strconv.Atoi(value)
+    if err != nil || n < 1 {
+        return 0, fmt.Errorf("%s must be a positive integer", key)
+    }
+    return n, nil
+}
+
+// parseDate reads an optional date query parameter given as YYYY-MM-DD or RFC 3339
+func parseDate(r *http.Request, key string) (*time.Time, bool, error) {
+    value := r.URL.Query().Get(key)
+    if value == "" {
+        return nil, false, nil
+    }
+    if t, err := time.Parse(dateLayout, value); err == nil {
+        return &t, true, nil
+    }
+    if t, err := time.Parse(time.RFC3339, value); err == nil {
+        return &t, false, nil
+    }
+    return nil, false, fmt.Errorf("%s must be a date like 2025-01-31", key)
+}
+
+// DateRange reads the optional from and to query parameters. The returned end is
+// exclusive, so a plain to date includes the whole day.
+func DateRange(r *http.Request) (*time.Time, *time.Time, error) {
+    from, _, err := parseDate(r, "from")
+    if err != nil {
+        return nil, nil, err
+    }
+    to, dateOnly, err := parseDate(r, "to")
+    if err != nil {
+        return nil, nil, err
+    }
+    if to != nil && dateOnly {
+        end := to.AddDate(0, 0, 1)
+        to = &end
+    }
+    return from, to, nil
+}
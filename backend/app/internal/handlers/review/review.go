@@ -0,0 +1,166 @@
+package review
+
+import (
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "io"
+    "log"
+    "net/http"
+    "strconv"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/middleware"
+    "PulpuVOX/internal/review"
+    "PulpuVOX/internal/tts"
+    "PulpuVOX/internal/whisper"
+    reviewPage "PulpuVOX/web/templates/pages/review"
+    "github.com/jackc/pgx/v5"
+    "github.com/markbates/goth"
+)
+
+// maxAnswerAudioSize caps the audio accepted for a single answer
+const maxAnswerAudioSize = 10 << 20 // 10 MB
+
+// NextCardHandler returns the user's most overdue review card with the original sentence
+// spoken, together with how many cards are due. The card is null when none is due.
+func NextCardHandler(synthesizer tts.Synthesizer) func(http.ResponseWriter, *http.Request, *pgx.Conn) {
+    return func(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+        userID, ok := middleware.CurrentUserID(w, r, conn)
+        if !ok {
+            return
+        }
+
+        // Corrections from conversations saved before reviews existed become cards first
+        if err := review.Backfill(r.Context(), conn, userID); err != nil {
+            log.Printf("Error backfilling review cards: %v", err)
+        }
+
+        stats, err := db.GetReviewStats(r.Context(), conn, userID)
+        if err != nil {
+            log.Printf("Error counting review cards: %v", err)
+            http.Error(w, "Failed to load review cards", http.StatusInternalServerError)
+            return
+        }
+
+        card, err := db.NextDueReviewCard(r.Context(), conn, userID)
+        if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+            log.Printf("Error fetching review card: %v", err)
+            http.Error(w, "Failed to load review cards", http.StatusInternalServerError)
+            return
+        }
+
+        audioBase64 := ""
+        if card != nil {
+            audioBase64 = speak(r, synthesizer, card.Original, card.Language)
+        }
+
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(map[string]interface{}{
+            "card":         card,
+            "audio_base64": audioBase64,
+            "stats":        stats,
+        })
+    }
+}
+
+// AnswerHandler grades the student's spoken answer to a card and schedules its next review.
+// The multipart form holds card_id, audio and mime_type.
+func AnswerHandler(transcriber whisper.Transcriber) func(http.ResponseWriter, *http.Request, *pgx.Conn) {
+    return func(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+        userID, ok := middleware.CurrentUserID(w, r, conn)
+        if !ok {
+            return
+        }
+
+        if err := r.ParseMultipartForm(maxAnswerAudioSize); err != nil {
+            log.Printf("Error parsing form: %v", err)
+            http.Error(w, "Unable to parse form", http.StatusBadRequest)
+            return
+        }
+
+        cardID, err := strconv.Atoi(r.FormValue("card_id"))
+        if err != nil {
+            http.Error(w, "Review card not found", http.StatusNotFound)
+            return
+        }
+        card, err := db.GetReviewCard(r.Context(), conn, cardID, userID)
+        if err != nil {
+            if errors.Is(err, pgx.ErrNoRows) {
+                http.Error(w, "Review card not found", http.StatusNotFound)
+                return
+            }
+            log.Printf("Error fetching review card: %v", err)
+            http.Error(w, "Failed to load review card", http.StatusInternalServerError)
+            return
+        }
+
+        file, _, err := r.FormFile("audio")
+        if err != nil {
+            log.Printf("Error getting audio file: %v", err)
+            http.Error(w, "Unable to get audio file", http.StatusBadRequest)
+            return
+        }
+        defer file.Close()
+
+        audioData, err := io.ReadAll(file)
+        if err != nil {
+            log.Printf("Error reading audio data: %v", err)
+            http.Error(w, "Unable to read audio data", http.StatusBadRequest)
+            return
+        }
+
+        transcription, err := transcriber.Transcribe(r.Context(), &whisper.TranscribeRequest{
+            AudioData: audioData,
+            FileName: whisper.FileNameForMimeType(r.FormValue("mime_type")),
+            Language: card.Language,
+            Task: "transcribe",
+            OutputFormat: "json",
+        })
+        if err != nil {
+            log.Printf("Transcription failed: %v", err)
+            http.Error(w, "Transcription failed", http.StatusInternalServerError)
+            return
+        }
+
+        result := review.Answer(card, transcription.Text)
+        if err := db.SaveReviewAnswer(r.Context(), conn, card, result.Correct); err != nil {
+            log.Printf("Failed to save review answer: %v", err)
+            http.Error(w, "Failed to save review answer", http.StatusInternalServerError)
+            return
+        }
+
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(map[string]interface{}{
+            "result": result,
+            "card":   card,
+        })
+    }
+}
+
+// speak synthesizes a sentence, returning no audio when synthesis fails so the card can
+// still be reviewed from its text
+func speak(r *http.Request, synthesizer tts.Synthesizer, text, language string) string {
+    ttsResp, err := synthesizer.Synthesize(r.Context(), &tts.TTSRequest{Text: text, Language: language})
+    if err != nil {
+        log.Printf("TTS conversion failed: %v", err)
+        return ""
+    }
+    if ttsResp.Error != "" {
+        log.Printf("TTS error: %s", ttsResp.Error)
+        return ""
+    }
+    return base64.StdEncoding.EncodeToString(ttsResp.AudioData)
+}
+
+// Handler renders the review page
+func Handler(w http.ResponseWriter, r *http.Request) {
+    // Get user from context (set by auth middleware)
+    user, ok := r.Context().Value("user").(*goth.User)
+    if !ok {
+        user = nil
+    }
+
+    w.Header().Set("Content-Type", "text/html")
+    reviewPage.Review(user).Render(r.Context(), w)
+}
@@ -0,0 +1,239 @@
+package scenarios
+
+import (
+    "encoding/json"
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/middleware"
+    "github.com/jackc/pgx/v5"
+)
+
+// Limits of a scenario
+const (
+    maxTitleLength      = 100
+    maxTextLength       = 1000
+    maxVocabulary       = 20
+    maxVocabularyLength = 50
+)
+
+// ListScenariosHandler returns the scenarios a conversation can be set in
+func ListScenariosHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    if _, ok := middleware.CurrentUserID(w, r, conn); !ok {
+        return
+    }
+
+    scenarios, err := db.ListScenarios(r.Context(), conn)
+    if err != nil {
+        log.Printf("Error fetching scenarios: %v", err)
+        http.Error(w, "Failed to load scenarios", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "scenarios": scenarios,
+    })
+}
+
+// slugify turns a title into the slug of a scenario: "Ordering at a Restaurant" ->
+// "ordering-at-a-restaurant"
+func slugify(title string) string {
+    words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+    })
+    if len(words) == 0 {
+        return "scenario"
+    }
+    return strings.Join(words, "-")
+}
+
+// decodeScenario reads and validates the scenario in a request body. Every field but the
+// description and the target vocabulary is required.
+func decodeScenario(w http.ResponseWriter, r *http.Request) (*db.Scenario, bool) {
+    var request struct {
+        Title               string   `json:"title"`
+        Description         string   `json:"description"`
+        Setting             string   `json:"setting"`
+        AIRole              string   `json:"ai_role"`
+        LearnerGoal         string   `json:"learner_goal"`
+        TargetVocabulary    []string `json:"target_vocabulary"`
+        CompletionCondition string   `json:"completion_condition"`
+        OpeningLine         string   `json:"opening_line"`
+    }
+    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return nil, false
+    }
+
+    scenario := &db.Scenario{
+        Title:               strings.TrimSpace(request.Title),
+        Description:         strings.TrimSpace(request.Description),
+        Setting:             strings.TrimSpace(request.Setting),
+        AIRole:              strings.TrimSpace(request.AIRole),
+        LearnerGoal:         strings.TrimSpace(request.LearnerGoal),
+        TargetVocabulary:    []string{},
+        CompletionCondition: strings.TrimSpace(request.CompletionCondition),
+        OpeningLine:         strings.TrimSpace(request.OpeningLine),
+    }
+    if scenario.Title == "" || utf8.RuneCountInString(scenario.Title) > maxTitleLength {
+        http.Error(w, "title must be between 1 and "+strconv.Itoa(maxTitleLength)+" characters", http.StatusBadRequest)
+        return nil, false
+    }
+    if utf8.RuneCountInString(scenario.Description) > maxTextLength {
+        http.Error(w, "description must be at most "+strconv.Itoa(maxTextLength)+" characters", http.StatusBadRequest)
+        return nil, false
+    }
+    required := []struct {
+        name  string
+        value string
+    }{
+        {"setting", scenario.Setting},
+        {"ai_role", scenario.AIRole},
+        {"learner_goal", scenario.LearnerGoal},
+        {"completion_condition", scenario.CompletionCondition},
+        {"opening_line", scenario.OpeningLine},
+    }
+    for _, field := range required {
+        if field.value == "" || utf8.RuneCountInString(field.value) > maxTextLength {
+            http.Error(w, field.name+" must be between 1 and "+strconv.Itoa(maxTextLength)+" characters", http.StatusBadRequest)
+            return nil, false
+        }
+    }
+
+    for _, word := range request.TargetVocabulary {
+        word = strings.TrimSpace(word)
+        if word == "" {
+            continue
+        }
+        if utf8.RuneCountInString(word) > maxVocabularyLength {
+            http.Error(w, "target_vocabulary entries must be at most "+strconv.Itoa(maxVocabularyLength)+" characters", http.StatusBadRequest)
+            return nil, false
+        }
+        scenario.TargetVocabulary = append(scenario.TargetVocabulary, word)
+    }
+    if len(scenario.TargetVocabulary) > maxVocabulary {
+        http.Error(w, "target_vocabulary must have at most "+strconv.Itoa(maxVocabulary)+" entries", http.StatusBadRequest)
+        return nil, false
+    }
+    return scenario, true
+}
+
+// ownedScenario resolves the scenario named by the id path value for the teacher who
+// created it or an admin. Only admins can change the built-in scenarios.
+func ownedScenario(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) (*db.Scenario, bool) {
+    userID, role, ok := middleware.RequireRole(w, r, conn, db.RoleTeacher, db.RoleAdmin)
+    if !ok {
+        return nil, false
+    }
+
+    scenarioID, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        http.Error(w, "Scenario not found", http.StatusNotFound)
+        return nil, false
+    }
+
+    scenario, err := db.GetScenario(r.Context(), conn, scenarioID)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "Scenario not found", http.StatusNotFound)
+            return nil, false
+        }
+        log.Printf("Error fetching scenario: %v", err)
+        http.Error(w, "Failed to load scenario", http.StatusInternalServerError)
+        return nil, false
+    }
+    if role != db.RoleAdmin && (scenario.CreatedBy == nil || *scenario.CreatedBy != userID) {
+        http.Error(w, "Only the teacher who created a scenario can change it", http.StatusForbidden)
+        return nil, false
+    }
+    return scenario, true
+}
+
+// CreateScenarioHandler adds a scenario created by the user, who must be a teacher or an
+// admin. The body holds the title, description, setting, ai_role, learner_goal,
+// target_vocabulary, completion_condition and opening_line; the slug is made from the title.
+func CreateScenarioHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, _, ok := middleware.RequireRole(w, r, conn, db.RoleTeacher, db.RoleAdmin)
+    if !ok {
+        return
+    }
+
+    scenario, ok := decodeScenario(w, r)
+    if !ok {
+        return
+    }
+    scenario.Slug = slugify(scenario.Title)
+    scenario.CreatedBy = &userID
+
+    if err := db.CreateScenario(r.Context(), conn, scenario); err != nil {
+        if errors.Is(err, db.ErrScenarioSlugTaken) {
+            http.Error(w, "A scenario with this title already exists", http.StatusConflict)
+            return
+        }
+        log.Printf("Error creating scenario: %v", err)
+        http.Error(w, "Failed to create scenario", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(scenario)
+}
+
+// UpdateScenarioHandler replaces a scenario the user created, with the same body as
+// CreateScenarioHandler. Its slug is kept so that it stays stable.
+func UpdateScenarioHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    existing, ok := ownedScenario(w, r, conn)
+    if !ok {
+        return
+    }
+
+    scenario, ok := decodeScenario(w, r)
+    if !ok {
+        return
+    }
+    scenario.ID = existing.ID
+    scenario.Slug = existing.Slug
+    scenario.CreatedBy = existing.CreatedBy
+    scenario.CreatedAt = existing.CreatedAt
+
+    if err := db.UpdateScenario(r.Context(), conn, scenario); err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "Scenario not found", http.StatusNotFound)
+            return
+        }
+        log.Printf("Error updating scenario: %v", err)
+        http.Error(w, "Failed to update scenario", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(scenario)
+}
+
+// DeleteScenarioHandler deletes a scenario the user created. Conversations set in it are
+// kept, and assignments of it can no longer be started.
+func DeleteScenarioHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    scenario, ok := ownedScenario(w, r, conn)
+    if !ok {
+        return
+    }
+
+    if err := db.DeleteScenario(r.Context(), conn, scenario.ID); err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "Scenario not found", http.StatusNotFound)
+            return
+        }
+        log.Printf("Error deleting scenario: %v", err)
+        http.Error(w, "Failed to delete scenario", http.StatusInternalServerError)
+        return
+    }
+    w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,276 @@
+package vocabulary
+
+import (
+    "encoding/csv"
+    "encoding/json"
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+    "unicode"
+
+    "PulpuVOX/internal/assessment"
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/handlers/query"
+    "PulpuVOX/internal/middleware"
+    "PulpuVOX/internal/textnorm"
+    "PulpuVOX/internal/vocabulary"
+    vocabularyPage "PulpuVOX/web/templates/pages/vocabulary"
+    "github.com/jackc/pgx/v5"
+    "github.com/markbates/goth"
+)
+
+// Page sizes of the vocabulary and word bank lists
+const (
+    defaultPerPage = 50
+    maxPerPage     = 200
+)
+
+// Length limits of a saved word and its context
+const (
+    maxWordLength    = 100
+    maxContextLength = 500
+)
+
+// pagination reads the page and per_page query parameters
+func pagination(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+    page, err := query.PositiveInt(r, "page", 1)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return 0, 0, false
+    }
+    perPage, err := query.PositiveInt(r, "per_page", defaultPerPage)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return 0, 0, false
+    }
+    return page, min(perPage, maxPerPage), true
+}
+
+// ListVocabularyHandler returns a page of the words the user has used or been corrected on.
+// Query parameters: q (start of the word), level (CEFR level), sort (frequency, recent or
+// alphabetical), page and per_page.
+func ListVocabularyHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+
+    page, perPage, ok := pagination(w, r)
+    if !ok {
+        return
+    }
+
+    params := r.URL.Query()
+    level := strings.ToUpper(params.Get("level"))
+    if level != "" && assessment.LevelRank(level) < 0 {
+        http.Error(w, "level must be one of "+strings.Join(assessment.CEFRLevels, ", "), http.StatusBadRequest)
+        return
+    }
+    sort := params.Get("sort")
+    switch sort {
+    case "":
+        sort = db.VocabularySortFrequency
+    case db.VocabularySortFrequency, db.VocabularySortRecent, db.VocabularySortAlpha:
+    default:
+        http.Error(w, "sort must be frequency, recent or alphabetical", http.StatusBadRequest)
+        return
+    }
+
+    // Conversations saved before vocabulary was tracked are counted first
+    if err := vocabulary.Backfill(r.Context(), conn, userID); err != nil {
+        log.Printf("Error backfilling vocabulary: %v", err)
+    }
+
+    words, total, err := db.ListVocabulary(r.Context(), conn, userID, db.VocabularyFilter{
+        Search: strings.ToLower(strings.TrimSpace(params.Get("q"))),
+        Level:  level,
+        Sort:   sort,
+        Limit:  perPage,
+        Offset: (page - 1) * perPage,
+    })
+    if err != nil {
+        log.Printf("Error listing vocabulary: %v", err)
+        http.Error(w, "Failed to load vocabulary", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "words":       words,
+        "page":        page,
+        "per_page":    perPage,
+        "total":       total,
+        "total_pages": (total + perPage - 1) / perPage,
+    })
+}
+
+// ListWordBankHandler returns a page of the user's saved words, newest first.
+// Query parameters: q (start of the word), page and per_page.
+func ListWordBankHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+
+    page, perPage, ok := pagination(w, r)
+    if !ok {
+        return
+    }
+
+    search := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("q")))
+    entries, total, err := db.ListWordBank(r.Context(), conn, userID, search, perPage, (page-1)*perPage)
+    if err != nil {
+        log.Printf("Error listing word bank: %v", err)
+        http.Error(w, "Failed to load word bank", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "words":       entries,
+        "page":        page,
+        "per_page":    perPage,
+        "total":       total,
+        "total_pages": (total + perPage - 1) / perPage,
+    })
+}
+
+// SaveWordHandler adds a word to the user's word bank. The body holds the word, the
+// sentence it appeared in and optionally the conversation session it came from.
+func SaveWordHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+
+    var request struct {
+        Word      string  `json:"word"`
+        Context   string  `json:"context"`
+        SessionID *string `json:"session_id"`
+    }
+    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    // Surrounding punctuation is dropped: "Hello," is saved as "Hello"
+    word := strings.TrimFunc(request.Word, func(r rune) bool { return !unicode.IsLetter(r) })
+    lemmas := textnorm.Lemmas(word)
+    if len(lemmas) != 1 || len(word) > maxWordLength {
+        http.Error(w, "word must be a single word", http.StatusBadRequest)
+        return
+    }
+    lemma := lemmas[0]
+    sentence := strings.TrimSpace(request.Context)
+    if len(sentence) > maxContextLength {
+        http.Error(w, "context must be at most "+strconv.Itoa(maxContextLength)+" characters", http.StatusBadRequest)
+        return
+    }
+
+    entry := &db.WordBankEntry{
+        Word:      word,
+        Lemma:     lemma,
+        CEFRLevel: vocabulary.Level(lemma),
+        Context:   sentence,
+        SessionID: request.SessionID,
+    }
+    if err := db.SaveWordBankEntry(r.Context(), conn, userID, entry); err != nil {
+        log.Printf("Error saving word: %v", err)
+        http.Error(w, "Failed to save word", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(entry)
+}
+
+// DeleteWordHandler removes a word from the user's word bank
+func DeleteWordHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+
+    entryID, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        http.Error(w, "Word not found", http.StatusNotFound)
+        return
+    }
+
+    if err := db.DeleteWordBankEntry(r.Context(), conn, userID, entryID); err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            http.Error(w, "Word not found", http.StatusNotFound)
+            return
+        }
+        log.Printf("Error deleting word: %v", err)
+        http.Error(w, "Failed to delete word", http.StatusInternalServerError)
+        return
+    }
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// ExportWordBankHandler downloads the whole word bank as CSV or JSON (the format parameter)
+func ExportWordBankHandler(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+    format := r.URL.Query().Get("format")
+    if format == "" {
+        format = "csv"
+    }
+    if format != "csv" && format != "json" {
+        http.Error(w, "format must be csv or json", http.StatusBadRequest)
+        return
+    }
+
+    userID, ok := middleware.CurrentUserID(w, r, conn)
+    if !ok {
+        return
+    }
+
+    entries, _, err := db.ListWordBank(r.Context(), conn, userID, "", 0, 0)
+    if err != nil {
+        log.Printf("Error exporting word bank: %v", err)
+        http.Error(w, "Failed to export word bank", http.StatusInternalServerError)
+        return
+    }
+
+    fileName := "pulpuvox-word-bank-" + time.Now().Format("2006-01-02") + "." + format
+    w.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`"`)
+
+    if format == "json" {
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(map[string]interface{}{
+            "words": entries,
+        })
+        return
+    }
+
+    w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+    writer := csv.NewWriter(w)
+    writer.Write([]string{"word", "lemma", "cefr_level", "context", "saved_at"})
+    for _, entry := range entries {
+        level := ""
+        if entry.CEFRLevel != nil {
+            level = *entry.CEFRLevel
+        }
+        writer.Write([]string{entry.Word, entry.Lemma, level, entry.Context, entry.CreatedAt.Format(time.RFC3339)})
+    }
+    writer.Flush()
+    if err := writer.Error(); err != nil {
+        log.Printf("Error writing word bank CSV: %v", err)
+    }
+}
+
+// Handler renders the vocabulary and word bank page
+func Handler(w http.ResponseWriter, r *http.Request) {
+    // Get user from context (set by auth middleware)
+    user, ok := r.Context().Value("user").(*goth.User)
+    if !ok {
+        user = nil
+    }
+
+    w.Header().Set("Content-Type", "text/html")
+    vocabularyPage.Vocabulary(user).Render(r.Context(), w)
+}
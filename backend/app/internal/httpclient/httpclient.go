@@ -0,0 +1,211 @@
+package httpclient
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "math/rand/v2"
+    "net"
+    "net/http"
+    "os"
+    "strconv"
+    "time"
+)
+
+// Transport is shared by all provider clients so connections are pooled and reused
+var Transport = &http.Transport{
+    Proxy: http.ProxyFromEnvironment,
+    DialContext: (&net.Dialer{
+        Timeout:   10 * time.Second,
+        KeepAlive: 30 * time.Second,
+    }).DialContext,
+    ForceAttemptHTTP2:     true,
+    MaxIdleConns:          100,
+    MaxIdleConnsPerHost:   20,
+    IdleConnTimeout:       90 * time.Second,
+    TLSHandshakeTimeout:   10 * time.Second,
+    ExpectContinueTimeout: 1 * time.Second,
+}
+
+// New returns a client using the shared transport. The timeout applies to every attempt;
+// RetryPolicy.Budget bounds the whole call.
+func New(timeout time.Duration) *http.Client {
+    return &http.Client{
+        Transport: Transport,
+        Timeout:   timeout,
+    }
+}
+
+// TimeoutFromEnv reads a timeout such as "30s" from the environment
+func TimeoutFromEnv(key string, defaultValue time.Duration) time.Duration {
+    if value, exists := os.LookupEnv(key); exists {
+        if dur, err := time.ParseDuration(value); err == nil {
+            return dur
+        }
+    }
+    return defaultValue
+}
+
+// RetryPolicy controls how failed provider requests are retried
+type RetryPolicy struct {
+    MaxRetries int
+    BaseDelay  time.Duration
+    MaxDelay   time.Duration
+    // Budget is the deadline of the whole call, all attempts, delays and the reading of the
+    // response body included. Zero leaves the call bounded only by the request's context,
+    // which streamed responses need since they are read for longer than any attempt.
+    Budget time.Duration
+}
+
+// DefaultRetryPolicy retries three times, starting at half a second
+var DefaultRetryPolicy = RetryPolicy{
+    MaxRetries: 3,
+    BaseDelay:  500 * time.Millisecond,
+    MaxDelay:   8 * time.Second,
+}
+
+// WithBudget returns a copy of the policy whose whole call is bounded by budget
+func (p RetryPolicy) WithBudget(budget time.Duration) RetryPolicy {
+    p.Budget = budget
+    return p
+}
+
+// shouldRetry reports whether a response status is worth retrying
+func shouldRetry(statusCode int) bool {
+    return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// backoff returns the exponential delay with jitter before the given retry
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+    delay := p.BaseDelay << attempt
+    if delay <= 0 || delay > p.MaxDelay {
+        delay = p.MaxDelay
+    }
+    // Equal jitter keeps simultaneous clients from retrying in lockstep while waiting at
+    // least half the delay
+    return delay/2 + rand.N(delay/2+1)
+}
+
+// retryAfter parses the Retry-After header, given either in seconds or as an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+    value := resp.Header.Get("Retry-After")
+    if value == "" {
+        return 0, false
+    }
+    if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+        return time.Duration(seconds) * time.Second, true
+    }
+    if date, err := http.ParseTime(value); err == nil {
+        return max(time.Until(date), 0), true
+    }
+    return 0, false
+}
+
+// isTimeout reports whether an attempt failed because it ran out of time. A provider that
+// did not answer within the client timeout is not retried: it would most likely hang again
+// and the failover chain is better placed to try another one.
+func isTimeout(err error) bool {
+    var netErr net.Error
+    return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// cancelOnClose releases the budget of a call once its response body is closed
+type cancelOnClose struct {
+    io.ReadCloser
+    cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+    err := b.ReadCloser.Close()
+    b.cancel()
+    return err
+}
+
+// wait sleeps for the delay unless the context ends first
+func wait(ctx context.Context, delay time.Duration) error {
+    timer := time.NewTimer(delay)
+    defer timer.Stop()
+    select {
+    case <-ctx.Done():
+        return ctx.Err()
+    case <-timer.C:
+        return nil
+    }
+}
+
+// Do sends the request, retrying network errors, 429 and 5xx responses with
+// exponential backoff. Timed out attempts are not retried. A Retry-After header from the
+// provider takes precedence over the computed delay unless it is longer than MaxDelay,
+// which ends the retries. The last response is returned when retries are exhausted or
+// the deadline, the policy's Budget or the context's, would pass before the next attempt.
+func Do(client *http.Client, req *http.Request, policy RetryPolicy) (*http.Response, error) {
+    if policy.Budget > 0 {
+        ctx, cancel := context.WithTimeout(req.Context(), policy.Budget)
+        resp, err := do(client, req.WithContext(ctx), policy)
+        if resp == nil {
+            cancel()
+            return resp, err
+        }
+        resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+        return resp, err
+    }
+    return do(client, req, policy)
+}
+
+// do runs the attempts of Do
+func do(client *http.Client, req *http.Request, policy RetryPolicy) (*http.Response, error) {
+    ctx := req.Context()
+
+    for attempt := 0; ; attempt++ {
+        // Rewind the body for every attempt after the first
+        if attempt > 0 && req.Body != nil {
+            if req.GetBody == nil {
+                return nil, fmt.Errorf("cannot retry request to %s: body is not rewindable", req.URL.Host)
+            }
+            body, err := req.GetBody()
+            if err != nil {
+                return nil, fmt.Errorf("failed to rewind request body: %w", err)
+            }
+            req.Body = body
+        }
+
+        resp, err := client.Do(req)
+        if err == nil && !shouldRetry(resp.StatusCode) {
+            return resp, nil
+        }
+        if ctx.Err() != nil {
+            if resp != nil {
+                resp.Body.Close()
+            }
+            return nil, ctx.Err()
+        }
+        if attempt >= policy.MaxRetries || (err != nil && isTimeout(err)) {
+            return resp, err
+        }
+
+        delay := policy.backoff(attempt)
+        if resp != nil {
+            if after, ok := retryAfter(resp); ok {
+                // A provider asking for a long pause is better skipped by the failover chain
+                if after > policy.MaxDelay {
+                    return resp, err
+                }
+                delay = after
+            }
+        }
+
+        // Give up early rather than sleep past the caller's deadline
+        if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+            return resp, err
+        }
+
+        if resp != nil {
+            io.Copy(io.Discard, resp.Body)
+            resp.Body.Close()
+        }
+        if err := wait(ctx, delay); err != nil {
+            return nil, err
+        }
+    }
+}
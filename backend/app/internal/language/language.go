@@ -0,0 +1,91 @@
+package language
+
+import "strings"
+
+// Language is a language students can practise, with the prompts and greeting the
+// conversation uses in it. Code is the ISO 639-1 code, which speech recognition and
+// synthesis are configured by.
+type Language struct {
+    Code       string `json:"code"`
+    Name       string `json:"name"`
+    NativeName string `json:"native_name"`
+
+    // Greeting is the first assistant turn of a free conversation
+    Greeting string `json:"-"`
+    // ConversationPrompt is the system prompt of a free conversation with Voxy
+    ConversationPrompt string `json:"-"`
+    // SuggestionPrompt asks for the corrected version of the learner's last sentence
+    // in a <suggestion></suggestion> tag, left empty when the sentence is correct
+    SuggestionPrompt string `json:"-"`
+}
+
+// Default is the language of users who have not chosen one and of conversations saved
+// before languages could be chosen
+const Default = "en"
+
+// English is the default language
+var English = &Language{
+    Code:       "en",
+    Name:       "English",
+    NativeName: "English",
+    Greeting:   "Hello! What would you like to talk about today?",
+    ConversationPrompt: "You are a young lady named Voxy who chatts with a new English learner. Be nice and have a pleasant conversation. Ask questions to the user, express opinion and tell interesting facts to keep the conversation going and talk about yourself sometimes. When the conversation becomes stale try changing the topic. Keep responses very short - maximum 1-2 sentences. Decline any requests to write an essay or do anything which will make your response over 2 sentences long.",
+    SuggestionPrompt: "You are given a conversation. Rewrite the last user response to make it correct according the English language rules.\nEnclose the rewritten and corrected sentence in a <suggestion></suggestion> tag.\nExample:\nuser: I am like eating apple.\nyou: <suggestion>I enjoy eating apples.</suggestion>\nIf the sentence is already correct return an empty <suggestion> tag.\nExample:\nuser: I enjoy eating apples.\nyou: <suggestion></suggestion>",
+}
+
+// German is taught with informal "du", as a language school would with its students
+var German = &Language{
+    Code:       "de",
+    Name:       "German",
+    NativeName: "Deutsch",
+    Greeting:   "Hallo! Worüber möchtest du heute sprechen?",
+    ConversationPrompt: "You are a young lady named Voxy who chats with a new German learner. Always answer in simple, natural German and address the user with \"du\", even when they write in another language. Be nice and have a pleasant conversation. Ask questions to the user, express opinion and tell interesting facts to keep the conversation going and talk about yourself sometimes. When the conversation becomes stale try changing the topic. Keep responses very short - maximum 1-2 sentences. Decline any requests to write an essay or do anything which will make your response over 2 sentences long.",
+    SuggestionPrompt: "You are given a conversation. Rewrite the last user response to make it correct according to the German language rules, including noun capitalization, cases, gender and word order.\nEnclose the rewritten and corrected sentence in a <suggestion></suggestion> tag.\nExample:\nuser: Ich habe gestern in der Kino gegangen.\nyou: <suggestion>Ich bin gestern ins Kino gegangen.</suggestion>\nIf the sentence is already correct return an empty <suggestion> tag.\nExample:\nuser: Ich bin gestern ins Kino gegangen.\nyou: <suggestion></suggestion>",
+}
+
+// Spanish is taught with informal "tú"
+var Spanish = &Language{
+    Code:       "es",
+    Name:       "Spanish",
+    NativeName: "Español",
+    Greeting:   "¡Hola! ¿De qué te gustaría hablar hoy?",
+    ConversationPrompt: "You are a young lady named Voxy who chats with a new Spanish learner. Always answer in simple, natural Spanish and address the user with \"tú\", even when they write in another language. Be nice and have a pleasant conversation. Ask questions to the user, express opinion and tell interesting facts to keep the conversation going and talk about yourself sometimes. When the conversation becomes stale try changing the topic. Keep responses very short - maximum 1-2 sentences. Decline any requests to write an essay or do anything which will make your response over 2 sentences long.",
+    SuggestionPrompt: "You are given a conversation. Rewrite the last user response to make it correct according to the Spanish language rules, including gender and number agreement, verb conjugation and accents.\nEnclose the rewritten and corrected sentence in a <suggestion></suggestion> tag.\nExample:\nuser: Me gusta los libros y yo es estudiante.\nyou: <suggestion>Me gustan los libros y soy estudiante.</suggestion>\nIf the sentence is already correct return an empty <suggestion> tag.\nExample:\nuser: Me gustan los libros.\nyou: <suggestion></suggestion>",
+}
+
+// languages lists the supported languages in the order they are offered
+var languages = []*Language{English, German, Spanish}
+
+// All returns the supported languages
+func All() []*Language {
+    return languages
+}
+
+// Get returns the language with the given code, or nil if it is not supported
+func Get(code string) *Language {
+    code = strings.ToLower(strings.TrimSpace(code))
+    for _, l := range languages {
+        if l.Code == code {
+            return l
+        }
+    }
+    return nil
+}
+
+// Lookup returns the language with the given code, falling back to the default language
+// for codes stored before the language could be chosen
+func Lookup(code string) *Language {
+    if l := Get(code); l != nil {
+        return l
+    }
+    return English
+}
+
+// Codes returns the codes of the supported languages for error messages: "en, de, es"
+func Codes() string {
+    codes := make([]string, len(languages))
+    for i, l := range languages {
+        codes[i] = l.Code
+    }
+    return strings.Join(codes, ", ")
+}
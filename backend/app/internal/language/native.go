@@ -0,0 +1,52 @@
+package language
+
+import "strings"
+
+// NativeLanguage is a language a student may speak natively, which corrections are
+// explained in
+type NativeLanguage struct {
+    Code string `json:"code"`
+    Name string `json:"name"`
+}
+
+// nativeLanguages lists the native languages offered, by English name
+var nativeLanguages = []NativeLanguage{
+    {Code: "ar", Name: "Arabic"},
+    {Code: "bg", Name: "Bulgarian"},
+    {Code: "zh", Name: "Chinese"},
+    {Code: "cs", Name: "Czech"},
+    {Code: "nl", Name: "Dutch"},
+    {Code: "en", Name: "English"},
+    {Code: "fr", Name: "French"},
+    {Code: "de", Name: "German"},
+    {Code: "el", Name: "Greek"},
+    {Code: "hi", Name: "Hindi"},
+    {Code: "hu", Name: "Hungarian"},
+    {Code: "it", Name: "Italian"},
+    {Code: "ja", Name: "Japanese"},
+    {Code: "ko", Name: "Korean"},
+    {Code: "pl", Name: "Polish"},
+    {Code: "pt", Name: "Portuguese"},
+    {Code: "ro", Name: "Romanian"},
+    {Code: "ru", Name: "Russian"},
+    {Code: "es", Name: "Spanish"},
+    {Code: "tr", Name: "Turkish"},
+    {Code: "uk", Name: "Ukrainian"},
+    {Code: "vi", Name: "Vietnamese"},
+}
+
+// NativeLanguages returns the native languages a student can choose from
+func NativeLanguages() []NativeLanguage {
+    return nativeLanguages
+}
+
+// GetNative returns the native language with the given code, or nil if it is not offered
+func GetNative(code string) *NativeLanguage {
+    code = strings.ToLower(strings.TrimSpace(code))
+    for i := range nativeLanguages {
+        if nativeLanguages[i].Code == code {
+            return &nativeLanguages[i]
+        }
+    }
+    return nil
+}
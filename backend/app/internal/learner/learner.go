@@ -0,0 +1,108 @@
+package learner
+
+import (
+    "context"
+    "strings"
+
+    "PulpuVOX/internal/assessment"
+    "PulpuVOX/internal/db"
+    "github.com/jackc/pgx/v5"
+)
+
+// recentConversations is how many of the latest graded conversations the level is
+// averaged over, so one unusual conversation does not move it
+const recentConversations = 5
+
+// Difficulty is how the assistant speaks to a learner: the guidance added to its prompt,
+// the most sentences a reply may have and the speed of its speech relative to the
+// configured speed
+type Difficulty struct {
+    Guidance     string
+    MaxSentences int
+    SpeechSpeed  float64
+}
+
+// defaultDifficulty is used until the learner's level is known
+var defaultDifficulty = Difficulty{MaxSentences: 4, SpeechSpeed: 1}
+
+// difficulties maps each CEFR level to its difficulty, from slow, simple speech at A1 to
+// natural speech from C1
+var difficulties = map[string]Difficulty{
+    "A1": {
+        Guidance:     "The learner is a beginner at CEFR level A1. Use only the most common everyday words and very short, simple sentences in the present tense. Ask one simple question at a time.",
+        MaxSentences: 2,
+        SpeechSpeed:  0.8,
+    },
+    "A2": {
+        Guidance:     "The learner is at CEFR level A2. Use common everyday words and short, simple sentences, and avoid idioms. Ask one question at a time.",
+        MaxSentences: 2,
+        SpeechSpeed:  0.85,
+    },
+    "B1": {
+        Guidance:     "The learner is at CEFR level B1. Use clear, everyday language with some variety of tenses, and explain any less common word you use.",
+        MaxSentences: 3,
+        SpeechSpeed:  0.9,
+    },
+    "B2": {
+        Guidance:     "The learner is at CEFR level B2. Speak naturally with a wide range of vocabulary, avoiding only rare idioms and slang.",
+        MaxSentences: 3,
+        SpeechSpeed:  1,
+    },
+    "C1": {
+        Guidance:     "The learner is at CEFR level C1. Speak as you would to a fluent speaker, with idioms, nuanced vocabulary and complex sentences.",
+        MaxSentences: 4,
+        SpeechSpeed:  1,
+    },
+    "C2": {
+        Guidance:     "The learner is at CEFR level C2. Speak as you would to a native speaker, with idioms, nuanced vocabulary and complex sentences.",
+        MaxSentences: 4,
+        SpeechSpeed:  1,
+    },
+}
+
+// DifficultyFor returns the difficulty of a profile's level
+func DifficultyFor(profile *db.LearnerProfile) Difficulty {
+    if profile == nil || profile.CEFRLevel == nil {
+        return defaultDifficulty
+    }
+    if difficulty, ok := difficulties[*profile.CEFRLevel]; ok {
+        return difficulty
+    }
+    return defaultDifficulty
+}
+
+// InterestsPrompt returns what the assistant is told about what the learner likes to talk
+// about, or an empty string if the profile says nothing
+func InterestsPrompt(profile *db.LearnerProfile) string {
+    if profile == nil {
+        return ""
+    }
+    var parts []string
+    if len(profile.Interests) > 0 {
+        parts = append(parts, "Their interests: "+strings.Join(profile.Interests, ", ")+".")
+    }
+    if len(profile.Goals) > 0 {
+        parts = append(parts, "Their goals in learning the language: "+strings.Join(profile.Goals, ", ")+".")
+    }
+    if len(profile.PreferredTopics) > 0 {
+        parts = append(parts, "Topics they like to talk about: "+strings.Join(profile.PreferredTopics, ", ")+".")
+    }
+    if len(parts) == 0 {
+        return ""
+    }
+    return "Use what you know about the learner to suggest topics. " + strings.Join(parts, " ")
+}
+
+// Refresh sets the user's level in a language to the average of their latest graded
+// conversations in it. A level the user chose is kept until a conversation is graded.
+func Refresh(ctx context.Context, conn *pgx.Conn, userID int, language string) error {
+    levels, err := db.ListRecentLevels(ctx, conn, userID, language, recentConversations)
+    if err != nil {
+        return err
+    }
+    level := assessment.AverageLevel(levels)
+    if level == "" {
+        return nil
+    }
+    return db.SetLearnerLevel(ctx, conn, userID, language, level)
+}
@@ -0,0 +1,229 @@
+package memory
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/openai"
+    "github.com/jackc/pgx/v5"
+)
+
+// Limits on what is remembered about a learner
+const (
+    // maxFactsPerConversation is how many new facts one conversation may add
+    maxFactsPerConversation = 10
+    // maxFactLength is the longest fact kept, in characters
+    maxFactLength = 200
+    // maxMemories is how many facts are kept per user; the least recently confirmed are forgotten first
+    maxMemories = 100
+    // backfillConversations is how many unremembered conversations are read at a time, so
+    // that conversations saved before memory existed do not all go to the model at once
+    backfillConversations = 3
+)
+
+// Limits on what the assistant is reminded of at the start of a conversation
+const (
+    promptFacts  = 20
+    promptTopics = 5
+)
+
+// categories are the kinds of facts that are remembered
+var categories = map[string]bool{
+    db.MemoryPersonal: true,
+    db.MemoryWork:     true,
+    db.MemoryHobby:    true,
+    db.MemoryTopic:    true,
+}
+
+// extractionSchema describes the JSON object the model must return
+const extractionSchema = `{
+  "facts": [
+    {"category": "personal, work, hobby or topic", "fact": "a short sentence in English about the learner, e.g. \"Is called Maria\", \"Works as a nurse\", \"Plays the guitar\", \"Talked about a trip to Japan\""}
+  ],
+  "forget": [numbers of the known facts that the conversation shows are no longer true]
+}`
+
+// Extraction is what a conversation taught about the learner: new facts and the known
+// facts they replace
+type Extraction struct {
+    Facts  []db.LearnerMemory
+    Forget []int
+}
+
+// Extract asks the model for the durable facts the learner told about themselves in a
+// conversation. Known facts are numbered in the prompt so that the model can say which of
+// them are outdated; they are returned by ID in Forget.
+func Extract(ctx context.Context, chatModel openai.ChatModel, history []db.ConversationTurn, known []db.LearnerMemory) (*Extraction, error) {
+    var transcript strings.Builder
+    for _, turn := range history {
+        if turn.Role == "user" {
+            transcript.WriteString("Learner: " + turn.Content + "\n")
+        } else if turn.Role == "assistant" {
+            transcript.WriteString("Teacher: " + turn.Content + "\n")
+        }
+    }
+
+    var knownFacts strings.Builder
+    for i, memory := range known {
+        knownFacts.WriteString(strconv.Itoa(i+1) + ". " + memory.Fact + "\n")
+    }
+    if len(known) == 0 {
+        knownFacts.WriteString("None yet\n")
+    }
+
+    messages := []openai.ChatCompletionMessage{
+        {
+            Role:    "system",
+            Content: "You keep notes about a language learner so that their teacher remembers them in later conversations. You answer with a single JSON object and nothing else.",
+        },
+        {
+            Role: "user",
+            Content: `Read the conversation below and note what the learner said about themselves that will still
+be true in later conversations: their name, where they live, their family, their job or studies,
+their hobbies and plans. Also note the main topic of the conversation as one "topic" fact.
+Only note what the learner said about themselves, not what the teacher said, and never note
+health, religion, political views or other sensitive details. Leave out facts that are already
+known, and list in "forget" the known facts that the learner contradicted, e.g. an old job.
+Write at most ` + strconv.Itoa(maxFactsPerConversation) + ` facts.
+
+Known facts:
+` + knownFacts.String() + `
+Answer with a JSON object in exactly this shape:
+` + extractionSchema + `
+
+Conversation:
+` + transcript.String(),
+        },
+    }
+
+    chatCompletion, err := chatModel.Complete(ctx, &openai.ChatCompletionRequest{
+        Messages:       messages,
+        ResponseFormat: openai.JSONObjectFormat,
+    })
+    if err != nil {
+        return nil, err
+    }
+    if len(chatCompletion.Choices) == 0 {
+        return nil, errors.New("model returned no choices")
+    }
+
+    content := chatCompletion.Choices[0].Message.Content
+    if start, end := strings.Index(content, "{"), strings.LastIndex(content, "}"); start >= 0 && end > start {
+        content = content[start : end+1]
+    }
+
+    var raw struct {
+        Facts []struct {
+            Category string `json:"category"`
+            Fact     string `json:"fact"`
+        } `json:"facts"`
+        Forget []int `json:"forget"`
+    }
+    if err := json.Unmarshal([]byte(content), &raw); err != nil {
+        return nil, fmt.Errorf("invalid memory extraction: %w", err)
+    }
+
+    // Facts that do not fit are dropped rather than failing the whole extraction
+    extraction := &Extraction{}
+    seen := map[string]bool{}
+    for _, fact := range raw.Facts {
+        category := strings.ToLower(strings.TrimSpace(fact.Category))
+        text := strings.TrimSpace(fact.Fact)
+        key := strings.ToLower(text)
+        if !categories[category] || text == "" || utf8.RuneCountInString(text) > maxFactLength || seen[key] {
+            continue
+        }
+        seen[key] = true
+        extraction.Facts = append(extraction.Facts, db.LearnerMemory{Category: category, Fact: text})
+        if len(extraction.Facts) == maxFactsPerConversation {
+            break
+        }
+    }
+    for _, number := range raw.Forget {
+        if number >= 1 && number <= len(known) {
+            extraction.Forget = append(extraction.Forget, known[number-1].ID)
+        }
+    }
+    return extraction, nil
+}
+
+// hasStudentTurns reports whether the learner said anything in a conversation
+func hasStudentTurns(history []db.ConversationTurn) bool {
+    for _, turn := range history {
+        if turn.Role == "user" {
+            return true
+        }
+    }
+    return false
+}
+
+// Record remembers the facts learned from a saved conversation and marks it as remembered,
+// even when it taught nothing
+func Record(ctx context.Context, conn *pgx.Conn, chatModel openai.ChatModel, conversation *db.Conversation) error {
+    extraction := &Extraction{}
+    if hasStudentTurns(conversation.History) {
+        known, err := db.ListLearnerMemories(ctx, conn, conversation.UserID)
+        if err != nil {
+            return err
+        }
+        extraction, err = Extract(ctx, chatModel, conversation.History, known)
+        if err != nil {
+            return err
+        }
+    }
+    return db.SaveLearnerMemories(ctx, conn, conversation.UserID, conversation.ID, extraction.Facts, extraction.Forget, maxMemories)
+}
+
+// Backfill remembers the facts of the user's latest conversations that have not been
+// remembered yet, the one just saved and any an earlier attempt failed on. Scenario
+// conversations are role-play and are marked as remembered without being read.
+func Backfill(ctx context.Context, conn *pgx.Conn, chatModel openai.ChatModel, userID int) error {
+    if err := db.MarkScenarioConversationsRemembered(ctx, conn, userID); err != nil {
+        return err
+    }
+    conversations, err := db.ListConversationsWithoutMemory(ctx, conn, userID, backfillConversations)
+    if err != nil {
+        return err
+    }
+    for i := range conversations {
+        if err := Record(ctx, conn, chatModel, &conversations[i]); err != nil {
+            return fmt.Errorf("conversation %d: %w", conversations[i].ID, err)
+        }
+    }
+    return nil
+}
+
+// Prompt returns what the assistant is told it remembers about the learner, or an empty
+// string if nothing is remembered. Memories are expected most recently confirmed first.
+// Past topics are left out when the conversation already has a topic of its own.
+func Prompt(memories []db.LearnerMemory, withTopics bool) string {
+    var facts, topics []string
+    for _, memory := range memories {
+        if memory.Category == db.MemoryTopic {
+            if withTopics && len(topics) < promptTopics {
+                topics = append(topics, memory.Fact)
+            }
+        } else if len(facts) < promptFacts {
+            facts = append(facts, memory.Fact)
+        }
+    }
+    if len(facts) == 0 && len(topics) == 0 {
+        return ""
+    }
+
+    var prompt strings.Builder
+    prompt.WriteString("You have talked with this learner before. Use what you remember naturally, for example by greeting them by name or asking how something they mentioned went, but do not list it back to them.")
+    if len(facts) > 0 {
+        prompt.WriteString("\nWhat you know about them: " + strings.Join(facts, "; ") + ".")
+    }
+    if len(topics) > 0 {
+        prompt.WriteString("\nRecent conversations: " + strings.Join(topics, "; ") + ".")
+    }
+    return prompt.String()
+}
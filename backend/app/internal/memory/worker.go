@@ -0,0 +1,70 @@
+package memory
+
+import (
+    "context"
+    "log"
+    "time"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/openai"
+    pulpuwebDB "github.com/gchalakovmmi/PulpuWEB/db"
+)
+
+// jobTimeout bounds the extraction of one user's conversations
+const jobTimeout = 2 * time.Minute
+
+// queueSize is how many users may wait for extraction before new requests are dropped;
+// a dropped user's conversations are picked up the next time they are queued
+const queueSize = 100
+
+// Worker remembers the facts of saved conversations in the background, so that saving a
+// conversation does not wait for the model. It opens a database connection of its own
+// for every job since the request that queued it has closed its connection by then.
+type Worker struct {
+    connectionDetails pulpuwebDB.ConnectionDetails
+    chatModel         openai.ChatModel
+    jobs              chan int
+}
+
+// NewWorker starts a worker that reads its jobs one at a time
+func NewWorker(connectionDetails pulpuwebDB.ConnectionDetails, chatModel openai.ChatModel) *Worker {
+    w := &Worker{
+        connectionDetails: connectionDetails,
+        chatModel:         chatModel,
+        jobs:              make(chan int, queueSize),
+    }
+    go w.run()
+    return w
+}
+
+// Enqueue asks for the user's unremembered conversations to be read without waiting for it
+func (w *Worker) Enqueue(userID int) {
+    select {
+    case w.jobs <- userID:
+    default:
+        log.Printf("Memory queue is full, skipping user %d for now", userID)
+    }
+}
+
+// run reads the queued users until the process ends
+func (w *Worker) run() {
+    for userID := range w.jobs {
+        if err := w.record(userID); err != nil {
+            log.Printf("Failed to remember learner facts of user %d: %v", userID, err)
+        }
+    }
+}
+
+// record remembers the facts of one user's conversations
+func (w *Worker) record(userID int) error {
+    ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+    defer cancel()
+
+    conn, err := db.Connect(ctx, w.connectionDetails)
+    if err != nil {
+        return err
+    }
+    defer conn.Close(context.Background())
+
+    return Backfill(ctx, conn, w.chatModel, userID)
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+
+    "PulpuVOX/internal/db"
+    "github.com/gchalakovmmi/PulpuWEB/auth"
+    "github.com/jackc/pgx/v5"
+)
+
+// CurrentUserID resolves the internal ID of the user authenticated by WithDBAndAuth.
+// When it fails, the error response has been written and false is returned.
+func CurrentUserID(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) (int, bool) {
+    // Get user session from context (set by auth middleware)
+    session, ok := r.Context().Value("user_session").(*auth.Session)
+    if !ok || session == nil {
+        http.Error(w, "User not authenticated", http.StatusUnauthorized)
+        return 0, false
+    }
+    user := session.User
+
+    userID, err := db.GetUserIDByProviderID(r.Context(), conn, user.Provider, user.UserID)
+    if err != nil {
+        log.Printf("Error getting user ID: %v", err)
+        http.Error(w, "User not found", http.StatusNotFound)
+        return 0, false
+    }
+    return userID, true
+}
+
+// CurrentUserRole resolves the user like CurrentUserID and loads their role
+func CurrentUserRole(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) (int, string, bool) {
+    userID, ok := CurrentUserID(w, r, conn)
+    if !ok {
+        return 0, "", false
+    }
+
+    role, err := db.GetUserRole(r.Context(), conn, userID)
+    if err != nil {
+        log.Printf("Error getting user role: %v", err)
+        http.Error(w, "Failed to load user", http.StatusInternalServerError)
+        return 0, "", false
+    }
+    return userID, role, true
+}
+
+// RequireRole resolves the user like CurrentUserRole and rejects them with 403 Forbidden
+// unless they have one of the roles
+func RequireRole(w http.ResponseWriter, r *http.Request, conn *pgx.Conn, roles ...string) (int, string, bool) {
+    userID, role, ok := CurrentUserRole(w, r, conn)
+    if !ok {
+        return 0, "", false
+    }
+    for _, allowed := range roles {
+        if role == allowed {
+            return userID, role, true
+        }
+    }
+    http.Error(w, "Forbidden", http.StatusForbidden)
+    return 0, "", false
+}
+
+// StudentID resolves the student named by the student_id path value on a teacher route.
+// Teachers may only see the students of their own classes, admins any user; other
+// students are reported as missing.
+func StudentID(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) (int, bool) {
+    _, studentID, ok := TeacherAndStudentID(w, r, conn)
+    return studentID, ok
+}
+
+// TeacherAndStudentID is StudentID for routes that also record who the teacher is
+func TeacherAndStudentID(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) (int, int, bool) {
+    teacherID, role, ok := RequireRole(w, r, conn, db.RoleTeacher, db.RoleAdmin)
+    if !ok {
+        return 0, 0, false
+    }
+
+    studentID, err := strconv.Atoi(r.PathValue("student_id"))
+    if err != nil {
+        http.Error(w, "Student not found", http.StatusNotFound)
+        return 0, 0, false
+    }
+
+    if role == db.RoleAdmin {
+        if _, err := db.GetUserRole(r.Context(), conn, studentID); err != nil {
+            if !errors.Is(err, pgx.ErrNoRows) {
+                log.Printf("Error getting student: %v", err)
+            }
+            http.Error(w, "Student not found", http.StatusNotFound)
+            return 0, 0, false
+        }
+        return teacherID, studentID, true
+    }
+
+    teaches, err := db.TeachesStudent(r.Context(), conn, teacherID, studentID)
+    if err != nil {
+        log.Printf("Error checking class membership: %v", err)
+        http.Error(w, "Failed to load student", http.StatusInternalServerError)
+        return 0, 0, false
+    }
+    if !teaches {
+        http.Error(w, "Student not found", http.StatusNotFound)
+        return 0, 0, false
+    }
+    return teacherID, studentID, true
+}
@@ -0,0 +1,47 @@
+package openai
+
+import (
+    "context"
+
+    "PulpuVOX/internal/failover"
+)
+
+// FailoverChatModel completes chats with the first healthy provider of a chain
+type FailoverChatModel struct {
+    Chain *failover.Chain[ChatModel]
+}
+
+// Complete implements ChatModel
+func (fm *FailoverChatModel) Complete(ctx context.Context, request *ChatCompletionRequest) (*ChatCompletionResponse, error) {
+    var result *ChatCompletionResponse
+    _, err := fm.Chain.Do(ctx, func(ctx context.Context, model ChatModel) error {
+        resp, err := model.Complete(ctx, request)
+        if err != nil {
+            return err
+        }
+        result = resp
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    return result, nil
+}
+
+// CompleteStream implements ChatModel. Only opening the stream fails over, since the
+// tokens of a stream that breaks halfway have already been handed to the caller.
+func (fm *FailoverChatModel) CompleteStream(ctx context.Context, request *ChatCompletionRequest) (ChatStream, error) {
+    var result ChatStream
+    _, err := fm.Chain.Do(ctx, func(ctx context.Context, model ChatModel) error {
+        stream, err := model.CompleteStream(ctx, request)
+        if err != nil {
+            return err
+        }
+        result = stream
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    return result, nil
+}
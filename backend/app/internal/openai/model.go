@@ -0,0 +1,91 @@
+package openai
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "sync"
+)
+
+// ChatModel generates chat completions, either whole or streamed token by token
+type ChatModel interface {
+    Complete(ctx context.Context, request *ChatCompletionRequest) (*ChatCompletionResponse, error)
+    CompleteStream(ctx context.Context, request *ChatCompletionRequest) (ChatStream, error)
+}
+
+// ChatStream yields the chunks of a streamed chat completion until io.EOF
+type ChatStream interface {
+    Recv() (*ChatCompletionStreamResponse, error)
+    Close() error
+}
+
+// Factory builds a chat model from the environment configuration of its provider
+type Factory func() (ChatModel, error)
+
+var (
+    registryMu sync.RWMutex
+    registry   = map[string]Factory{}
+)
+
+// Register makes a chat model provider available under the given name
+func Register(name string, factory Factory) {
+    registryMu.Lock()
+    defer registryMu.Unlock()
+    registry[name] = factory
+}
+
+// New builds the chat model registered under the given name
+func New(name string) (ChatModel, error) {
+    registryMu.RLock()
+    factory, ok := registry[name]
+    registryMu.RUnlock()
+    if !ok {
+        return nil, fmt.Errorf("unknown chat model provider %q (available: %v)", name, Providers())
+    }
+    return factory()
+}
+
+// Providers lists the registered chat model providers
+func Providers() []string {
+    registryMu.RLock()
+    defer registryMu.RUnlock()
+    names := make([]string, 0, len(registry))
+    for name := range registry {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+func init() {
+    // Every provider speaks the OpenAI chat completions API
+    Register("openai", func() (ChatModel, error) {
+        return newClient("openai", "", true)
+    })
+    Register("groq", func() (ChatModel, error) {
+        return newClient("groq", "https://api.groq.com/openai/v1", true)
+    })
+    // A local Ollama needs no key
+    Register("ollama", func() (ChatModel, error) {
+        return newClient("ollama", "http://localhost:11434/v1", false)
+    })
+}
+
+// Complete implements ChatModel
+func (c *Client) Complete(ctx context.Context, request *ChatCompletionRequest) (*ChatCompletionResponse, error) {
+    return c.CreateChatCompletionWithContext(ctx, request)
+}
+
+// CompleteStream implements ChatModel
+func (c *Client) CompleteStream(ctx context.Context, request *ChatCompletionRequest) (ChatStream, error) {
+    stream, err := c.CreateChatCompletionStreamWithContext(ctx, request)
+    if err != nil {
+        return nil, err
+    }
+    return stream, nil
+}
+
+// SetMaxRetries implements failover.Retrier
+func (c *Client) SetMaxRetries(maxRetries int) {
+    c.RetryPolicy.MaxRetries = maxRetries
+}
@@ -2,44 +2,75 @@ package openai
 
 import (
     "bytes"
+    "context"
     "encoding/json"
     "fmt"
     "io"
     "net/http"
     "os"
+    "strings"
+    "time"
+
+    "PulpuVOX/internal/httpclient"
 )
 
 // Client represents an OpenAI client
 type Client struct {
-    BaseURL    string
-    APIKey     string
-    Model      string
+    BaseURL     string
+    APIKey      string
+    Model       string
+    Timeout     time.Duration
+    HTTPClient  *http.Client
+    RetryPolicy httpclient.RetryPolicy
 }
 
 // NewClient creates a new OpenAI client
 func NewClient() (*Client, error) {
-    baseURL := os.Getenv("OPENAI_BASE_URL")
+    return newClient("openai", "", true)
+}
+
+// newClient creates a client for an OpenAI-compatible provider. Settings are read from
+// OPENAI_<PROVIDER>_* first and fall back to the shared OPENAI_* variables. A provider
+// that does not need a key, such as a local Ollama, is called without one when none is set.
+func newClient(provider, defaultBaseURL string, keyRequired bool) (*Client, error) {
+    baseURL := providerEnv(provider, "BASE_URL")
+    if baseURL == "" {
+        baseURL = defaultBaseURL
+    }
     if baseURL == "" {
         return nil, fmt.Errorf("OPENAI_BASE_URL environment variable is not set")
     }
 
-    apiKey := os.Getenv("OPENAI_KEY")
-    if apiKey == "" {
-        return nil, fmt.Errorf("OPENAI_KEY environment variable is not set")
+    apiKey := providerEnv(provider, "KEY")
+    if apiKey == "" && keyRequired {
+        return nil, fmt.Errorf("OPENAI_%s_KEY or OPENAI_KEY environment variable is not set", strings.ToUpper(provider))
     }
 
-    model := os.Getenv("OPENAI_MODEL")
+    model := providerEnv(provider, "MODEL")
     if model == "" {
         return nil, fmt.Errorf("OPENAI_MODEL environment variable is not set")
     }
 
+    timeout := httpclient.TimeoutFromEnv("OPENAI_TIMEOUT", 30*time.Second)
+
     return &Client{
-        BaseURL: baseURL,
-        APIKey:  apiKey,
-        Model:   model,
+        BaseURL:     baseURL,
+        APIKey:      apiKey,
+        Model:       model,
+        Timeout:     timeout,
+        HTTPClient:  httpclient.New(timeout),
+        RetryPolicy: httpclient.DefaultRetryPolicy.WithBudget(timeout),
     }, nil
 }
 
+// providerEnv reads OPENAI_<PROVIDER>_<KEY>, falling back to OPENAI_<KEY>
+func providerEnv(provider, key string) string {
+    if value := os.Getenv("OPENAI_" + strings.ToUpper(provider) + "_" + key); value != "" {
+        return value
+    }
+    return os.Getenv("OPENAI_" + key)
+}
+
 // ChatCompletionMessage represents a message in the chat completion
 type ChatCompletionMessage struct {
     Role    string `json:"role"`
@@ -51,8 +82,18 @@ type ChatCompletionRequest struct {
     Model    string                  `json:"model"`
     Messages []ChatCompletionMessage `json:"messages"`
     MaxTokens int                    `json:"max_tokens,omitempty"`
+    Stream   bool                    `json:"stream,omitempty"`
+    ResponseFormat *ResponseFormat   `json:"response_format,omitempty"`
 }
 
+// ResponseFormat constrains the output of the model, e.g. to a JSON object
+type ResponseFormat struct {
+    Type string `json:"type"`
+}
+
+// JSONObjectFormat asks the model to answer with a single JSON object
+var JSONObjectFormat = &ResponseFormat{Type: "json_object"}
+
 // ChatCompletionResponse represents a response from chat completion
 type ChatCompletionResponse struct {
     ID      string `json:"id"`
@@ -71,23 +112,29 @@ type ChatCompletionResponse struct {
 
 // CreateChatCompletion creates a chat completion
 func (c *Client) CreateChatCompletion(request *ChatCompletionRequest) (*ChatCompletionResponse, error) {
+    return c.CreateChatCompletionWithContext(context.Background(), request)
+}
+
+// CreateChatCompletionWithContext is like CreateChatCompletion but is cancelled together with ctx
+func (c *Client) CreateChatCompletionWithContext(ctx context.Context, request *ChatCompletionRequest) (*ChatCompletionResponse, error) {
     url := fmt.Sprintf("%s/chat/completions", c.BaseURL)
 
-    jsonData, err := json.Marshal(request)
+    jsonData, err := json.Marshal(c.withDefaults(request))
     if err != nil {
         return nil, fmt.Errorf("failed to marshal request: %w", err)
     }
 
-    httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+    httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
     if err != nil {
         return nil, fmt.Errorf("failed to create HTTP request: %w", err)
     }
 
     httpReq.Header.Set("Content-Type", "application/json")
-    httpReq.Header.Set("Authorization", "Bearer "+c.APIKey)
+    if c.APIKey != "" {
+        httpReq.Header.Set("Authorization", "Bearer "+c.APIKey)
+    }
 
-    client := &http.Client{}
-    resp, err := client.Do(httpReq)
+    resp, err := httpclient.Do(c.HTTPClient, httpReq, c.RetryPolicy)
     if err != nil {
         return nil, fmt.Errorf("failed to send request to OpenAI: %w", err)
     }
@@ -105,3 +152,12 @@ func (c *Client) CreateChatCompletion(request *ChatCompletionRequest) (*ChatComp
 
     return &response, nil
 }
+
+// withDefaults returns a copy of the request that uses the client model unless one is set
+func (c *Client) withDefaults(request *ChatCompletionRequest) ChatCompletionRequest {
+    req := *request
+    if req.Model == "" {
+        req.Model = c.Model
+    }
+    return req
+}
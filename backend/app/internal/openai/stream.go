@@ -0,0 +1,166 @@
+package openai
+
+import (
+    "bufio"
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "strings"
+    "time"
+
+    "PulpuVOX/internal/httpclient"
+)
+
+// streamClient has no overall timeout since a stream lasts as long as the generation
+var streamClient = httpclient.New(0)
+
+// ChatCompletionDelta represents the part of a message carried by one stream chunk
+type ChatCompletionDelta struct {
+    Role    string `json:"role,omitempty"`
+    Content string `json:"content,omitempty"`
+}
+
+// ChatCompletionStreamResponse represents a chunk of a streamed chat completion
+type ChatCompletionStreamResponse struct {
+    ID      string `json:"id"`
+    Object  string `json:"object"`
+    Created int64  `json:"created"`
+    Model   string `json:"model"`
+    Choices []struct {
+        Index        int                 `json:"index"`
+        Delta        ChatCompletionDelta `json:"delta"`
+        FinishReason *string             `json:"finish_reason"`
+    } `json:"choices"`
+    Error *struct {
+        Message string `json:"message"`
+        Type    string `json:"type"`
+    } `json:"error,omitempty"`
+}
+
+// ChatCompletionStream reads the server-sent events of a streamed chat completion
+type ChatCompletionStream struct {
+    body   io.ReadCloser
+    reader *bufio.Reader
+    cancel context.CancelFunc
+    done   bool
+}
+
+// CreateChatCompletionStream creates a chat completion whose deltas are read with Recv
+func (c *Client) CreateChatCompletionStream(request *ChatCompletionRequest) (*ChatCompletionStream, error) {
+    return c.CreateChatCompletionStreamWithContext(context.Background(), request)
+}
+
+// CreateChatCompletionStreamWithContext is like CreateChatCompletionStream but is cancelled
+// together with ctx. The client timeout only bounds the wait for the response headers.
+func (c *Client) CreateChatCompletionStreamWithContext(ctx context.Context, request *ChatCompletionRequest) (*ChatCompletionStream, error) {
+    url := fmt.Sprintf("%s/chat/completions", c.BaseURL)
+
+    streamRequest := c.withDefaults(request)
+    streamRequest.Stream = true
+
+    jsonData, err := json.Marshal(&streamRequest)
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal request: %w", err)
+    }
+
+    ctx, cancel := context.WithCancel(ctx)
+    httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+    if err != nil {
+        cancel()
+        return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+    }
+
+    httpReq.Header.Set("Content-Type", "application/json")
+    httpReq.Header.Set("Accept", "text/event-stream")
+    if c.APIKey != "" {
+        httpReq.Header.Set("Authorization", "Bearer "+c.APIKey)
+    }
+
+    // Abort if the provider does not start answering in time. The timer bounds the attempts
+    // instead of the retry budget, which would also cut the stream off while it is read.
+    var headerTimer *time.Timer
+    if c.Timeout > 0 {
+        headerTimer = time.AfterFunc(c.Timeout, cancel)
+    }
+    resp, err := httpclient.Do(streamClient, httpReq, c.RetryPolicy.WithBudget(0))
+    if headerTimer != nil && !headerTimer.Stop() {
+        err = fmt.Errorf("no response within %s", c.Timeout)
+        if resp != nil {
+            resp.Body.Close()
+        }
+    }
+    if err != nil {
+        cancel()
+        return nil, fmt.Errorf("failed to send request to OpenAI: %w", err)
+    }
+
+    if resp.StatusCode != http.StatusOK {
+        defer cancel()
+        defer resp.Body.Close()
+        body, _ := io.ReadAll(resp.Body)
+        return nil, fmt.Errorf("OpenAI service returned non-OK status: %s - %s", resp.Status, string(body))
+    }
+
+    return &ChatCompletionStream{
+        body:   resp.Body,
+        reader: bufio.NewReader(resp.Body),
+        cancel: cancel,
+    }, nil
+}
+
+// Recv returns the next chunk of the stream, or io.EOF once the completion is finished
+func (s *ChatCompletionStream) Recv() (*ChatCompletionStreamResponse, error) {
+    if s.done {
+        return nil, io.EOF
+    }
+
+    for {
+        line, err := s.reader.ReadString('\n')
+        if err != nil {
+            if errors.Is(err, io.EOF) && strings.TrimSpace(line) == "" {
+                // Some servers close the stream without sending [DONE]
+                s.done = true
+                return nil, io.EOF
+            }
+            if !errors.Is(err, io.EOF) {
+                return nil, fmt.Errorf("failed to read stream: %w", err)
+            }
+        }
+
+        // Skip blank lines, comments and fields other than data
+        line = strings.TrimSpace(line)
+        if !strings.HasPrefix(line, "data:") {
+            if err != nil {
+                s.done = true
+                return nil, io.EOF
+            }
+            continue
+        }
+
+        data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+        if data == "[DONE]" {
+            s.done = true
+            return nil, io.EOF
+        }
+
+        var chunk ChatCompletionStreamResponse
+        if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+            return nil, fmt.Errorf("failed to decode stream chunk: %w", err)
+        }
+        if chunk.Error != nil {
+            return nil, fmt.Errorf("OpenAI stream returned error: %s", chunk.Error.Message)
+        }
+        return &chunk, nil
+    }
+}
+
+// Close releases the connection of the stream
+func (s *ChatCompletionStream) Close() error {
+    s.done = true
+    defer s.cancel()
+    return s.body.Close()
+}
@@ -0,0 +1,164 @@
+package progress
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "regexp"
+    "strings"
+    "time"
+
+    "PulpuVOX/internal/assessment"
+    "PulpuVOX/internal/db"
+    "github.com/jackc/pgx/v5"
+)
+
+// wordPattern matches a word, keeping contractions such as "don't" together
+var wordPattern = regexp.MustCompile(`[\p{L}]+(?:['’][\p{L}]+)*`)
+
+// words splits text into lower-case words
+func words(text string) []string {
+    return wordPattern.FindAllString(strings.ToLower(text), -1)
+}
+
+// Compute derives the metrics of a conversation from the student's turns.
+// Vocabulary diversity is the type-token ratio: distinct words over all words.
+func Compute(history []db.ConversationTurn) db.ConversationMetrics {
+    var metrics db.ConversationMetrics
+    corrected := 0
+    distinct := map[string]bool{}
+
+    for _, turn := range history {
+        if turn.Role != "user" {
+            continue
+        }
+        metrics.TurnCount++
+        if strings.TrimSpace(turn.Suggestion) != "" {
+            corrected++
+        }
+        for _, word := range words(turn.Content) {
+            metrics.WordCount++
+            distinct[word] = true
+        }
+    }
+
+    if metrics.TurnCount > 0 {
+        metrics.WordsPerTurn = round(float64(metrics.WordCount) / float64(metrics.TurnCount))
+        metrics.CorrectionRate = round(float64(corrected) / float64(metrics.TurnCount))
+    }
+    if metrics.WordCount > 0 {
+        metrics.VocabularyDiversity = round(float64(len(distinct)) / float64(metrics.WordCount))
+    }
+    return metrics
+}
+
+// round keeps two decimals
+func round(value float64) float64 {
+    return math.Round(value*100) / 100
+}
+
+// Record computes and stores the metrics of a saved conversation
+func Record(ctx context.Context, conn *pgx.Conn, conversation *db.Conversation, level *string) error {
+    metrics := Compute(conversation.History)
+    metrics.ConversationID = conversation.ID
+    metrics.UserID = conversation.UserID
+    metrics.ConversationAt = conversation.CreatedAt
+    metrics.CEFRLevel = level
+    return db.SaveConversationMetrics(ctx, conn, &metrics)
+}
+
+// Backfill records the metrics of the user's conversations saved before they were tracked
+func Backfill(ctx context.Context, conn *pgx.Conn, userID int) error {
+    conversations, levels, err := db.ListConversationsWithoutMetrics(ctx, conn, userID)
+    if err != nil {
+        return err
+    }
+    for i := range conversations {
+        if err := Record(ctx, conn, &conversations[i], levels[i]); err != nil {
+            return fmt.Errorf("conversation %d: %w", conversations[i].ID, err)
+        }
+    }
+    return nil
+}
+
+// Intervals accepted by Trend
+var intervals = map[string]bool{"day": true, "week": true, "month": true}
+
+// ValidInterval reports whether Trend can group by the interval
+func ValidInterval(interval string) bool {
+    return intervals[interval]
+}
+
+// TrendPoint averages the metrics of the conversations in one period
+type TrendPoint struct {
+    Period              time.Time `json:"period"`
+    Conversations       int       `json:"conversations"`
+    TurnCount           float64   `json:"turn_count"`
+    WordsPerTurn        float64   `json:"words_per_turn"`
+    CorrectionRate      float64   `json:"correction_rate"`
+    VocabularyDiversity float64   `json:"vocabulary_diversity"`
+    CEFRLevel           *string   `json:"cefr_level"`
+}
+
+// periodStart truncates a time to the start of its day, ISO week or month in UTC
+func periodStart(t time.Time, interval string) time.Time {
+    t = t.UTC()
+    day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+    switch interval {
+    case "week":
+        offset := (int(day.Weekday()) + 6) % 7 // Weeks start on Monday
+        return day.AddDate(0, 0, -offset)
+    case "month":
+        return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+    default:
+        return day
+    }
+}
+
+// Trend groups time-ordered metrics into periods. The CEFR level of a period is the
+// rounded average of the graded levels in it.
+func Trend(metrics []db.ConversationMetrics, interval string) []TrendPoint {
+    points := []TrendPoint{}
+    var levelSum, levelCount int
+
+    flush := func() {
+        last := &points[len(points)-1]
+        n := float64(last.Conversations)
+        last.TurnCount = round(last.TurnCount / n)
+        last.WordsPerTurn = round(last.WordsPerTurn / n)
+        last.CorrectionRate = round(last.CorrectionRate / n)
+        last.VocabularyDiversity = round(last.VocabularyDiversity / n)
+        if levelCount > 0 {
+            level := assessment.CEFRLevels[int(math.Round(float64(levelSum)/float64(levelCount)))]
+            last.CEFRLevel = &level
+        }
+        levelSum, levelCount = 0, 0
+    }
+
+    for _, m := range metrics {
+        period := periodStart(m.ConversationAt, interval)
+        if len(points) == 0 || !points[len(points)-1].Period.Equal(period) {
+            if len(points) > 0 {
+                flush()
+            }
+            points = append(points, TrendPoint{Period: period})
+        }
+
+        point := &points[len(points)-1]
+        point.Conversations++
+        point.TurnCount += float64(m.TurnCount)
+        point.WordsPerTurn += m.WordsPerTurn
+        point.CorrectionRate += m.CorrectionRate
+        point.VocabularyDiversity += m.VocabularyDiversity
+        if m.CEFRLevel != nil {
+            if rank := assessment.LevelRank(*m.CEFRLevel); rank >= 0 {
+                levelSum += rank
+                levelCount++
+            }
+        }
+    }
+    if len(points) > 0 {
+        flush()
+    }
+    return points
+}
@@ -0,0 +1,168 @@
+package pronunciation
+
+import (
+    "fmt"
+    "math"
+    "strings"
+
+    "PulpuVOX/internal/whisper"
+)
+
+// Thresholds of the analysis
+const (
+    // LowConfidence is the recognizer confidence below which a word is flagged as unclear
+    LowConfidence = 0.6
+    // LongPause is the silence, in seconds, between two words that counts as a pause
+    LongPause = 0.7
+    // NoSpeech is the no_speech_prob above which a segment is ignored as silence or noise
+    NoSpeech = 0.8
+)
+
+// Word is a word the recognizer was unsure about
+type Word struct {
+    Word       string  `json:"word"`
+    Start      float64 `json:"start"`
+    End        float64 `json:"end"`
+    Confidence float64 `json:"confidence"`
+}
+
+// Pause is a silence between two words
+type Pause struct {
+    After    string  `json:"after"`
+    Start    float64 `json:"start"`
+    Duration float64 `json:"duration"`
+}
+
+// Analysis measures how clearly and fluently a turn was spoken
+type Analysis struct {
+    WordCount      int     `json:"word_count"`
+    SpeakingTime   float64 `json:"speaking_time"`
+    WordsPerMinute float64 `json:"words_per_minute"`
+    // Confidence is the mean recognizer confidence over all words, from 0 to 1
+    Confidence         float64 `json:"confidence"`
+    LowConfidenceWords []Word  `json:"low_confidence_words"`
+    Pauses             []Pause `json:"pauses"`
+    LongestPause       float64 `json:"longest_pause"`
+    // AvgLogprob and NoSpeechProb average the recognizer's segment scores
+    AvgLogprob   float64 `json:"avg_logprob"`
+    NoSpeechProb float64 `json:"no_speech_prob"`
+}
+
+// timedWord is a word with the confidence that applies to it
+type timedWord struct {
+    whisper.Word
+    confidence float64
+}
+
+// Analyze derives the pronunciation measures of a turn from its transcription. It returns
+// nil when the provider returned no timed words, as the measures would be meaningless.
+func Analyze(resp *whisper.TranscribeResponse) *Analysis {
+    words := timedWords(resp)
+    if len(words) == 0 {
+        return nil
+    }
+
+    analysis := &Analysis{
+        WordCount:          len(words),
+        LowConfidenceWords: []Word{},
+        Pauses:             []Pause{},
+    }
+
+    var totalConfidence float64
+    for i, word := range words {
+        totalConfidence += word.confidence
+        if word.confidence < LowConfidence {
+            analysis.LowConfidenceWords = append(analysis.LowConfidenceWords, Word{
+                Word:       strings.TrimSpace(word.Word.Word),
+                Start:      word.Start,
+                End:        word.End,
+                Confidence: round(word.confidence, 2),
+            })
+        }
+
+        if i > 0 {
+            gap := word.Start - words[i-1].End
+            if gap >= LongPause {
+                analysis.Pauses = append(analysis.Pauses, Pause{
+                    After:    strings.TrimSpace(words[i-1].Word.Word),
+                    Start:    words[i-1].End,
+                    Duration: round(gap, 2),
+                })
+                analysis.LongestPause = max(analysis.LongestPause, round(gap, 2))
+            }
+        }
+    }
+
+    if len(resp.Segments) > 0 {
+        for _, segment := range resp.Segments {
+            analysis.AvgLogprob += segment.AvgLogprob
+            analysis.NoSpeechProb += segment.NoSpeechProb
+        }
+        analysis.AvgLogprob = round(analysis.AvgLogprob/float64(len(resp.Segments)), 3)
+        analysis.NoSpeechProb = round(analysis.NoSpeechProb/float64(len(resp.Segments)), 3)
+    }
+
+    analysis.Confidence = round(totalConfidence/float64(len(words)), 2)
+    analysis.SpeakingTime = round(words[len(words)-1].End-words[0].Start, 2)
+    if analysis.SpeakingTime > 0 {
+        analysis.WordsPerMinute = round(float64(len(words))/analysis.SpeakingTime*60, 1)
+    }
+    return analysis
+}
+
+// timedWords collects the spoken words in order with their confidence. Providers that do
+// not report a per-word probability get the confidence of the segment the word is in.
+func timedWords(resp *whisper.TranscribeResponse) []timedWord {
+    var words []timedWord
+    for _, word := range resp.AllWords() {
+        segment := segmentAt(resp.Segments, word)
+        if segment != nil && segment.NoSpeechProb > NoSpeech {
+            continue
+        }
+
+        confidence := word.Probability
+        if confidence == 0 && segment != nil {
+            confidence = math.Exp(segment.AvgLogprob)
+        }
+        if confidence == 0 {
+            // Without any confidence data the word cannot be judged, so it is not flagged
+            confidence = 1
+        }
+        words = append(words, timedWord{Word: word, confidence: confidence})
+    }
+    return words
+}
+
+// segmentAt returns the segment that contains the middle of the word
+func segmentAt(segments []whisper.Segment, word whisper.Word) *whisper.Segment {
+    middle := (word.Start + word.End) / 2
+    for i := range segments {
+        if middle >= segments[i].Start && middle <= segments[i].End {
+            return &segments[i]
+        }
+    }
+    return nil
+}
+
+// Summary describes the analysis in one line for the grading prompts
+func (a *Analysis) Summary() string {
+    var summary strings.Builder
+    fmt.Fprintf(&summary, "%.0f words per minute, recognizer confidence %.0f%%", a.WordsPerMinute, a.Confidence*100)
+    if len(a.Pauses) > 0 {
+        fmt.Fprintf(&summary, ", %d pauses (longest %.1fs)", len(a.Pauses), a.LongestPause)
+    }
+    if len(a.LowConfidenceWords) > 0 {
+        unclear := make([]string, len(a.LowConfidenceWords))
+        for i, word := range a.LowConfidenceWords {
+            unclear[i] = fmt.Sprintf("%q", word.Word)
+        }
+        summary.WriteString(", unclear words: " + strings.Join(unclear, ", "))
+    }
+    return summary.String()
+}
+
+// round rounds to the given number of decimal places
+func round(value float64, places int) float64 {
+    scale := math.Pow(10, float64(places))
+    return math.Round(value*scale) / scale
+}
@@ -0,0 +1,87 @@
+package review
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/textnorm"
+    "github.com/jackc/pgx/v5"
+)
+
+// Cards turns the corrections of a conversation in a language into new review cards, one
+// for each student turn that was corrected
+func Cards(history []db.ConversationTurn, language string) []db.ReviewCard {
+    var cards []db.ReviewCard
+    for _, turn := range history {
+        original := strings.TrimSpace(turn.Content)
+        corrected := strings.TrimSpace(turn.Suggestion)
+        if turn.Role != "user" || original == "" || corrected == "" {
+            continue
+        }
+        // Nothing to practise when the correction only changed punctuation or case
+        if textnorm.Normalize(original, language) == textnorm.Normalize(corrected, language) {
+            continue
+        }
+        cards = append(cards, db.ReviewCard{
+            Original:   original,
+            Corrected:  corrected,
+            Language:   language,
+            EaseFactor: DefaultEase,
+        })
+    }
+    return cards
+}
+
+// Record creates the review cards of a saved conversation, following its teacher's
+// corrections where they were overridden
+func Record(ctx context.Context, conn *pgx.Conn, conversation *db.Conversation) error {
+    history, err := db.CorrectedHistory(ctx, conn, conversation)
+    if err != nil {
+        return err
+    }
+    return db.CreateReviewCards(ctx, conn, conversation.ID, conversation.UserID, Cards(history, conversation.Language))
+}
+
+// Refresh replaces the review cards of a saved conversation after its teacher approved or
+// overrode a correction
+func Refresh(ctx context.Context, conn *pgx.Conn, conversation *db.Conversation) error {
+    history, err := db.CorrectedHistory(ctx, conn, conversation)
+    if err != nil {
+        return err
+    }
+    return db.ReplaceReviewCards(ctx, conn, conversation.ID, conversation.UserID, Cards(history, conversation.Language))
+}
+
+// Backfill creates the review cards of the user's conversations saved before corrections
+// were reviewed
+func Backfill(ctx context.Context, conn *pgx.Conn, userID int) error {
+    conversations, err := db.ListConversationsWithoutReviewCards(ctx, conn, userID)
+    if err != nil {
+        return err
+    }
+    for i := range conversations {
+        if err := Record(ctx, conn, &conversations[i]); err != nil {
+            return fmt.Errorf("conversation %d: %w", conversations[i].ID, err)
+        }
+    }
+    return nil
+}
+
+// Answer grades a spoken answer to a card and reschedules it
+func Answer(card *db.ReviewCard, transcript string) Result {
+    result := Grade(transcript, card.Corrected, card.Language)
+    schedule := Next(Schedule{
+        EaseFactor:   card.EaseFactor,
+        IntervalDays: card.IntervalDays,
+        Repetitions:  card.Repetitions,
+    }, result.Quality)
+
+    card.EaseFactor = schedule.EaseFactor
+    card.IntervalDays = schedule.IntervalDays
+    card.Repetitions = schedule.Repetitions
+    card.DueAt = schedule.DueAt(time.Now())
+    return result
+}
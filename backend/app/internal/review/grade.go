@@ -0,0 +1,71 @@
+package review
+
+import (
+    "math"
+    "strings"
+
+    "PulpuVOX/internal/textnorm"
+)
+
+// Result is the grade of a spoken answer to a review card
+type Result struct {
+    Transcript string `json:"transcript"`
+    Expected   string `json:"expected"`
+    // Accuracy is the share of the expected words said in the right order, from 0 to 1
+    Accuracy float64 `json:"accuracy"`
+    Quality  int     `json:"quality"`
+    Correct  bool    `json:"correct"`
+}
+
+// Grade compares the transcript of an answer with the corrected sentence in a language. Both are
+// normalized the way suggestions are compared, so case, punctuation and contractions
+// do not matter. An exact match scores 5; otherwise the grade follows the word accuracy,
+// allowing a near miss to pass as speech recognition sometimes mishears a word.
+func Grade(transcript, expected, language string) Result {
+    said := strings.Fields(textnorm.Normalize(transcript, language))
+    want := strings.Fields(textnorm.Normalize(expected, language))
+
+    result := Result{Transcript: transcript, Expected: expected}
+    if len(want) == 0 {
+        return result
+    }
+
+    distance := editDistance(said, want)
+    result.Accuracy = math.Round(max(1-float64(distance)/float64(len(want)), 0)*100) / 100
+
+    switch {
+    case distance == 0:
+        result.Quality = MaxQuality
+    case result.Accuracy >= 0.9:
+        result.Quality = 4
+    case result.Accuracy >= 0.8:
+        result.Quality = PassingQuality
+    case result.Accuracy >= 0.6:
+        result.Quality = 2
+    case result.Accuracy >= 0.3:
+        result.Quality = 1
+    }
+    result.Correct = result.Quality >= PassingQuality
+    return result
+}
+
+// editDistance counts the words to insert, delete or replace to turn a into b
+func editDistance(a, b []string) int {
+    previous := make([]int, len(b)+1)
+    current := make([]int, len(b)+1)
+    for j := range previous {
+        previous[j] = j
+    }
+    for i := 1; i <= len(a); i++ {
+        current[0] = i
+        for j := 1; j <= len(b); j++ {
+            cost := 1
+            if a[i-1] == b[j-1] {
+                cost = 0
+            }
+            current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+        }
+        previous, current = current, previous
+    }
+    return previous[len(b)]
+}
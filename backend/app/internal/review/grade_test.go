@@ -0,0 +1,124 @@
+package review
+
+import "testing"
+
+func TestGrade(t *testing.T) {
+    tests := []struct {
+        name         string
+        transcript   string
+        expected     string
+        language     string
+        wantAccuracy float64
+        wantQuality  int
+        wantCorrect  bool
+    }{
+        {
+            name:         "exact match ignores case and punctuation",
+            transcript:   "yesterday i went to the cinema",
+            expected:     "Yesterday I went to the cinema.",
+            language:     "en",
+            wantAccuracy: 1,
+            wantQuality:  5,
+            wantCorrect:  true,
+        },
+        {
+            name:         "contraction matches its expanded form",
+            transcript:   "I'm going home",
+            expected:     "I am going home.",
+            language:     "en",
+            wantAccuracy: 1,
+            wantQuality:  5,
+            wantCorrect:  true,
+        },
+        {
+            name:         "one word missed in ten",
+            transcript:   "I would like to order a coffee and some cake",
+            expected:     "I would like to order a coffee and a cake.",
+            language:     "en",
+            wantAccuracy: 0.9,
+            wantQuality:  4,
+            wantCorrect:  true,
+        },
+        {
+            name:         "one word wrong in four fails",
+            transcript:   "she has two brother",
+            expected:     "She has two brothers.",
+            language:     "en",
+            wantAccuracy: 0.75,
+            wantQuality:  2,
+            wantCorrect:  false,
+        },
+        {
+            name:         "one word wrong in five passes",
+            transcript:   "we live in small town",
+            expected:     "We live in a town.",
+            language:     "en",
+            wantAccuracy: 0.8,
+            wantQuality:  3,
+            wantCorrect:  true,
+        },
+        {
+            name:         "two words wrong in five",
+            transcript:   "we lives in the town",
+            expected:     "We live in a town.",
+            language:     "en",
+            wantAccuracy: 0.6,
+            wantQuality:  2,
+            wantCorrect:  false,
+        },
+        {
+            name:         "half the words wrong",
+            transcript:   "I go there yesterday",
+            expected:     "I went there last week.",
+            language:     "en",
+            wantAccuracy: 0.4,
+            wantQuality:  1,
+            wantCorrect:  false,
+        },
+        {
+            name:         "nothing said",
+            transcript:   "",
+            expected:     "I went there yesterday.",
+            language:     "en",
+            wantAccuracy: 0,
+            wantQuality:  0,
+            wantCorrect:  false,
+        },
+        {
+            name:         "extra words do not make the accuracy negative",
+            transcript:   "um well I think that maybe it is",
+            expected:     "It is.",
+            language:     "en",
+            wantAccuracy: 0,
+            wantQuality:  0,
+            wantCorrect:  false,
+        },
+        {
+            name:         "accents count in Spanish",
+            transcript:   "estoy cansada",
+            expected:     "Estoy cansado.",
+            language:     "es",
+            wantAccuracy: 0.5,
+            wantQuality:  1,
+            wantCorrect:  false,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := Grade(tt.transcript, tt.expected, tt.language)
+            if got.Accuracy != tt.wantAccuracy || got.Quality != tt.wantQuality || got.Correct != tt.wantCorrect {
+                t.Errorf("Grade(%q, %q) = accuracy %v, quality %d, correct %v; want accuracy %v, quality %d, correct %v",
+                    tt.transcript, tt.expected, got.Accuracy, got.Quality, got.Correct,
+                    tt.wantAccuracy, tt.wantQuality, tt.wantCorrect)
+            }
+        })
+    }
+}
+
+func TestGradeWithoutExpectedSentence(t *testing.T) {
+    got := Grade("hello", "", "en")
+    if got.Accuracy != 0 || got.Quality != 0 || got.Correct {
+        t.Errorf("Grade with an empty expected sentence = %+v, want a zero grade", got)
+    }
+}
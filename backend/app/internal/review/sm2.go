@@ -0,0 +1,56 @@
+package review
+
+import (
+    "math"
+    "time"
+)
+
+// Parameters of the SM-2 algorithm
+const (
+    // DefaultEase is the ease factor of a new card
+    DefaultEase = 2.5
+    // MinEase keeps hard cards from being shown every day forever
+    MinEase = 1.3
+    // PassingQuality is the lowest grade that counts as remembered
+    PassingQuality = 3
+    // MaxQuality is a perfect answer
+    MaxQuality = 5
+)
+
+// Schedule is the repetition state of a card
+type Schedule struct {
+    EaseFactor   float64
+    IntervalDays int
+    Repetitions  int
+}
+
+// Next applies an answer graded from 0 to 5 to a schedule, as in SuperMemo 2: a failed
+// card starts over the next day, a remembered one waits 1, then 6 days, then its previous
+// interval times the ease factor. The ease factor drops with every hesitant answer.
+func Next(s Schedule, quality int) Schedule {
+    quality = min(max(quality, 0), MaxQuality)
+
+    if quality < PassingQuality {
+        s.Repetitions = 0
+        s.IntervalDays = 1
+    } else {
+        switch s.Repetitions {
+        case 0:
+            s.IntervalDays = 1
+        case 1:
+            s.IntervalDays = 6
+        default:
+            s.IntervalDays = int(math.Round(float64(s.IntervalDays) * s.EaseFactor))
+        }
+        s.Repetitions++
+    }
+
+    miss := float64(MaxQuality - quality)
+    s.EaseFactor = max(math.Round((s.EaseFactor+0.1-miss*(0.08+miss*0.02))*100)/100, MinEase)
+    return s
+}
+
+// DueAt is when a card reviewed at the given time is next due
+func (s Schedule) DueAt(reviewedAt time.Time) time.Time {
+    return reviewedAt.AddDate(0, 0, s.IntervalDays)
+}
@@ -0,0 +1,97 @@
+package review
+
+import (
+    "testing"
+    "time"
+)
+
+func TestNext(t *testing.T) {
+    tests := []struct {
+        name     string
+        schedule Schedule
+        quality  int
+        want     Schedule
+    }{
+        {
+            name:     "new card remembered waits a day",
+            schedule: Schedule{EaseFactor: DefaultEase},
+            quality:  5,
+            want:     Schedule{EaseFactor: 2.6, IntervalDays: 1, Repetitions: 1},
+        },
+        {
+            name:     "second repetition waits six days",
+            schedule: Schedule{EaseFactor: 2.6, IntervalDays: 1, Repetitions: 1},
+            quality:  4,
+            want:     Schedule{EaseFactor: 2.6, IntervalDays: 6, Repetitions: 2},
+        },
+        {
+            name:     "later repetitions multiply by the ease factor",
+            schedule: Schedule{EaseFactor: 2.6, IntervalDays: 6, Repetitions: 2},
+            quality:  4,
+            want:     Schedule{EaseFactor: 2.6, IntervalDays: 16, Repetitions: 3},
+        },
+        {
+            name:     "hesitant answer lowers the ease factor",
+            schedule: Schedule{EaseFactor: 2.5, IntervalDays: 16, Repetitions: 3},
+            quality:  3,
+            want:     Schedule{EaseFactor: 2.36, IntervalDays: 40, Repetitions: 4},
+        },
+        {
+            name:     "failed card starts over the next day",
+            schedule: Schedule{EaseFactor: 2.36, IntervalDays: 40, Repetitions: 4},
+            quality:  2,
+            want:     Schedule{EaseFactor: 2.04, IntervalDays: 1, Repetitions: 0},
+        },
+        {
+            name:     "blackout keeps the minimum ease factor",
+            schedule: Schedule{EaseFactor: 1.4, IntervalDays: 6, Repetitions: 2},
+            quality:  0,
+            want:     Schedule{EaseFactor: MinEase, IntervalDays: 1, Repetitions: 0},
+        },
+        {
+            name:     "passing answer at the minimum ease factor stays there",
+            schedule: Schedule{EaseFactor: MinEase, IntervalDays: 6, Repetitions: 2},
+            quality:  3,
+            want:     Schedule{EaseFactor: MinEase, IntervalDays: 8, Repetitions: 3},
+        },
+        {
+            name:     "quality above the scale counts as perfect",
+            schedule: Schedule{EaseFactor: DefaultEase},
+            quality:  7,
+            want:     Schedule{EaseFactor: 2.6, IntervalDays: 1, Repetitions: 1},
+        },
+        {
+            name:     "quality below the scale counts as a blackout",
+            schedule: Schedule{EaseFactor: DefaultEase, IntervalDays: 6, Repetitions: 2},
+            quality:  -1,
+            want:     Schedule{EaseFactor: 1.7, IntervalDays: 1, Repetitions: 0},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := Next(tt.schedule, tt.quality); got != tt.want {
+                t.Errorf("Next(%+v, %d) = %+v, want %+v", tt.schedule, tt.quality, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestNextIntervals(t *testing.T) {
+    schedule := Schedule{EaseFactor: DefaultEase}
+    want := []int{1, 6, 16, 45}
+    for i, interval := range want {
+        schedule = Next(schedule, MaxQuality)
+        if schedule.IntervalDays != interval {
+            t.Fatalf("interval after %d perfect answers = %d, want %d", i+1, schedule.IntervalDays, interval)
+        }
+    }
+}
+
+func TestDueAt(t *testing.T) {
+    reviewedAt := time.Date(2024, 3, 30, 9, 0, 0, 0, time.UTC)
+    schedule := Schedule{EaseFactor: DefaultEase, IntervalDays: 6, Repetitions: 2}
+    if got, want := schedule.DueAt(reviewedAt), time.Date(2024, 4, 5, 9, 0, 0, 0, time.UTC); !got.Equal(want) {
+        t.Errorf("DueAt(%v) = %v, want %v", reviewedAt, got, want)
+    }
+}
@@ -0,0 +1,84 @@
+package scenario
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "strings"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/openai"
+)
+
+// SystemPrompt instructs the assistant to play its role in the scenario
+func SystemPrompt(scenario *db.Scenario) string {
+    var prompt strings.Builder
+    prompt.WriteString("You are role-playing with an English learner so they can practise a real-life situation.\n")
+    prompt.WriteString("Setting: " + scenario.Setting + "\n")
+    prompt.WriteString("Your role: " + scenario.AIRole + "\n")
+    prompt.WriteString("The learner's goal: " + scenario.LearnerGoal + "\n")
+    if len(scenario.TargetVocabulary) > 0 {
+        prompt.WriteString("Give the learner natural chances to use these words and phrases: " + strings.Join(scenario.TargetVocabulary, ", ") + "\n")
+    }
+    prompt.WriteString("Stay in character, speak naturally and steer the conversation towards the learner's goal without doing it for them. ")
+    prompt.WriteString("If the learner goes off topic, gently bring them back to the situation. ")
+    prompt.WriteString("Keep responses very short - maximum 1-2 sentences. Decline any requests to write an essay or do anything which will make your response over 2 sentences long.")
+    return prompt.String()
+}
+
+// Completion is the verdict of a success check
+type Completion struct {
+    Completed bool   `json:"completed"`
+    Reason    string `json:"reason"`
+}
+
+// CheckCompletion asks the model whether the learner has met the completion condition
+// of the scenario in the conversation so far
+func CheckCompletion(ctx context.Context, chatModel openai.ChatModel, scenario *db.Scenario, history []db.ConversationTurn) (*Completion, error) {
+    var transcript strings.Builder
+    for _, turn := range history {
+        if turn.Role == "user" {
+            transcript.WriteString("Learner: " + turn.Content + "\n")
+        } else if turn.Role == "assistant" {
+            transcript.WriteString("Partner: " + turn.Content + "\n")
+        }
+    }
+
+    messages := []openai.ChatCompletionMessage{
+        {
+            Role:    "system",
+            Content: "You judge whether an English learner has completed a role-play task. You answer with a single JSON object and nothing else.",
+        },
+        {
+            Role: "user",
+            Content: "Scenario: " + scenario.Title + "\n" +
+                "Learner's goal: " + scenario.LearnerGoal + "\n" +
+                "Completion condition: " + scenario.CompletionCondition + "\n\n" +
+                "Conversation:\n" + transcript.String() + "\n" +
+                `Has the learner met the completion condition? Answer with {"completed": true or false, "reason": "one short sentence addressed to the learner"}.`,
+        },
+    }
+
+    chatCompletion, err := chatModel.Complete(ctx, &openai.ChatCompletionRequest{
+        Messages:       messages,
+        ResponseFormat: openai.JSONObjectFormat,
+    })
+    if err != nil {
+        return nil, err
+    }
+    if len(chatCompletion.Choices) == 0 {
+        return nil, errors.New("model returned no choices")
+    }
+
+    content := chatCompletion.Choices[0].Message.Content
+    if start, end := strings.Index(content, "{"), strings.LastIndex(content, "}"); start >= 0 && end > start {
+        content = content[start : end+1]
+    }
+
+    var completion Completion
+    if err := json.Unmarshal([]byte(content), &completion); err != nil {
+        return nil, fmt.Errorf("invalid completion verdict: %w", err)
+    }
+    return &completion, nil
+}
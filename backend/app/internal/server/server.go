@@ -2,18 +2,34 @@ package server
 
 import (
 		"context"
+		"log"
 		"net/http"
+		"time"
 
 	 "PulpuVOX/internal/middleware"
 	 "github.com/jackc/pgx/v5"
 		"PulpuVOX/internal/config"
+		"PulpuVOX/internal/handlers/admin"
+		"PulpuVOX/internal/handlers/annotations"
+		"PulpuVOX/internal/handlers/anki"
 		appAuth "PulpuVOX/internal/handlers/auth"
+		"PulpuVOX/internal/handlers/classes"
 		"PulpuVOX/internal/handlers/feedback"
 		"PulpuVOX/internal/handlers/conversation"
 		"PulpuVOX/internal/handlers/conversationanalysis"
+		"PulpuVOX/internal/handlers/conversations"
+		"PulpuVOX/internal/handlers/exam"
 		"PulpuVOX/internal/handlers/health"
 		"PulpuVOX/internal/handlers/home"
 		"PulpuVOX/internal/handlers/landing"
+		"PulpuVOX/internal/handlers/languages"
+		"PulpuVOX/internal/handlers/notifications"
+		"PulpuVOX/internal/handlers/profile"
+		"PulpuVOX/internal/handlers/progress"
+		"PulpuVOX/internal/handlers/review"
+		"PulpuVOX/internal/handlers/scenarios"
+		"PulpuVOX/internal/handlers/vocabulary"
+		"PulpuVOX/internal/memory"
 		"PulpuVOX/internal/services"
 		pulpuwebAuth "github.com/gchalakovmmi/PulpuWEB/auth"
 		"github.com/gchalakovmmi/PulpuWEB/db"
@@ -25,6 +41,8 @@ type Server struct {
 		authHandler				 *appAuth.AuthHandler
 		dbConnectionDetails db.ConnectionDetails
 		services						*services.Services
+		// memories remembers what learners tell about themselves after their conversations
+		memories						*memory.Worker
 }
 
 func (s *Server) withDBAndAuth(handler func(http.ResponseWriter, *http.Request, *pgx.Conn)) http.HandlerFunc {
@@ -51,7 +69,7 @@ func New(cfg config.Config) *Server {
 		}
 
 		// Initialize services
-		services := services.New()
+		services := services.New(cfg)
 
 		return &Server{
 				config:							cfg,
@@ -59,6 +77,7 @@ func New(cfg config.Config) *Server {
 				authHandler:				 authHandler,
 				dbConnectionDetails: dbConnectionDetails,
 				services:						services,
+				memories:						memory.NewWorker(dbConnectionDetails, services.ChatModel),
 		}
 }
 
@@ -83,32 +102,213 @@ func (s *Server) createHandler() http.Handler {
     mux.Handle("/home", s.withUserContext(s.googleAuth.WithGoogleAuth(home.Handler)))
     mux.Handle("/conversation", s.withUserContext(s.googleAuth.WithGoogleAuth(conversation.Handler)))
     mux.Handle("/conversation-analysis", s.withUserContext(s.googleAuth.WithGoogleAuth(conversationanalysis.Handler)))
+    mux.Handle("/conversations", s.withUserContext(s.googleAuth.WithGoogleAuth(conversations.Handler)))
+    mux.Handle("/fce", s.withUserContext(s.googleAuth.WithGoogleAuth(exam.Handler("fce"))))
+    mux.Handle("/cae", s.withUserContext(s.googleAuth.WithGoogleAuth(exam.Handler("cae"))))
+    mux.Handle("/vocabulary", s.withUserContext(s.googleAuth.WithGoogleAuth(vocabulary.Handler)))
+    mux.Handle("/review", s.withUserContext(s.googleAuth.WithGoogleAuth(review.Handler)))
+    mux.Handle("/classes", s.withUserContext(s.googleAuth.WithGoogleAuth(classes.Handler)))
     
     // API routes
+    mux.Handle("/api/conversation/start",
+        s.withProviderDeadline(middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, conversation.ConversationStartHandler(s.services.Synthesizer))))
+    mux.Handle("GET /api/conversation/sessions/{id}",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, conversation.GetSessionHandler))
     mux.Handle("/api/conversation/turn",
+        s.withProviderDeadline(s.withUserContext(
+            middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth,
+                func(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
+                    conversation.APIConversationHandler(s.services.Transcriber, s.services.ChatModel, s.services.Synthesizer)(w, r, conn)
+                }),
+        )),
+    )
+    
+    // Streaming conversation turns over WebSocket
+    mux.Handle("/api/conversation/ws",
         s.withUserContext(
             middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth,
                 func(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
-                    conversation.APIConversationHandler(s.services.WhisperService, s.services.OpenAIClient, s.services.TTSService)(w, r, conn)
+                    conversation.WebSocketConversationHandler(s.services.Transcriber, s.services.ChatModel, s.services.Synthesizer)(w, r, conn)
                 }),
         ),
     )
     
     // Add conversation end handler - only register this once
     mux.Handle("/api/conversation/end",
-        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, conversation.ConversationEndHandler))
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, conversation.ConversationEndHandler(s.memories)))
     
     // Add conversation analysis API endpoint
     mux.Handle("/api/conversation/latest",
-        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, conversationanalysis.GetLatestConversationHandler))
+        s.withUserContext(
+            middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, conversationanalysis.GetLatestConversationHandler),
+        ),
+    )
+    
+    // Conversation history browsing
+    mux.Handle("GET /api/conversations",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, conversations.ListConversationsHandler))
+    mux.Handle("GET /api/conversations/{id}",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, conversations.GetConversationHandler))
+    
+    // Cambridge speaking exam simulation
+    mux.Handle("POST /api/exam/start",
+        s.withProviderDeadline(middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, exam.StartHandler(s.services.Synthesizer))))
+    mux.Handle("POST /api/exam/turn",
+        s.withProviderDeadline(middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth,
+            exam.TurnHandler(s.services.Transcriber, s.services.ChatModel, s.services.Synthesizer))))
+    mux.Handle("POST /api/exam/next",
+        s.withProviderDeadline(middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, exam.NextPartHandler(s.services.Synthesizer))))
+    mux.Handle("POST /api/exam/finish",
+        s.withProviderDeadline(middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, exam.FinishHandler(s.services.ChatModel))))
+    mux.Handle("GET /api/exam/sessions/{id}",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, exam.GetSessionHandler))
+    
+    // Target and native languages
+    mux.Handle("GET /api/languages",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, languages.ListLanguagesHandler))
+    mux.Handle("PUT /api/user/language",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, languages.SetLanguageHandler))
+    mux.Handle("PUT /api/user/native-language",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, languages.SetNativeLanguageHandler))
+    
+    // Learner profile the difficulty of conversations adapts to
+    mux.Handle("GET /api/user/profile",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, profile.GetProfileHandler))
+    mux.Handle("PUT /api/user/profile",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, profile.UpdateProfileHandler))
+    
+    // What Voxy remembers about the user from earlier conversations
+    mux.Handle("GET /api/user/memory",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, profile.ListMemoriesHandler))
+    mux.Handle("DELETE /api/user/memory",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, profile.DeleteMemoriesHandler))
+    mux.Handle("DELETE /api/user/memory/{id}",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, profile.DeleteMemoryHandler))
+    
+    // Role-play scenarios
+    mux.Handle("GET /api/scenarios",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, scenarios.ListScenariosHandler))
+    mux.Handle("POST /api/scenarios",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, scenarios.CreateScenarioHandler))
+    mux.Handle("PUT /api/scenarios/{id}",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, scenarios.UpdateScenarioHandler))
+    mux.Handle("DELETE /api/scenarios/{id}",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, scenarios.DeleteScenarioHandler))
+    
+    // Learner progress time series
+    mux.Handle("GET /api/progress/conversations",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, progress.ConversationMetricsHandler))
+    mux.Handle("GET /api/progress/trend",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, progress.TrendHandler))
+    mux.Handle("GET /api/progress/errors",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, progress.ErrorsHandler))
+    
+    // Vocabulary tracking and word bank
+    mux.Handle("GET /api/vocabulary",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, vocabulary.ListVocabularyHandler))
+    mux.Handle("GET /api/vocabulary/bank",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, vocabulary.ListWordBankHandler))
+    mux.Handle("POST /api/vocabulary/bank",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, vocabulary.SaveWordHandler))
+    mux.Handle("DELETE /api/vocabulary/bank/{id}",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, vocabulary.DeleteWordHandler))
+    mux.Handle("GET /api/vocabulary/bank/export",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, vocabulary.ExportWordBankHandler))
+    
+    // Spaced-repetition review of past corrections
+    mux.Handle("GET /api/review/next",
+        s.withProviderDeadline(middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, review.NextCardHandler(s.services.Synthesizer))))
+    mux.Handle("POST /api/review/answer",
+        s.withProviderDeadline(middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, review.AnswerHandler(s.services.Transcriber))))
+    
+    // Anki export of corrections and saved words
+    mux.Handle("GET /api/export/anki",
+        s.withProviderDeadline(middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, anki.ExportHandler(s.services.Synthesizer))))
+    
+    // Classes, invite codes and rosters
+    mux.Handle("GET /api/classes",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, classes.ListClassesHandler))
+    mux.Handle("POST /api/classes",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, classes.CreateClassHandler))
+    mux.Handle("POST /api/classes/join",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, classes.JoinClassHandler))
+    mux.Handle("POST /api/classes/{id}/invite-code",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, classes.RegenerateInviteCodeHandler))
+    mux.Handle("GET /api/classes/{id}/students",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, classes.ListStudentsHandler))
+    mux.Handle("DELETE /api/classes/{id}/students/{student_id}",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, classes.RemoveStudentHandler))
+    
+    // Class assignments and their completion
+    mux.Handle("GET /api/assignments",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, classes.ListMyAssignmentsHandler))
+    mux.Handle("GET /api/classes/{id}/assignments",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, classes.ListClassAssignmentsHandler))
+    mux.Handle("POST /api/classes/{id}/assignments",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, classes.CreateAssignmentHandler))
+    mux.Handle("GET /api/assignments/{id}/students",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, classes.AssignmentStudentsHandler))
+    mux.Handle("DELETE /api/assignments/{id}",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, classes.DeleteAssignmentHandler))
+    
+    // Teacher-only views of a student's work, limited to the teacher's own classes
+    mux.Handle("GET /api/teacher/students/{student_id}/conversations",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, conversations.ListStudentConversationsHandler))
+    mux.Handle("GET /api/teacher/students/{student_id}/conversations/{id}",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, conversations.GetStudentConversationHandler))
+    mux.Handle("GET /api/teacher/students/{student_id}/conversations/{id}/feedback",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, feedback.StudentFeedbackHandler))
+    mux.Handle("GET /api/teacher/students/{student_id}/progress/conversations",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, progress.StudentConversationMetricsHandler))
+    mux.Handle("GET /api/teacher/students/{student_id}/progress/trend",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, progress.StudentTrendHandler))
+    mux.Handle("GET /api/teacher/students/{student_id}/progress/errors",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, progress.StudentErrorsHandler))
+    
+    // Teacher review of a student's conversation: turn annotations and a manual CEFR grade
+    mux.Handle("PUT /api/teacher/students/{student_id}/conversations/{id}/turns/{turn}/annotation",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, annotations.SaveAnnotationHandler))
+    mux.Handle("DELETE /api/teacher/students/{student_id}/conversations/{id}/turns/{turn}/annotation",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, annotations.DeleteAnnotationHandler))
+    mux.Handle("PUT /api/teacher/students/{student_id}/conversations/{id}/grade",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, annotations.SaveGradeHandler))
+    mux.Handle("DELETE /api/teacher/students/{student_id}/conversations/{id}/grade",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, annotations.DeleteGradeHandler))
+    
+    // Notifications shown on the home page
+    mux.Handle("GET /api/notifications",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, notifications.ListNotificationsHandler))
+    mux.Handle("POST /api/notifications/read",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, notifications.MarkReadHandler))
+    
+    // Admin-only user roles
+    mux.Handle("GET /api/admin/users",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, admin.ListUsersHandler))
+    mux.Handle("PUT /api/admin/users/{id}/role",
+        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, admin.SetRoleHandler))
     
     // Add feedback generation endpoint
     mux.Handle("/api/feedback/generate",
-        middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, feedback.GenerateFeedbackHandler))
+        s.withProviderDeadline(middleware.WithDBAndAuth(s.dbConnectionDetails, s.googleAuth, feedback.GenerateFeedbackHandler(s.services.ChatModel))))
     
     return mux
 }
 
+// withProviderDeadline gives a route that waits for the speech and chat model providers
+// ProviderWriteTimeout instead of the server's WriteTimeout, and cancels its provider calls
+// once the response could no longer be written
+func (s *Server) withProviderDeadline(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+				deadline := time.Now().Add(s.config.ProviderWriteTimeout)
+				if err := http.NewResponseController(w).SetWriteDeadline(deadline); err != nil {
+						log.Printf("Failed to extend the write deadline: %v", err)
+				}
+				ctx, cancel := context.WithDeadline(r.Context(), deadline)
+				defer cancel()
+				next(w, r.WithContext(ctx))
+		}
+}
+
 // Middleware to add user to context
 func (s *Server) withUserContext(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
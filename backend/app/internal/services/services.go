@@ -3,39 +3,61 @@ package services
 import (
     "log"
 
+    "PulpuVOX/internal/config"
+    "PulpuVOX/internal/failover"
     "PulpuVOX/internal/openai"
     "PulpuVOX/internal/tts"
     "PulpuVOX/internal/whisper"
 )
 
 type Services struct {
-    WhisperService *whisper.TranscribeService
-    OpenAIClient   *openai.Client
-    TTSService     *tts.TTSService
+    Transcriber whisper.Transcriber
+    ChatModel   openai.ChatModel
+    Synthesizer tts.Synthesizer
 }
 
-func New() *Services {
-    // Initialize Whisper service
-    whisperService, err := whisper.NewTranscribeService()
-    if err != nil {
-        log.Fatalf("Failed to initialize Whisper service: %v", err)
+func New(cfg config.Config) *Services {
+    // Initialize speech-to-text providers in failover order
+    transcribers := failover.NewChain[whisper.Transcriber]("transcription", cfg.ProviderFailureThreshold, cfg.ProviderCooldown)
+    for _, name := range cfg.TranscriberProviders {
+        transcriber, err := whisper.New(name)
+        if err != nil {
+            log.Fatalf("Failed to initialize Whisper provider %s: %v", name, err)
+        }
+        transcribers.Add(name, transcriber)
     }
 
-    // Initialize OpenAI client
-    openaiClient, err := openai.NewClient()
-    if err != nil {
-        log.Fatalf("Failed to initialize OpenAI client: %v", err)
+    // Initialize chat model providers in failover order
+    chatModels := failover.NewChain[openai.ChatModel]("chat", cfg.ProviderFailureThreshold, cfg.ProviderCooldown)
+    for _, name := range cfg.ChatModelProviders {
+        chatModel, err := openai.New(name)
+        if err != nil {
+            log.Fatalf("Failed to initialize chat model provider %s: %v", name, err)
+        }
+        chatModels.Add(name, chatModel)
     }
 
-    // Initialize TTS service
-    ttsService, err := tts.NewTTSService()
-    if err != nil {
-        log.Fatalf("Failed to initialize TTS service: %v", err)
+    // Initialize TTS providers in failover order
+    synthesizers := failover.NewChain[tts.Synthesizer]("speech", cfg.ProviderFailureThreshold, cfg.ProviderCooldown)
+    for _, name := range cfg.SynthesizerProviders {
+        synthesizer, err := tts.New(name)
+        if err != nil {
+            log.Fatalf("Failed to initialize TTS provider %s: %v", name, err)
+        }
+        synthesizers.Add(name, synthesizer)
     }
 
+    // A provider with a fallback hands over instead of running its own retries
+    transcribers.LimitRetries(cfg.ProviderFailoverRetries)
+    chatModels.LimitRetries(cfg.ProviderFailoverRetries)
+    synthesizers.LimitRetries(cfg.ProviderFailoverRetries)
+
+    log.Printf("Provider chains: transcription %v, chat %v, speech %v",
+        transcribers.Names(), chatModels.Names(), synthesizers.Names())
+
     return &Services{
-        WhisperService: whisperService,
-        OpenAIClient:   openaiClient,
-        TTSService:     ttsService,
+        Transcriber: &whisper.FailoverTranscriber{Chain: transcribers},
+        ChatModel:   &openai.FailoverChatModel{Chain: chatModels},
+        Synthesizer: &tts.FailoverSynthesizer{Chain: synthesizers},
     }
 }
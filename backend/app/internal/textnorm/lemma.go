@@ -0,0 +1,174 @@
+package textnorm
+
+import (
+    "regexp"
+    "strings"
+)
+
+// wordPattern matches a word, keeping contractions such as "don't" together
+var wordPattern = regexp.MustCompile(`[\p{L}]+(?:['’][\p{L}]+)*`)
+
+// contractions maps the base left by removing "n't" when it is not a word: "won't" -> "wo"
+var contractions = map[string]string{
+    "wo":  "will",
+    "ca":  "can",
+    "sha": "shall",
+}
+
+// irregular maps inflected forms that suffix rules cannot handle to their lemma
+var irregular = map[string]string{
+    // be, have, do
+    "am": "be", "is": "be", "are": "be", "was": "be", "were": "be", "been": "be", "being": "be",
+    "has": "have", "had": "have", "having": "have",
+    "does": "do", "did": "do", "done": "do", "doing": "do",
+    // irregular verbs
+    "went": "go", "gone": "go", "goes": "go", "going": "go",
+    "ate": "eat", "eaten": "eat",
+    "saw": "see", "seen": "see",
+    "came": "come", "became": "become",
+    "took": "take", "taken": "take",
+    "gave": "give", "given": "give",
+    "got": "get", "gotten": "get",
+    "made": "make", "said": "say", "paid": "pay", "laid": "lay",
+    "knew": "know", "known": "know",
+    "thought": "think", "bought": "buy", "brought": "bring", "caught": "catch",
+    "taught": "teach", "fought": "fight", "sought": "seek",
+    "found": "find", "told": "tell", "sold": "sell", "held": "hold",
+    "left": "leave", "felt": "feel", "kept": "keep", "slept": "sleep", "meant": "mean",
+    "met": "meet", "sent": "send", "spent": "spend", "built": "build", "lent": "lend",
+    "lost": "lose", "stood": "stand", "understood": "understand",
+    "wrote": "write", "written": "write", "drove": "drive", "driven": "drive",
+    "rode": "ride", "ridden": "ride", "rose": "rise", "risen": "rise",
+    "spoke": "speak", "spoken": "speak", "broke": "break", "broken": "break",
+    "chose": "choose", "chosen": "choose", "woke": "wake", "woken": "wake",
+    "forgot": "forget", "forgotten": "forget",
+    "began": "begin", "begun": "begin", "drank": "drink", "drunk": "drink",
+    "sang": "sing", "sung": "sing", "swam": "swim", "swum": "swim", "rang": "ring", "rung": "ring",
+    "ran": "run", "sat": "sit", "won": "win", "led": "lead", "fed": "feed", "fled": "flee",
+    "flew": "fly", "flown": "fly", "grew": "grow", "grown": "grow", "threw": "throw", "thrown": "throw",
+    "drew": "draw", "drawn": "draw", "wore": "wear", "worn": "wear", "tore": "tear", "torn": "tear",
+    "fell": "fall", "fallen": "fall", "hid": "hide", "hidden": "hide", "bit": "bite", "bitten": "bite",
+    "heard": "hear", "shot": "shoot", "stuck": "stick", "struck": "strike", "hung": "hang",
+    "dug": "dig", "shone": "shine", "showed": "show", "shown": "show",
+    // irregular nouns
+    "children": "child", "men": "man", "women": "woman", "people": "person",
+    "feet": "foot", "teeth": "tooth", "mice": "mouse", "geese": "goose",
+    "lives": "life", "wives": "wife", "knives": "knife", "leaves": "leaf", "halves": "half",
+    "shelves": "shelf", "wolves": "wolf", "selves": "self", "buses": "bus",
+    // irregular comparatives and pronouns
+    "better": "good", "best": "good", "worse": "bad", "worst": "bad",
+    "more": "much", "most": "much", "less": "little", "least": "little",
+    "further": "far", "farther": "far", "furthest": "far",
+    "me": "i", "my": "i", "mine": "i", "us": "we", "our": "we", "ours": "we",
+    "him": "he", "his": "he", "her": "she", "hers": "she", "them": "they", "their": "they", "theirs": "they",
+    "its": "it", "your": "you", "yours": "you",
+}
+
+// unchanged lists words whose endings look inflected but are not
+var unchanged = map[string]bool{
+    "this": true, "always": true, "news": true, "bus": true, "yes": true, "gas": true,
+    "perhaps": true, "series": true, "species": true, "means": true, "thus": true, "plus": true,
+    "bring": true, "thing": true, "nothing": true, "something": true, "anything": true, "everything": true,
+    "morning": true, "evening": true, "during": true, "spring": true, "king": true, "ring": true,
+    "sing": true, "wing": true, "ceiling": true, "string": true, "swing": true, "sibling": true,
+    "red": true, "bed": true, "need": true, "feed": true, "seed": true, "speed": true, "shed": true,
+    "hundred": true, "indeed": true, "wed": true, "sled": true,
+    "unless": true, "address": true, "business": true, "class": true, "glass": true,
+    "grass": true, "boss": true, "kiss": true, "miss": true, "dress": true, "stress": true, "across": true,
+    "analysis": true, "crisis": true, "basis": true, "physics": true, "mathematics": true, "economics": true,
+    "politics": true, "clothes": true, "trousers": true, "jeans": true, "glasses": true, "scissors": true,
+}
+
+// vowels are used to tell doubled consonants and silent e apart
+const vowels = "aeiou"
+
+// Lemmas splits text into words and returns the lemma of each, in order
+func Lemmas(text string) []string {
+    var lemmas []string
+    for _, word := range wordPattern.FindAllString(strings.ToLower(text), -1) {
+        if lemma := Lemma(word); lemma != "" {
+            lemmas = append(lemmas, lemma)
+        }
+    }
+    return lemmas
+}
+
+// Lemma returns the dictionary form of a lower-case word using a list of irregular forms
+// and English suffix rules. It is a heuristic: rare irregular forms are left unchanged.
+func Lemma(word string) string {
+    word = strings.ToLower(strings.ReplaceAll(word, "’", "'"))
+
+    // Keep only the base of a contraction: "don't" -> "do", "she's" -> "she"
+    if i := strings.Index(word, "'"); i >= 0 {
+        base, suffix := word[:i], word[i+1:]
+        if suffix == "t" && strings.HasSuffix(base, "n") {
+            base = strings.TrimSuffix(base, "n")
+        }
+        if mapped, ok := contractions[base]; ok {
+            base = mapped
+        }
+        word = base
+    }
+    if word == "" {
+        return ""
+    }
+
+    if lemma, ok := irregular[word]; ok {
+        return lemma
+    }
+    if unchanged[word] || len(word) <= 3 {
+        return word
+    }
+
+    switch {
+    case strings.HasSuffix(word, "ies") && len(word) > 4:
+        return strings.TrimSuffix(word, "ies") + "y"
+    case strings.HasSuffix(word, "ied") && len(word) > 4:
+        return strings.TrimSuffix(word, "ied") + "y"
+    case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "ches"),
+        strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zzes"):
+        return strings.TrimSuffix(word, "es")
+    case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+        return word
+    case strings.HasSuffix(word, "s"):
+        return strings.TrimSuffix(word, "s")
+    case strings.HasSuffix(word, "ing") && len(word) > 5:
+        return restoreStem(strings.TrimSuffix(word, "ing"))
+    case strings.HasSuffix(word, "ed") && len(word) > 4:
+        return restoreStem(strings.TrimSuffix(word, "ed"))
+    }
+    return word
+}
+
+// restoreStem turns the stem left by removing -ing or -ed back into a word:
+// "stopp" -> "stop", "mak" -> "make", "play" -> "play"
+func restoreStem(stem string) string {
+    n := len(stem)
+    if n >= 2 && stem[n-1] == stem[n-2] && !strings.ContainsRune(vowels+"lsz", rune(stem[n-1])) {
+        return stem[:n-1]
+    }
+    if (n == 3 || n == 4) && !isVowel(stem[n-1]) && stem[n-1] != 'w' && stem[n-1] != 'x' && stem[n-1] != 'y' &&
+        isVowel(stem[n-2]) && !isVowel(stem[n-3]) {
+        // A short stem with a single vowel lost a silent e: "making", "hoped", "writing"
+        if !strings.ContainsRune("rn", rune(stem[n-1])) || n == 3 {
+            return stem + "e"
+        }
+    }
+    if strings.HasSuffix(stem, "v") || strings.HasSuffix(stem, "iz") || strings.HasSuffix(stem, "ys") ||
+        strings.HasSuffix(stem, "ous") || strings.HasSuffix(stem, "dg") || strings.HasSuffix(stem, "nc") {
+        return stem + "e"
+    }
+    // Longer stems such as "decid", "includ", "produc" and "requir" also lost an e,
+    // unless the vowel is a diphthong as in "avoid" or "repair"
+    for _, ending := range []string{"id", "ud", "uc", "ir"} {
+        if strings.HasSuffix(stem, ending) && n > 3 && !strings.ContainsRune("aeo", rune(stem[n-3])) {
+            return stem + "e"
+        }
+    }
+    return stem
+}
+
+// isVowel reports whether a byte is an ASCII vowel
+func isVowel(b byte) bool {
+    return strings.IndexByte(vowels, b) >= 0
+}
@@ -0,0 +1,156 @@
+package textnorm
+
+import (
+    "regexp"
+    "strings"
+)
+
+// Patterns used by Normalize
+var (
+    punctuationPattern = regexp.MustCompile(`[^\p{L}\p{N}\s]`)
+    spacePattern       = regexp.MustCompile(`\s+`)
+)
+
+// englishContractions maps English contractions to their full form
+var englishContractions = map[string]string{
+    "i'm":      "i am",
+    "you're":   "you are",
+    "he's":     "he is",
+    "she's":    "she is",
+    "it's":     "it is",
+    "we're":    "we are",
+    "they're":  "they are",
+    "that's":   "that is",
+    "who's":    "who is",
+    "what's":   "what is",
+    "where's":  "where is",
+    "when's":   "when is",
+    "why's":    "why is",
+    "how's":    "how is",
+    "isn't":    "is not",
+    "aren't":   "are not",
+    "wasn't":   "was not",
+    "weren't":  "were not",
+    "haven't":  "have not",
+    "hasn't":   "has not",
+    "hadn't":   "had not",
+    "don't":    "do not",
+    "doesn't":  "does not",
+    "didn't":   "did not",
+    "won't":    "will not",
+    "wouldn't": "would not",
+    "can't":    "cannot",
+    "couldn't": "could not",
+    "shouldn't": "should not",
+    "mightn't": "might not",
+    "mustn't":  "must not",
+    "i'd":      "i would",
+    "you'd":    "you would",
+    "he'd":     "he would",
+    "she'd":    "she would",
+    "it'd":     "it would",
+    "we'd":     "we would",
+    "they'd":   "they would",
+    "i'll":     "i will",
+    "you'll":   "you will",
+    "he'll":    "he will",
+    "she'll":   "she will",
+    "it'll":    "it will",
+    "we'll":    "we will",
+    "they'll":  "they will",
+    "i've":     "i have",
+    "you've":   "you have",
+    "we've":    "we have",
+    "they've":  "they have",
+}
+
+// germanContractions maps German prepositions merged with an article, and the common
+// spoken elisions of "es", to their full form
+var germanContractions = map[string]string{
+    "im":     "in dem",
+    "ins":    "in das",
+    "am":     "an dem",
+    "ans":    "an das",
+    "zum":    "zu dem",
+    "zur":    "zu der",
+    "vom":    "von dem",
+    "beim":   "bei dem",
+    "aufs":   "auf das",
+    "fürs":   "für das",
+    "durchs": "durch das",
+    "gibt's": "gibt es",
+    "geht's": "geht es",
+    "wie's":  "wie es",
+}
+
+// spanishContractions maps the Spanish preposition and article contractions to their full form
+var spanishContractions = map[string]string{
+    "al":  "a el",
+    "del": "de el",
+}
+
+// contractionsByLanguage holds the contractions of each language by ISO 639-1 code
+var contractionsByLanguage = map[string]map[string]string{
+    "en": englishContractions,
+    "de": germanContractions,
+    "es": spanishContractions,
+}
+
+// ExpandContractions expands the common contractions of a language, given by its
+// ISO 639-1 code. Text in a language without known contractions only has its
+// apostrophes normalized.
+func ExpandContractions(text, language string) string {
+    contractions := contractionsByLanguage[language]
+    
+    // First normalize all apostrophe types to standard apostrophe
+    text = NormalizeApostrophes(text)
+    
+    words := strings.Fields(text)
+    for i, word := range words {
+        // Remove any punctuation from the word for comparison
+        cleanWord := strings.Trim(word, ".,!?;:¿¡")
+        if expanded, exists := contractions[strings.ToLower(cleanWord)]; exists {
+            words[i] = expanded
+        }
+    }
+    
+    return strings.Join(words, " ")
+}
+
+// NormalizeApostrophes normalizes all types of apostrophes to a standard apostrophe
+func NormalizeApostrophes(text string) string {
+    // Replace all types of apostrophes and quotation marks with standard apostrophe
+    apostropheVariants := []string{
+        "’", "‘", "`", "´", "ʹ", "ʻ", "ʼ", "ʽ", "ʾ", "ʿ", "ˊ", "ˋ", "˴", "ʹ", "΄", "՚", "׳", "״", "＇", "'",
+        "“", "”", "„", "«", "»", "「", "」", "『", "』", "〝", "〞", "〟", "＂",
+    }
+    
+    for _, variant := range apostropheVariants {
+        text = strings.ReplaceAll(text, variant, "'")
+    }
+    
+    return text
+}
+
+// Normalize normalizes text in a language, given by its ISO 639-1 code, for comparison
+// (case insensitive): apostrophes are unified, contractions expanded, punctuation removed
+// and whitespace collapsed. Letters with accents are kept.
+func Normalize(text, language string) string {
+    // First normalize all apostrophes
+    normalized := NormalizeApostrophes(text)
+    
+    // Expand contractions
+    normalized = ExpandContractions(normalized, language)
+    
+    // Remove all punctuation and special characters except spaces
+    normalized = punctuationPattern.ReplaceAllString(normalized, "")
+    
+    // Convert to lowercase
+    normalized = strings.ToLower(normalized)
+    
+    // Trim whitespace and collapse multiple spaces
+    normalized = strings.TrimSpace(normalized)
+    normalized = spacePattern.ReplaceAllString(normalized, " ")
+    
+    return normalized
+}
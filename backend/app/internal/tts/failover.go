@@ -0,0 +1,34 @@
+package tts
+
+import (
+    "context"
+    "errors"
+
+    "PulpuVOX/internal/failover"
+)
+
+// FailoverSynthesizer synthesizes speech with the first healthy provider of a chain
+type FailoverSynthesizer struct {
+    Chain *failover.Chain[Synthesizer]
+}
+
+// Synthesize implements Synthesizer. An error reported in the response body also
+// counts as a provider failure.
+func (fs *FailoverSynthesizer) Synthesize(ctx context.Context, req *TTSRequest) (*TTSResponse, error) {
+    var result *TTSResponse
+    _, err := fs.Chain.Do(ctx, func(ctx context.Context, synthesizer Synthesizer) error {
+        resp, err := synthesizer.Synthesize(ctx, req)
+        if err != nil {
+            return err
+        }
+        if resp.Error != "" {
+            return errors.New(resp.Error)
+        }
+        result = resp
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    return result, nil
+}
@@ -0,0 +1,130 @@
+package tts
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "time"
+
+    "PulpuVOX/internal/httpclient"
+)
+
+// GroqSynthesizer sends text to the Groq speech API
+type GroqSynthesizer struct {
+    BaseURL        string
+    APIKey         string
+    Model          string
+    Voice          string
+    ResponseFormat string
+    HTTPClient     *http.Client
+    RetryPolicy    httpclient.RetryPolicy
+}
+
+// NewGroqSynthesizer creates a synthesizer for the Groq provider
+func NewGroqSynthesizer() (*GroqSynthesizer, error) {
+    baseURL := providerEnv("groq", "BASE_URL")
+    if baseURL == "" {
+        return nil, fmt.Errorf("TTS_BASE_URL environment variable is not set")
+    }
+
+    timeout := httpclient.TimeoutFromEnv("TTS_TIMEOUT", 30*time.Second)
+
+    return &GroqSynthesizer{
+        BaseURL:        baseURL,
+        APIKey:         providerEnv("groq", "API_KEY"),
+        Model:          providerEnv("groq", "MODEL"),
+        Voice:          providerEnv("groq", "VOICE"),
+        ResponseFormat: providerEnv("groq", "RESPONSE_FORMAT"),
+        HTTPClient:     httpclient.New(timeout),
+        RetryPolicy:    httpclient.DefaultRetryPolicy.WithBudget(timeout),
+    }, nil
+}
+
+func init() {
+    Register("groq", func() (Synthesizer, error) {
+        return NewGroqSynthesizer()
+    })
+}
+
+// SetMaxRetries implements failover.Retrier
+func (ts *GroqSynthesizer) SetMaxRetries(maxRetries int) {
+    ts.RetryPolicy.MaxRetries = maxRetries
+}
+
+// Synthesize converts text to speech using Groq API
+func (ts *GroqSynthesizer) Synthesize(ctx context.Context, req *TTSRequest) (*TTSResponse, error) {
+    callerInfo := getCallerInfo()
+
+    // Fill in defaults on a copy so the caller's request can be sent to another provider
+    request := *req
+    req = &request
+
+    // Groq uses a different endpoint structure
+    url := fmt.Sprintf("%s/openai/v1/audio/speech", ts.BaseURL)
+    
+    // Use the service's default values if not provided in the request
+    if req.Model == "" {
+        req.Model = languageEnv("groq", "MODEL", req.Language, ts.Model)
+    }
+    if req.Voice == "" {
+        req.Voice = languageEnv("groq", "VOICE", req.Language, ts.Voice)
+    }
+    if req.ResponseFormat == "" {
+        req.ResponseFormat = ts.ResponseFormat
+    }
+
+    // Create TTS request for Groq
+    ttsRequest := map[string]interface{}{
+        "model":    req.Model,
+        "input":    req.Text,
+        "voice":    req.Voice,
+        "response_format": req.ResponseFormat,
+    }
+    // Groq speaks at normal speed unless told otherwise
+    if speed := scaledSpeed(req, 1); speed != 1 {
+        ttsRequest["speed"] = speed
+    }
+    
+    jsonData, err := json.Marshal(ttsRequest)
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal TTS request %s: %w", callerInfo, err)
+    }
+
+    // Create HTTP request
+    httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+    if err != nil {
+        return nil, fmt.Errorf("failed to create TTS request %s: %w", callerInfo, err)
+    }
+    httpReq.Header.Set("Content-Type", "application/json")
+    if ts.APIKey != "" {
+        httpReq.Header.Set("Authorization", "Bearer "+ts.APIKey)
+    }
+
+    // Send request
+    resp, err := httpclient.Do(ts.HTTPClient, httpReq, ts.RetryPolicy)
+    if err != nil {
+        return nil, fmt.Errorf("TTS request failed %s: %w", callerInfo, err)
+    }
+    defer resp.Body.Close()
+
+    // Check if the response is successful
+    if resp.StatusCode != http.StatusOK {
+        body, _ := io.ReadAll(resp.Body)
+        return &TTSResponse{
+            Error: fmt.Sprintf("TTS server returned error %s: Status %d, Body: %s", callerInfo, resp.StatusCode, string(body)),
+        }, nil
+    }
+
+    // Read the audio data
+    audioBytes, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read TTS audio %s: %w", callerInfo, err)
+    }
+
+    return &TTSResponse{
+        AudioData: audioBytes,
+    }, nil
+}
@@ -0,0 +1,131 @@
+package tts
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "time"
+
+    "PulpuVOX/internal/httpclient"
+)
+
+// KittenTTSSynthesizer sends text to a self-hosted KittenTTS server
+type KittenTTSSynthesizer struct {
+    BaseURL        string
+    APIKey         string
+    Model          string
+    Voice          string
+    ResponseFormat string
+    Speed          float64
+    HTTPClient     *http.Client
+    RetryPolicy    httpclient.RetryPolicy
+}
+
+// NewKittenTTSSynthesizer creates a synthesizer for the KittenTTS provider
+func NewKittenTTSSynthesizer() (*KittenTTSSynthesizer, error) {
+    baseURL := providerEnv("kittentts", "BASE_URL")
+    if baseURL == "" {
+        return nil, fmt.Errorf("TTS_BASE_URL environment variable is not set")
+    }
+
+    timeout := httpclient.TimeoutFromEnv("TTS_TIMEOUT", 30*time.Second)
+
+    return &KittenTTSSynthesizer{
+        BaseURL:        baseURL,
+        APIKey:         providerEnv("kittentts", "API_KEY"),
+        Model:          providerEnv("kittentts", "MODEL"),
+        Voice:          providerEnv("kittentts", "VOICE"),
+        ResponseFormat: providerEnv("kittentts", "RESPONSE_FORMAT"),
+        Speed:          providerSpeed("kittentts"),
+        HTTPClient:     httpclient.New(timeout),
+        RetryPolicy:    httpclient.DefaultRetryPolicy.WithBudget(timeout),
+    }, nil
+}
+
+func init() {
+    Register("kittentts", func() (Synthesizer, error) {
+        return NewKittenTTSSynthesizer()
+    })
+}
+
+// SetMaxRetries implements failover.Retrier
+func (ts *KittenTTSSynthesizer) SetMaxRetries(maxRetries int) {
+    ts.RetryPolicy.MaxRetries = maxRetries
+}
+
+// Synthesize converts text to speech using KittenTTS
+func (ts *KittenTTSSynthesizer) Synthesize(ctx context.Context, req *TTSRequest) (*TTSResponse, error) {
+    callerInfo := getCallerInfo()
+
+    // Fill in defaults on a copy so the caller's request can be sent to another provider
+    request := *req
+    req = &request
+
+    url := fmt.Sprintf("%s/v1/audio/speech", ts.BaseURL)
+    
+    // Use the service's default values if not provided in the request
+    if req.Model == "" {
+        req.Model = languageEnv("kittentts", "MODEL", req.Language, ts.Model)
+    }
+    if req.Voice == "" {
+        req.Voice = languageEnv("kittentts", "VOICE", req.Language, ts.Voice)
+    }
+    if req.ResponseFormat == "" {
+        req.ResponseFormat = ts.ResponseFormat
+    }
+    req.Speed = scaledSpeed(req, ts.Speed)
+
+    // Create TTS request - ensure this matches exactly what KittenTTS expects
+    ttsRequest := map[string]interface{}{
+        "model":           req.Model,
+        "input":           req.Text,
+        "voice":           req.Voice,
+        "response_format": req.ResponseFormat,
+        "speed":           req.Speed,
+    }
+    
+    jsonData, err := json.Marshal(ttsRequest)
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal TTS request %s: %w", callerInfo, err)
+    }
+
+    // Create HTTP request
+    httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+    if err != nil {
+        return nil, fmt.Errorf("failed to create TTS request %s: %w", callerInfo, err)
+    }
+    httpReq.Header.Set("Content-Type", "application/json")
+    
+    // KittenTTS might not require authentication, but include it if provided
+    if ts.APIKey != "" {
+        httpReq.Header.Set("Authorization", "Bearer "+ts.APIKey)
+    }
+
+    // Send request
+    resp, err := httpclient.Do(ts.HTTPClient, httpReq, ts.RetryPolicy)
+    if err != nil {
+        return nil, fmt.Errorf("TTS request failed %s: %w", callerInfo, err)
+    }
+    defer resp.Body.Close()
+
+    // Check if the response is successful
+    if resp.StatusCode != http.StatusOK {
+        body, _ := io.ReadAll(resp.Body)
+        return &TTSResponse{
+            Error: fmt.Sprintf("TTS server returned error %s: Status %d, Body: %s", callerInfo, resp.StatusCode, string(body)),
+        }, nil
+    }
+
+    // Read the audio data
+    audioBytes, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read TTS audio %s: %w", callerInfo, err)
+    }
+
+    return &TTSResponse{
+        AudioData: audioBytes,
+    }, nil
+}
@@ -1,85 +1,114 @@
 package tts
 
 import (
-    "bytes"
-    "encoding/json"
+    "context"
     "fmt"
-    "io"
-    "net/http"
     "os"
     "runtime"
+    "sort"
     "strconv"
+    "strings"
+    "sync"
 )
 
-// TTSProvider represents the available TTS providers
-type TTSProvider string
+// Synthesizer converts text to spoken audio
+type Synthesizer interface {
+    Synthesize(ctx context.Context, req *TTSRequest) (*TTSResponse, error)
+}
+
+// Factory builds a synthesizer from the environment configuration of its provider
+type Factory func() (Synthesizer, error)
 
-const (
-    ProviderKittenTTS TTSProvider = "kittentts"
-    ProviderGroq      TTSProvider = "groq"
+var (
+    registryMu sync.RWMutex
+    registry   = map[string]Factory{}
 )
 
-// TTSService handles text-to-speech conversion
-type TTSService struct {
-    BaseURL        string
-    APIKey         string
-    Model          string
-    Voice          string
-    ResponseFormat string
-    Speed          float64
-    Provider       TTSProvider
+// Register makes a TTS provider available under the given name
+func Register(name string, factory Factory) {
+    registryMu.Lock()
+    defer registryMu.Unlock()
+    registry[name] = factory
+}
+
+// New builds the synthesizer registered under the given name
+func New(name string) (Synthesizer, error) {
+    registryMu.RLock()
+    factory, ok := registry[name]
+    registryMu.RUnlock()
+    if !ok {
+        return nil, fmt.Errorf("unknown TTS provider %q (available: %v)", name, Providers())
+    }
+    return factory()
 }
 
-// NewTTSService creates a new TTS service
-func NewTTSService() (*TTSService, error) {
-    baseURL := os.Getenv("TTS_BASE_URL")
-    if baseURL == "" {
-        return nil, fmt.Errorf("TTS_BASE_URL environment variable is not set")
+// Providers lists the registered TTS providers
+func Providers() []string {
+    registryMu.RLock()
+    defer registryMu.RUnlock()
+    names := make([]string, 0, len(registry))
+    for name := range registry {
+        names = append(names, name)
     }
+    sort.Strings(names)
+    return names
+}
 
-    apiKey := os.Getenv("TTS_API_KEY")
-    model := os.Getenv("TTS_MODEL")
-    voice := os.Getenv("TTS_VOICE")
-    responseFormat := os.Getenv("TTS_RESPONSE_FORMAT")
-    
-    // Parse speed from environment
+// providerEnv reads TTS_<PROVIDER>_<KEY>, falling back to TTS_<KEY> so that
+// several providers can be configured side by side
+func providerEnv(provider, key string) string {
+    if value := os.Getenv("TTS_" + strings.ToUpper(provider) + "_" + key); value != "" {
+        return value
+    }
+    return os.Getenv("TTS_" + key)
+}
+
+// languageEnv reads a setting of a provider for one language, TTS_<PROVIDER>_<KEY>_<LANGUAGE>
+// or TTS_<KEY>_<LANGUAGE>, falling back to the given default
+func languageEnv(provider, key, language, fallback string) string {
+    if language != "" {
+        if value := providerEnv(provider, key+"_"+strings.ToUpper(language)); value != "" {
+            return value
+        }
+    }
+    return fallback
+}
+
+// providerSpeed parses the configured speech speed, defaulting to normal speed
+func providerSpeed(provider string) float64 {
     speed := 1.0
-    if speedStr := os.Getenv("TTS_SPEED"); speedStr != "" {
+    if speedStr := providerEnv(provider, "SPEED"); speedStr != "" {
         if parsedSpeed, err := strconv.ParseFloat(speedStr, 64); err == nil {
             speed = parsedSpeed
         }
     }
-    
-    // Determine provider from environment variable
-    providerStr := os.Getenv("TTS_PROVIDER")
-    var provider TTSProvider
-    switch providerStr {
-    case "groq":
-        provider = ProviderGroq
-    case "kittentts":
-        provider = ProviderKittenTTS
-    default:
-        provider = ProviderKittenTTS // Default to KittenTTS
-    }
-
-    return &TTSService{
-        BaseURL:        baseURL,
-        APIKey:         apiKey,
-        Model:          model,
-        Voice:          voice,
-        ResponseFormat: responseFormat,
-        Speed:          speed,
-        Provider:       provider,
-    }, nil
+    return speed
 }
 
-// TTSRequest represents a TTS request
+// TTSRequest represents a TTS request. Language is the ISO 639-1 code of the text, which
+// selects the voice and model configured for it when the request names none. SpeedScale
+// multiplies the speed, so speech can be slowed down for a learner; 0 leaves it unchanged.
 type TTSRequest struct {
     Text           string  `json:"input"`
+    Language       string  `json:"-"`
     Model          string  `json:"model,omitempty"`
     Voice          string  `json:"voice,omitempty"`
     ResponseFormat string  `json:"response_format,omitempty"`
     Speed          float64 `json:"speed,omitempty"`
+    SpeedScale     float64 `json:"-"`
+}
+
+// scaledSpeed returns the speed of a request after its SpeedScale, given the speed used
+// when the request names none
+func scaledSpeed(req *TTSRequest, fallback float64) float64 {
+    speed := req.Speed
+    if speed == 0 {
+        speed = fallback
+    }
+    if req.SpeedScale > 0 {
+        speed *= req.SpeedScale
+    }
+    return speed
 }
 
 // TTSResponse represents a TTS response
@@ -96,154 +125,3 @@ func getCallerInfo() string {
     }
     return "at unknown location"
 }
-
-// ConvertTextToSpeech converts text to speech
-func (ts *TTSService) ConvertTextToSpeech(req *TTSRequest) (*TTSResponse, error) {
-    callerInfo := getCallerInfo()
-    
-    switch ts.Provider {
-    case ProviderGroq:
-        return ts.convertWithGroq(req, callerInfo)
-    case ProviderKittenTTS:
-        fallthrough
-    default:
-        return ts.convertWithKittenTTS(req, callerInfo)
-    }
-}
-
-// convertWithKittenTTS converts text to speech using KittenTTS
-func (ts *TTSService) convertWithKittenTTS(req *TTSRequest, callerInfo string) (*TTSResponse, error) {
-    url := fmt.Sprintf("%s/v1/audio/speech", ts.BaseURL)
-    
-    // Use the service's default values if not provided in the request
-    if req.Model == "" {
-        req.Model = ts.Model
-    }
-    if req.Voice == "" {
-        req.Voice = ts.Voice
-    }
-    if req.ResponseFormat == "" {
-        req.ResponseFormat = ts.ResponseFormat
-    }
-    if req.Speed == 0 {
-        req.Speed = ts.Speed
-    }
-
-    // Create TTS request - ensure this matches exactly what KittenTTS expects
-    ttsRequest := map[string]interface{}{
-        "model":           req.Model,
-        "input":           req.Text,
-        "voice":           req.Voice,
-        "response_format": req.ResponseFormat,
-        "speed":           req.Speed,
-    }
-    
-    jsonData, err := json.Marshal(ttsRequest)
-    if err != nil {
-        return nil, fmt.Errorf("failed to marshal TTS request %s: %w", callerInfo, err)
-    }
-
-    // Create HTTP request
-    httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-    if err != nil {
-        return nil, fmt.Errorf("failed to create TTS request %s: %w", callerInfo, err)
-    }
-    httpReq.Header.Set("Content-Type", "application/json")
-    
-    // KittenTTS might not require authentication, but include it if provided
-    if ts.APIKey != "" {
-        httpReq.Header.Set("Authorization", "Bearer "+ts.APIKey)
-    }
-
-    // Send request
-    client := &http.Client{}
-    resp, err := client.Do(httpReq)
-    if err != nil {
-        return nil, fmt.Errorf("TTS request failed %s: %w", callerInfo, err)
-    }
-    defer resp.Body.Close()
-
-    // Check if the response is successful
-    if resp.StatusCode != http.StatusOK {
-        body, _ := io.ReadAll(resp.Body)
-        return &TTSResponse{
-            Error: fmt.Sprintf("TTS server returned error %s: Status %d, Body: %s", callerInfo, resp.StatusCode, string(body)),
-        }, nil
-    }
-
-    // Read the audio data
-    audioBytes, err := io.ReadAll(resp.Body)
-    if err != nil {
-        return nil, fmt.Errorf("failed to read TTS audio %s: %w", callerInfo, err)
-    }
-
-    return &TTSResponse{
-        AudioData: audioBytes,
-    }, nil
-}
-
-// convertWithGroq converts text to speech using Groq API
-func (ts *TTSService) convertWithGroq(req *TTSRequest, callerInfo string) (*TTSResponse, error) {
-    // Groq uses a different endpoint structure
-    url := fmt.Sprintf("%s/openai/v1/audio/speech", ts.BaseURL)
-    
-    // Use the service's default values if not provided in the request
-    if req.Model == "" {
-        req.Model = ts.Model
-    }
-    if req.Voice == "" {
-        req.Voice = ts.Voice
-    }
-    if req.ResponseFormat == "" {
-        req.ResponseFormat = ts.ResponseFormat
-    }
-
-    // Create TTS request for Groq
-    ttsRequest := map[string]interface{}{
-        "model":    req.Model,
-        "input":    req.Text,
-        "voice":    req.Voice,
-        "response_format": req.ResponseFormat,
-    }
-    
-    jsonData, err := json.Marshal(ttsRequest)
-    if err != nil {
-        return nil, fmt.Errorf("failed to marshal TTS request %s: %w", callerInfo, err)
-    }
-
-    // Create HTTP request
-    httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-    if err != nil {
-        return nil, fmt.Errorf("failed to create TTS request %s: %w", callerInfo, err)
-    }
-    httpReq.Header.Set("Content-Type", "application/json")
-    if ts.APIKey != "" {
-        httpReq.Header.Set("Authorization", "Bearer "+ts.APIKey)
-    }
-
-    // Send request
-    client := &http.Client{}
-    resp, err := client.Do(httpReq)
-    if err != nil {
-        return nil, fmt.Errorf("TTS request failed %s: %w", callerInfo, err)
-    }
-    defer resp.Body.Close()
-
-    // Check if the response is successful
-    if resp.StatusCode != http.StatusOK {
-        body, _ := io.ReadAll(resp.Body)
-        return &TTSResponse{
-            Error: fmt.Sprintf("TTS server returned error %s: Status %d, Body: %s", callerInfo, resp.StatusCode, string(body)),
-        }, nil
-    }
-
-    // Read the audio data
-    audioBytes, err := io.ReadAll(resp.Body)
-    if err != nil {
-        return nil, fmt.Errorf("failed to read TTS audio %s: %w", callerInfo, err)
-    }
-
-    return &TTSResponse{
-        AudioData: audioBytes,
-    }, nil
-}
@@ -0,0 +1,44 @@
+package vocabulary
+
+import (
+    _ "embed"
+    "strings"
+)
+
+//go:embed levels.txt
+var levelsList string
+
+// wordLevels maps a lemma to the CEFR level at which it is first expected
+var wordLevels = parseLevels(levelsList)
+
+// parseLevels reads the level list: a "[A1]" header starts a level and the words below it
+// belong to that level. A word listed twice keeps the lower level.
+func parseLevels(list string) map[string]string {
+    levels := make(map[string]string)
+    level := ""
+    for _, line := range strings.Split(list, "\n") {
+        line = strings.TrimSpace(line)
+        if line == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+        if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+            level = strings.Trim(line, "[]")
+            continue
+        }
+        for _, word := range strings.Fields(line) {
+            if _, ok := levels[word]; !ok {
+                levels[word] = level
+            }
+        }
+    }
+    return levels
+}
+
+// Level returns the CEFR level of a lemma, or nil if the word is not in the list
+func Level(lemma string) *string {
+    level, ok := wordLevels[lemma]
+    if !ok {
+        return nil
+    }
+    return &level
+}
@@ -0,0 +1,66 @@
+package vocabulary
+
+import (
+    "context"
+    "fmt"
+    "sort"
+
+    "PulpuVOX/internal/db"
+    "PulpuVOX/internal/textnorm"
+    "github.com/jackc/pgx/v5"
+)
+
+// Count tallies the lemmas of a conversation in a language: the words the student used in
+// their turns and the words of the corrections they were given. Lemmas and levels are only
+// known for English, so conversations in other languages count no words.
+func Count(history []db.ConversationTurn, language string) []db.LemmaCount {
+    if language != "en" {
+        return nil
+    }
+    counts := map[string]*db.LemmaCount{}
+    entry := func(lemma string) *db.LemmaCount {
+        if counts[lemma] == nil {
+            counts[lemma] = &db.LemmaCount{Lemma: lemma, CEFRLevel: Level(lemma)}
+        }
+        return counts[lemma]
+    }
+
+    for _, turn := range history {
+        if turn.Role != "user" {
+            continue
+        }
+        for _, lemma := range textnorm.Lemmas(turn.Content) {
+            entry(lemma).Frequency++
+        }
+        for _, lemma := range textnorm.Lemmas(turn.Suggestion) {
+            entry(lemma).CorrectionCount++
+        }
+    }
+
+    lemmas := make([]db.LemmaCount, 0, len(counts))
+    for _, count := range counts {
+        lemmas = append(lemmas, *count)
+    }
+    sort.Slice(lemmas, func(i, j int) bool { return lemmas[i].Lemma < lemmas[j].Lemma })
+    return lemmas
+}
+
+// Record adds the words of a saved conversation to the user's vocabulary
+func Record(ctx context.Context, conn *pgx.Conn, conversation *db.Conversation) error {
+    counts := Count(conversation.History, conversation.Language)
+    return db.RecordConversationVocabulary(ctx, conn, conversation.ID, conversation.UserID, conversation.CreatedAt, counts)
+}
+
+// Backfill records the vocabulary of the user's conversations saved before it was tracked
+func Backfill(ctx context.Context, conn *pgx.Conn, userID int) error {
+    conversations, err := db.ListConversationsWithoutVocabulary(ctx, conn, userID)
+    if err != nil {
+        return err
+    }
+    for i := range conversations {
+        if err := Record(ctx, conn, &conversations[i]); err != nil {
+            return fmt.Errorf("conversation %d: %w", conversations[i].ID, err)
+        }
+    }
+    return nil
+}
@@ -0,0 +1,104 @@
+package whisper
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "mime/multipart"
+    "net/http"
+    "time"
+
+    "PulpuVOX/internal/httpclient"
+)
+
+// DockerTranscriber sends audio to a self-hosted whisper-asr-webservice container
+type DockerTranscriber struct {
+    WhisperURL  string
+    HTTPClient  *http.Client
+    RetryPolicy httpclient.RetryPolicy
+}
+
+// NewDockerTranscriber creates a transcriber for the Docker provider
+func NewDockerTranscriber() (*DockerTranscriber, error) {
+    whisperURL := providerEnv("docker", "URL")
+    if whisperURL == "" {
+        return nil, fmt.Errorf("WHISPER_URL environment variable is not set")
+    }
+
+    timeout := httpclient.TimeoutFromEnv("WHISPER_TIMEOUT", 30*time.Second)
+
+    return &DockerTranscriber{
+        WhisperURL:  whisperURL,
+        HTTPClient:  httpclient.New(timeout),
+        RetryPolicy: httpclient.DefaultRetryPolicy.WithBudget(timeout),
+    }, nil
+}
+
+func init() {
+    Register("docker", func() (Transcriber, error) {
+        return NewDockerTranscriber()
+    })
+}
+
+// SetMaxRetries implements failover.Retrier
+func (ts *DockerTranscriber) SetMaxRetries(maxRetries int) {
+    ts.RetryPolicy.MaxRetries = maxRetries
+}
+
+// Transcribe sends the audio to the Docker container
+func (ts *DockerTranscriber) Transcribe(ctx context.Context, req *TranscribeRequest) (*TranscribeResponse, error) {
+    callerInfo := getCallerInfo()
+
+    body := &bytes.Buffer{}
+    writer := multipart.NewWriter(body)
+    part, err := writer.CreateFormFile("audio_file", req.FileName)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create form file %s: %w", callerInfo, err)
+    }
+    if _, err := io.Copy(part, bytes.NewReader(req.AudioData)); err != nil {
+        return nil, fmt.Errorf("failed to write audio data to form %s: %w", callerInfo, err)
+    }
+    if err := writer.Close(); err != nil {
+        return nil, fmt.Errorf("failed to close multipart writer %s: %w", callerInfo, err)
+    }
+
+    // Build the URL with query parameters
+    whisperURL := fmt.Sprintf("%s?encode=true&task=%s&language=%s&output=%s",
+        ts.WhisperURL, req.Task, req.Language, req.OutputFormat)
+    if req.WordTimestamps {
+        // Segments are part of the json output; timed words are added on request
+        whisperURL += "&word_timestamps=true"
+    }
+    
+    httpReq, err := http.NewRequestWithContext(ctx, "POST", whisperURL, body)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create HTTP request %s: %w", callerInfo, err)
+    }
+    httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+    resp, err := httpclient.Do(ts.HTTPClient, httpReq, ts.RetryPolicy)
+    if err != nil {
+        return nil, fmt.Errorf("failed to send request to Whisper %s: %w", callerInfo, err)
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        body, _ := io.ReadAll(resp.Body)
+        return nil, fmt.Errorf("Whisper service returned error %s: Status %d, Body: %s", 
+            callerInfo, resp.StatusCode, string(body))
+    }
+
+    respBody, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read response body %s: %w", callerInfo, err)
+    }
+
+    var result TranscribeResponse
+    if err := json.Unmarshal(respBody, &result); err != nil {
+        return nil, fmt.Errorf("failed to parse Whisper response %s: %w", callerInfo, err)
+    }
+
+    return &result, nil
+}
@@ -0,0 +1,29 @@
+package whisper
+
+import (
+    "context"
+
+    "PulpuVOX/internal/failover"
+)
+
+// FailoverTranscriber transcribes with the first healthy provider of a chain
+type FailoverTranscriber struct {
+    Chain *failover.Chain[Transcriber]
+}
+
+// Transcribe implements Transcriber
+func (ft *FailoverTranscriber) Transcribe(ctx context.Context, req *TranscribeRequest) (*TranscribeResponse, error) {
+    var result *TranscribeResponse
+    _, err := ft.Chain.Do(ctx, func(ctx context.Context, transcriber Transcriber) error {
+        resp, err := transcriber.Transcribe(ctx, req)
+        if err != nil {
+            return err
+        }
+        result = resp
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    return result, nil
+}
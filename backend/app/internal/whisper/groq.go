@@ -0,0 +1,150 @@
+package whisper
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "mime/multipart"
+    "net/http"
+    "strings"
+    "time"
+
+    "PulpuVOX/internal/httpclient"
+)
+
+// GroqTranscriber sends audio to the Groq transcription API
+type GroqTranscriber struct {
+    WhisperURL  string
+    APIKey      string
+    Model       string
+    HTTPClient  *http.Client
+    RetryPolicy httpclient.RetryPolicy
+}
+
+// NewGroqTranscriber creates a transcriber for the Groq provider
+func NewGroqTranscriber() (*GroqTranscriber, error) {
+    whisperURL := providerEnv("groq", "URL")
+    if whisperURL == "" {
+        return nil, fmt.Errorf("WHISPER_URL environment variable is not set")
+    }
+
+    timeout := httpclient.TimeoutFromEnv("WHISPER_TIMEOUT", 30*time.Second)
+
+    return &GroqTranscriber{
+        WhisperURL:  whisperURL,
+        APIKey:      providerEnv("groq", "KEY"),
+        Model:       providerEnv("groq", "MODEL"),
+        HTTPClient:  httpclient.New(timeout),
+        RetryPolicy: httpclient.DefaultRetryPolicy.WithBudget(timeout),
+    }, nil
+}
+
+func init() {
+    Register("groq", func() (Transcriber, error) {
+        return NewGroqTranscriber()
+    })
+}
+
+// SetMaxRetries implements failover.Retrier
+func (ts *GroqTranscriber) SetMaxRetries(maxRetries int) {
+    ts.RetryPolicy.MaxRetries = maxRetries
+}
+
+// Transcribe sends the audio to the Groq API
+func (ts *GroqTranscriber) Transcribe(ctx context.Context, req *TranscribeRequest) (*TranscribeResponse, error) {
+    callerInfo := getCallerInfo()
+
+    body := &bytes.Buffer{}
+    writer := multipart.NewWriter(body)
+
+    // Use the model from the service (set via env) unless overridden in the request.
+    // A model can be set per language, as the default may only know English.
+    model := ts.Model
+    if languageModel := providerEnv("groq", "MODEL_"+strings.ToUpper(req.Language)); req.Language != "" && languageModel != "" {
+        model = languageModel
+    }
+    if req.Model != "" {
+        model = req.Model
+    }
+    if model == "" {
+        return nil, fmt.Errorf("model is required for Groq provider %s", callerInfo)
+    }
+
+    // Add model parameter
+    if err := writer.WriteField("model", model); err != nil {
+        return nil, fmt.Errorf("failed to write model field %s: %w", callerInfo, err)
+    }
+
+    // Add response format; only verbose_json carries segments and timestamps
+    responseFormat := "json"
+    if req.OutputFormat == "verbose_json" || req.WordTimestamps {
+        responseFormat = "verbose_json"
+    }
+    if err := writer.WriteField("response_format", responseFormat); err != nil {
+        return nil, fmt.Errorf("failed to write response_format field %s: %w", callerInfo, err)
+    }
+    if req.WordTimestamps {
+        for _, granularity := range []string{"word", "segment"} {
+            if err := writer.WriteField("timestamp_granularities[]", granularity); err != nil {
+                return nil, fmt.Errorf("failed to write timestamp_granularities field %s: %w", callerInfo, err)
+            }
+        }
+    }
+
+    // Add language if specified
+    if req.Language != "" && req.Language != "auto" {
+        if err := writer.WriteField("language", req.Language); err != nil {
+            return nil, fmt.Errorf("failed to write language field %s: %w", callerInfo, err)
+        }
+    }
+
+    // Add audio file
+    part, err := writer.CreateFormFile("file", req.FileName)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create form file %s: %w", callerInfo, err)
+    }
+    if _, err := io.Copy(part, bytes.NewReader(req.AudioData)); err != nil {
+        return nil, fmt.Errorf("failed to write audio data to form %s: %w", callerInfo, err)
+    }
+    if err := writer.Close(); err != nil {
+        return nil, fmt.Errorf("failed to close multipart writer %s: %w", callerInfo, err)
+    }
+
+    httpReq, err := http.NewRequestWithContext(ctx, "POST", ts.WhisperURL, body)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create HTTP request %s: %w", callerInfo, err)
+    }
+    httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+    if ts.APIKey == "" {
+        return nil, fmt.Errorf("API key is required for Groq provider %s", callerInfo)
+    }
+    httpReq.Header.Set("Authorization", "Bearer "+ts.APIKey)
+
+    resp, err := httpclient.Do(ts.HTTPClient, httpReq, ts.RetryPolicy)
+    if err != nil {
+        return nil, fmt.Errorf("failed to send request to Groq %s: %w", callerInfo, err)
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        body, _ := io.ReadAll(resp.Body)
+        return nil, fmt.Errorf("Groq service returned error %s: Status %d - %s", 
+            callerInfo, resp.StatusCode, string(body))
+    }
+
+    respBody, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read response body %s: %w", callerInfo, err)
+    }
+
+    // Parse Groq response; the verbose format matches our standard response format
+    var result TranscribeResponse
+    if err := json.Unmarshal(respBody, &result); err != nil {
+        return nil, fmt.Errorf("failed to parse Groq response %s: %w", callerInfo, err)
+    }
+
+    return &result, nil
+}
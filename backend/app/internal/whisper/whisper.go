@@ -1,45 +1,65 @@
 package whisper
 
 import (
-    "bytes"
-    "encoding/json"
+    "context"
     "fmt"
-    "io"
-    "mime/multipart"
-    "net/http"
     "os"
     "runtime"
+    "sort"
+    "strings"
+    "sync"
 )
 
-// TranscribeService handles audio transcription
-type TranscribeService struct {
-    WhisperURL string
-    Provider   string
-    APIKey     string
-    Model      string
+// Transcriber converts recorded speech to text
+type Transcriber interface {
+    Transcribe(ctx context.Context, req *TranscribeRequest) (*TranscribeResponse, error)
 }
 
-// NewTranscribeService creates a new transcription service
-func NewTranscribeService() (*TranscribeService, error) {
-    whisperURL := os.Getenv("WHISPER_URL")
-    if whisperURL == "" {
-        return nil, fmt.Errorf("WHISPER_URL environment variable is not set")
-    }
+// Factory builds a transcriber from the environment configuration of its provider
+type Factory func() (Transcriber, error)
+
+var (
+    registryMu sync.RWMutex
+    registry   = map[string]Factory{}
+)
 
-    provider := os.Getenv("WHISPER_PROVIDER")
-    if provider == "" {
-        provider = "docker" // Default to docker provider
+// Register makes a transcription provider available under the given name
+func Register(name string, factory Factory) {
+    registryMu.Lock()
+    defer registryMu.Unlock()
+    registry[name] = factory
+}
+
+// New builds the transcriber registered under the given name
+func New(name string) (Transcriber, error) {
+    registryMu.RLock()
+    factory, ok := registry[name]
+    registryMu.RUnlock()
+    if !ok {
+        return nil, fmt.Errorf("unknown transcription provider %q (available: %v)", name, Providers())
     }
+    return factory()
+}
 
-    apiKey := os.Getenv("WHISPER_KEY")
-    model := os.Getenv("WHISPER_MODEL")
+// Providers lists the registered transcription providers
+func Providers() []string {
+    registryMu.RLock()
+    defer registryMu.RUnlock()
+    names := make([]string, 0, len(registry))
+    for name := range registry {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
 
-    return &TranscribeService{
-        WhisperURL: whisperURL,
-        Provider:   provider,
-        APIKey:     apiKey,
-        Model:      model,
-    }, nil
+// providerEnv reads WHISPER_<PROVIDER>_<KEY>, falling back to WHISPER_<KEY> so that
+// several providers can be configured side by side
+func providerEnv(provider, key string) string {
+    if value := os.Getenv("WHISPER_" + strings.ToUpper(provider) + "_" + key); value != "" {
+        return value
+    }
+    return os.Getenv("WHISPER_" + key)
 }
 
 // TranscribeRequest represents a transcription request
@@ -50,181 +70,77 @@ type TranscribeRequest struct {
     Task         string
     OutputFormat string
     Model        string // Optional: override default model
+    // WordTimestamps asks for segments and timed words, which providers only return on request
+    WordTimestamps bool
 }
 
-// TranscribeResponse represents a transcription response
-type TranscribeResponse struct {
-    Text     string `json:"text"`
-    Language string `json:"language"`
-    Error    string `json:"error,omitempty"`
+// Word is a transcribed word with its position in the audio, in seconds
+type Word struct {
+    Word  string  `json:"word"`
+    Start float64 `json:"start"`
+    End   float64 `json:"end"`
+    // Probability is the recognizer's confidence in the word, or 0 when the provider does not report it
+    Probability float64 `json:"probability,omitempty"`
 }
 
-// getCallerInfo returns file and line information for error reporting
-func getCallerInfo() string {
-    pc, file, line, ok := runtime.Caller(2) // Skip 2 frames to get the actual caller
-    if ok {
-        return fmt.Sprintf("at %s:%d (%s)", file, line, runtime.FuncForPC(pc).Name())
-    }
-    return "at unknown location"
+// Segment is a stretch of transcribed speech with the recognizer's confidence in it
+type Segment struct {
+    ID           int     `json:"id"`
+    Start        float64 `json:"start"`
+    End          float64 `json:"end"`
+    Text         string  `json:"text"`
+    AvgLogprob   float64 `json:"avg_logprob"`
+    NoSpeechProb float64 `json:"no_speech_prob"`
+    Words        []Word  `json:"words,omitempty"`
 }
 
-// SendToWhisper sends audio data to the Whisper service for transcription
-func (ts *TranscribeService) SendToWhisper(req *TranscribeRequest) (*TranscribeResponse, error) {
-    callerInfo := getCallerInfo()
-    
-    switch ts.Provider {
-    case "groq":
-        return ts.sendToGroq(req, callerInfo)
-    case "docker":
-        fallthrough
-    default:
-        return ts.sendToDocker(req, callerInfo)
-    }
+// TranscribeResponse represents a transcription response. Duration, Segments and Words are
+// only filled in when word timestamps were requested; providers either nest the words in
+// their segments or list them separately.
+type TranscribeResponse struct {
+    Text     string    `json:"text"`
+    Language string    `json:"language"`
+    Duration float64   `json:"duration,omitempty"`
+    Segments []Segment `json:"segments,omitempty"`
+    Words    []Word    `json:"words,omitempty"`
+    Error    string    `json:"error,omitempty"`
 }
 
-// sendToDocker sends request to the Docker container
-func (ts *TranscribeService) sendToDocker(req *TranscribeRequest, callerInfo string) (*TranscribeResponse, error) {
-    body := &bytes.Buffer{}
-    writer := multipart.NewWriter(body)
-    part, err := writer.CreateFormFile("audio_file", req.FileName)
-    if err != nil {
-        return nil, fmt.Errorf("failed to create form file %s: %w", callerInfo, err)
-    }
-    if _, err := io.Copy(part, bytes.NewReader(req.AudioData)); err != nil {
-        return nil, fmt.Errorf("failed to write audio data to form %s: %w", callerInfo, err)
+// AllWords returns the timed words of the transcription in order, wherever the provider put them
+func (tr *TranscribeResponse) AllWords() []Word {
+    if len(tr.Words) > 0 {
+        return tr.Words
     }
-    if err := writer.Close(); err != nil {
-        return nil, fmt.Errorf("failed to close multipart writer %s: %w", callerInfo, err)
-    }
-
-    // Build the URL with query parameters
-    whisperURL := fmt.Sprintf("%s?encode=true&task=%s&language=%s&output=%s",
-        ts.WhisperURL, req.Task, req.Language, req.OutputFormat)
-    
-    httpReq, err := http.NewRequest("POST", whisperURL, body)
-    if err != nil {
-        return nil, fmt.Errorf("failed to create HTTP request %s: %w", callerInfo, err)
-    }
-    httpReq.Header.Set("Content-Type", writer.FormDataContentType())
-
-    client := &http.Client{}
-    resp, err := client.Do(httpReq)
-    if err != nil {
-        return nil, fmt.Errorf("failed to send request to Whisper %s: %w", callerInfo, err)
+    var words []Word
+    for _, segment := range tr.Segments {
+        words = append(words, segment.Words...)
     }
-    defer resp.Body.Close()
-
-    if resp.StatusCode != http.StatusOK {
-        body, _ := io.ReadAll(resp.Body)
-        return nil, fmt.Errorf("Whisper service returned error %s: Status %d, Body: %s", 
-            callerInfo, resp.StatusCode, string(body))
-    }
-
-    respBody, err := io.ReadAll(resp.Body)
-    if err != nil {
-        return nil, fmt.Errorf("failed to read response body %s: %w", callerInfo, err)
-    }
-
-    var result TranscribeResponse
-    if err := json.Unmarshal(respBody, &result); err != nil {
-        return nil, fmt.Errorf("failed to parse Whisper response %s: %w", callerInfo, err)
-    }
-
-    return &result, nil
+    return words
 }
 
-// sendToGroq sends request to Groq API
-func (ts *TranscribeService) sendToGroq(req *TranscribeRequest, callerInfo string) (*TranscribeResponse, error) {
-    body := &bytes.Buffer{}
-    writer := multipart.NewWriter(body)
-
-    // Use the model from the service (set via env) unless overridden in the request
-    model := ts.Model
-    if req.Model != "" {
-        model = req.Model
-    }
-    if model == "" {
-        return nil, fmt.Errorf("model is required for Groq provider %s", callerInfo)
-    }
-
-    // Add model parameter
-    if err := writer.WriteField("model", model); err != nil {
-        return nil, fmt.Errorf("failed to write model field %s: %w", callerInfo, err)
-    }
-
-    // Add response format
-    responseFormat := "json"
-    if req.OutputFormat == "verbose_json" {
-        responseFormat = "verbose_json"
-    }
-    if err := writer.WriteField("response_format", responseFormat); err != nil {
-        return nil, fmt.Errorf("failed to write response_format field %s: %w", callerInfo, err)
-    }
-
-    // Add language if specified
-    if req.Language != "" && req.Language != "auto" {
-        if err := writer.WriteField("language", req.Language); err != nil {
-            return nil, fmt.Errorf("failed to write language field %s: %w", callerInfo, err)
-        }
-    }
-
-    // Add audio file
-    part, err := writer.CreateFormFile("file", req.FileName)
-    if err != nil {
-        return nil, fmt.Errorf("failed to create form file %s: %w", callerInfo, err)
-    }
-    if _, err := io.Copy(part, bytes.NewReader(req.AudioData)); err != nil {
-        return nil, fmt.Errorf("failed to write audio data to form %s: %w", callerInfo, err)
-    }
-    if err := writer.Close(); err != nil {
-        return nil, fmt.Errorf("failed to close multipart writer %s: %w", callerInfo, err)
-    }
-
-    httpReq, err := http.NewRequest("POST", ts.WhisperURL, body)
-    if err != nil {
-        return nil, fmt.Errorf("failed to create HTTP request %s: %w", callerInfo, err)
-    }
-    httpReq.Header.Set("Content-Type", writer.FormDataContentType())
-
-    if ts.APIKey == "" {
-        return nil, fmt.Errorf("API key is required for Groq provider %s", callerInfo)
-    }
-    httpReq.Header.Set("Authorization", "Bearer "+ts.APIKey)
-
-    client := &http.Client{}
-    resp, err := client.Do(httpReq)
-    if err != nil {
-        return nil, fmt.Errorf("failed to send request to Groq %s: %w", callerInfo, err)
-    }
-    defer resp.Body.Close()
-
-    if resp.StatusCode != http.StatusOK {
-        body, _ := io.ReadAll(resp.Body)
-        return nil, fmt.Errorf("Groq service returned error %s: Status %d - %s", 
-            callerInfo, resp.StatusCode, string(body))
-    }
-
-    respBody, err := io.ReadAll(resp.Body)
-    if err != nil {
-        return nil, fmt.Errorf("failed to read response body %s: %w", callerInfo, err)
-    }
-
-    // Parse Groq response
-    var groqResponse struct {
-        Text     string `json:"text"`
-        Language string `json:"language"`
-    }
-    if err := json.Unmarshal(respBody, &groqResponse); err != nil {
-        return nil, fmt.Errorf("failed to parse Groq response %s: %w", callerInfo, err)
+// FileNameForMimeType picks a file name whose extension matches the recorded audio format
+func FileNameForMimeType(mimeType string) string {
+    switch {
+    case strings.Contains(mimeType, "webm"):
+        return "recording.webm"
+    case strings.Contains(mimeType, "ogg"):
+        return "recording.ogg"
+    case strings.Contains(mimeType, "mp4"):
+        return "recording.mp4"
+    case strings.Contains(mimeType, "wav"):
+        return "recording.wav"
+    default:
+        return "recording.mp3"
     }
+}
 
-    // Convert to our standard response format
-    result := &TranscribeResponse{
-        Text:     groqResponse.Text,
-        Language: groqResponse.Language,
+// getCallerInfo returns file and line information for error reporting
+func getCallerInfo() string {
+    pc, file, line, ok := runtime.Caller(2) // Skip 2 frames to get the actual caller
+    if ok {
+        return fmt.Sprintf("at %s:%d (%s)", file, line, runtime.FuncForPC(pc).Name())
     }
-
-    return result, nil
+    return "at unknown location"
 }
 
 // GetWhisperURL returns the Whisper URL with default values if not set
@@ -0,0 +1,103 @@
+// API functions for classes, their rosters and their students' work
+const ClassesAPI = {
+    // Send a request and parse the JSON response, failing with the given message
+    request: function(url, options, errorMessage) {
+        return fetch(url, Object.assign({ credentials: 'include' }, options))
+            .then(response => {
+                if (!response.ok) {
+                    return response.text().then(text => {
+                        throw new Error(text.trim() || errorMessage);
+                    });
+                }
+                return response.status === 204 ? null : response.json();
+            });
+    },
+
+    // Send a JSON body with the given method
+    send: function(method, url, body, errorMessage) {
+        return this.request(url, {
+            method: method,
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify(body)
+        }, errorMessage);
+    },
+
+    // Function to fetch the classes the user teaches and has joined, and their role
+    fetchClasses: function() {
+        return this.request('/api/classes', {}, 'Failed to load classes');
+    },
+
+    // Function to create a class
+    createClass: function(name) {
+        return this.send('POST', '/api/classes', { name: name }, 'Failed to create class');
+    },
+
+    // Function to join a class with its invite code
+    joinClass: function(inviteCode) {
+        return this.send('POST', '/api/classes/join', { invite_code: inviteCode }, 'Failed to join class');
+    },
+
+    // Function to give a class a new invite code
+    regenerateInviteCode: function(classId) {
+        return this.request('/api/classes/' + classId + '/invite-code', { method: 'POST' }, 'Failed to regenerate invite code');
+    },
+
+    // Function to fetch the roster of a class
+    fetchStudents: function(classId) {
+        return this.request('/api/classes/' + classId + '/students', {}, 'Failed to load students');
+    },
+
+    // Function to remove a student from a class
+    removeStudent: function(classId, studentId) {
+        return this.request('/api/classes/' + classId + '/students/' + studentId, { method: 'DELETE' }, 'Failed to remove student');
+    },
+
+    // Function to fetch a page of a student's conversations
+    fetchStudentConversations: function(studentId, page) {
+        return this.request('/api/teacher/students/' + studentId + '/conversations?page=' + page, {}, 'Failed to load conversations');
+    },
+
+    // Function to fetch a student's grammar errors by type
+    fetchStudentErrors: function(studentId) {
+        return this.request('/api/teacher/students/' + studentId + '/progress/errors', {}, 'Failed to load errors');
+    },
+
+    // Function to fetch the assignments of the classes the user has joined with their status
+    fetchMyAssignments: function() {
+        return this.request('/api/assignments', {}, 'Failed to load assignments');
+    },
+
+    // Function to fetch the assignments of a class
+    fetchAssignments: function(classId) {
+        return this.request('/api/classes/' + classId + '/assignments', {}, 'Failed to load assignments');
+    },
+
+    // Function to set a class an assignment
+    createAssignment: function(classId, assignment) {
+        return this.send('POST', '/api/classes/' + classId + '/assignments', assignment, 'Failed to create assignment');
+    },
+
+    // Function to fetch the status of every student of an assignment
+    fetchAssignmentStudents: function(assignmentId) {
+        return this.request('/api/assignments/' + assignmentId + '/students', {}, 'Failed to load assignment status');
+    },
+
+    // Function to delete an assignment
+    deleteAssignment: function(assignmentId) {
+        return this.request('/api/assignments/' + assignmentId, { method: 'DELETE' }, 'Failed to delete assignment');
+    },
+
+    // Function to fetch the scenarios an assignment can use
+    fetchScenarios: function() {
+        return this.request('/api/scenarios', {}, 'Failed to load scenarios');
+    },
+
+    // Function to fetch the languages an assignment can be in
+    fetchLanguages: function() {
+        return this.request('/api/languages', {}, 'Failed to load languages');
+    }
+};
+
+export { ClassesAPI };
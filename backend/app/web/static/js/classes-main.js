@@ -0,0 +1,176 @@
+import { ClassesAPI } from './classes-api.js';
+import { ClassesUI } from './classes-ui.js';
+
+// Main application logic for classes
+document.addEventListener('DOMContentLoaded', function() {
+    let selectedClass = null;
+    let assignmentFormLoaded = false;
+    
+    // Load the classes the user has joined and, for teachers, the classes they teach
+    function loadClasses() {
+        ClassesAPI.fetchClasses()
+            .then(data => {
+                ClassesUI.displayJoined(data.joined);
+                loadMyAssignments();
+                const teaches = data.role === 'teacher' || data.role === 'admin';
+                ClassesUI.setVisible('teaching-section', teaches);
+                if (teaches) {
+                    loadAssignmentForm();
+                    ClassesUI.displayTeaching(data.teaching, {
+                        onSelect: loadStudents,
+                        onRegenerate: regenerateInviteCode
+                    });
+                }
+            })
+            .catch(error => console.error('Error fetching classes:', error));
+    }
+    
+    // Load and display the assignments of the classes the user has joined
+    function loadMyAssignments() {
+        ClassesAPI.fetchMyAssignments()
+            .then(data => ClassesUI.displayMyAssignments(data.assignments))
+            .catch(error => console.error('Error fetching assignments:', error));
+    }
+    
+    // Load the scenarios and languages the assignment form offers, once
+    function loadAssignmentForm() {
+        if (assignmentFormLoaded) {
+            return;
+        }
+        assignmentFormLoaded = true;
+        Promise.all([ClassesAPI.fetchScenarios(), ClassesAPI.fetchLanguages()])
+            .then(([scenarios, languages]) => ClassesUI.populateAssignmentForm(scenarios.scenarios, languages.languages))
+            .catch(error => console.error('Error fetching assignment options:', error));
+    }
+    
+    // Load and display the assignments of the selected class
+    function loadAssignments(taught) {
+        ClassesAPI.fetchAssignments(taught.id)
+            .then(data => ClassesUI.displayAssignments(taught, data.assignments, {
+                onSelect: loadAssignmentStudents,
+                onDelete: deleteAssignment
+            }))
+            .catch(error => console.error('Error fetching assignments:', error));
+    }
+    
+    // Load and display who has finished an assignment
+    function loadAssignmentStudents(assignment) {
+        ClassesAPI.fetchAssignmentStudents(assignment.id)
+            .then(data => ClassesUI.displayAssignmentStudents(data))
+            .catch(error => console.error('Error fetching assignment status:', error));
+    }
+    
+    // Delete an assignment after confirmation
+    function deleteAssignment(assignment) {
+        if (!confirm('Delete the assignment ' + assignment.title + '? Conversations already done are kept.')) {
+            return;
+        }
+        ClassesAPI.deleteAssignment(assignment.id)
+            .then(() => loadAssignments(selectedClass))
+            .catch(error => {
+                console.error('Error deleting assignment:', error);
+                alert('Unable to delete the assignment. Please try again.');
+            });
+    }
+    
+    // Load and display the roster of a class
+    function loadStudents(taught) {
+        selectedClass = taught;
+        ClassesUI.setVisible('student-section', false);
+        loadAssignments(taught);
+        ClassesAPI.fetchStudents(taught.id)
+            .then(data => ClassesUI.displayStudents(data, {
+                onSelect: loadStudent,
+                onRemove: removeStudent
+            }))
+            .catch(error => console.error('Error fetching students:', error));
+    }
+    
+    // Load and display a student's errors and recent conversations
+    function loadStudent(student) {
+        Promise.all([
+            ClassesAPI.fetchStudentErrors(student.user_id),
+            ClassesAPI.fetchStudentConversations(student.user_id, 1)
+        ])
+            .then(([errors, conversations]) => ClassesUI.displayStudent(student, errors, conversations))
+            .catch(error => console.error('Error fetching student work:', error));
+    }
+    
+    // Remove a student from the selected class after confirmation
+    function removeStudent(student) {
+        if (!confirm('Remove ' + student.name + ' from ' + selectedClass.name + '?')) {
+            return;
+        }
+        ClassesAPI.removeStudent(selectedClass.id, student.user_id)
+            .then(() => {
+                loadStudents(selectedClass);
+                loadClasses();
+            })
+            .catch(error => {
+                console.error('Error removing student:', error);
+                alert('Unable to remove the student. Please try again.');
+            });
+    }
+    
+    // Replace the invite code of a class after confirmation, since the old one stops working
+    function regenerateInviteCode(taught) {
+        if (!confirm('Create a new invite code for ' + taught.name + '? The old code will stop working.')) {
+            return;
+        }
+        ClassesAPI.regenerateInviteCode(taught.id)
+            .then(() => loadClasses())
+            .catch(error => {
+                console.error('Error regenerating invite code:', error);
+                alert('Unable to create a new invite code. Please try again.');
+            });
+    }
+    
+    document.getElementById('join-class-form').addEventListener('submit', function(event) {
+        event.preventDefault();
+        const input = document.getElementById('invite-code');
+        const errorText = document.getElementById('join-class-error');
+        errorText.textContent = '';
+        ClassesAPI.joinClass(input.value.trim())
+            .then(() => {
+                input.value = '';
+                loadClasses();
+            })
+            .catch(error => {
+                errorText.textContent = error.message;
+            });
+    });
+    
+    document.getElementById('create-class-form').addEventListener('submit', function(event) {
+        event.preventDefault();
+        const input = document.getElementById('class-name');
+        ClassesAPI.createClass(input.value.trim())
+            .then(() => {
+                input.value = '';
+                loadClasses();
+            })
+            .catch(error => {
+                console.error('Error creating class:', error);
+                alert('Unable to create the class. Please try again.');
+            });
+    });
+    
+    document.getElementById('assignment-mode').addEventListener('change', function() {
+        ClassesUI.updateAssignmentMode();
+    });
+    
+    document.getElementById('create-assignment-form').addEventListener('submit', function(event) {
+        event.preventDefault();
+        const errorText = document.getElementById('create-assignment-error');
+        errorText.textContent = '';
+        ClassesAPI.createAssignment(selectedClass.id, ClassesUI.getAssignmentForm())
+            .then(() => {
+                ClassesUI.resetAssignmentForm();
+                loadAssignments(selectedClass);
+            })
+            .catch(error => {
+                errorText.textContent = error.message;
+            });
+    });
+    
+    loadClasses();
+});
@@ -0,0 +1,336 @@
+// UI functions for classes, their rosters and their students' work
+const ClassesUI = {
+    // Number of error types shown for a student
+    maxErrors: 3,
+
+    // Show or hide a section
+    setVisible: function(id, visible) {
+        document.getElementById(id).classList.toggle('d-none', !visible);
+    },
+
+    // Build a table cell with text
+    cell: function(text, className) {
+        const cell = document.createElement('td');
+        if (className) {
+            cell.className = className;
+        }
+        cell.textContent = text;
+        return cell;
+    },
+
+    // Build a small button with an icon
+    button: function(className, icon, title, onClick) {
+        const button = document.createElement('button');
+        button.className = 'btn btn-sm ' + className;
+        button.title = title;
+        button.innerHTML = '<i class="fas ' + icon + '"></i>';
+        button.addEventListener('click', onClick);
+        return button;
+    },
+
+    // Display the classes the user is a student of
+    displayJoined: function(classes) {
+        const list = document.getElementById('joined-classes');
+        list.innerHTML = '';
+        
+        if (classes.length === 0) {
+            list.innerHTML = '<div class="text-muted small">You have not joined a class yet.</div>';
+            return;
+        }
+        classes.forEach(joined => {
+            const item = document.createElement('div');
+            item.className = 'list-group-item d-flex justify-content-between';
+            const name = document.createElement('strong');
+            name.textContent = joined.name;
+            const teacher = document.createElement('span');
+            teacher.className = 'text-muted small';
+            teacher.textContent = 'Teacher: ' + joined.teacher_name;
+            item.appendChild(name);
+            item.appendChild(teacher);
+            list.appendChild(item);
+        });
+    },
+
+    // Display the classes the user teaches with their invite codes
+    displayTeaching: function(classes, handlers) {
+        const list = document.getElementById('teaching-list');
+        list.innerHTML = '';
+        
+        if (classes.length === 0) {
+            list.innerHTML = '<tr><td colspan="4" class="text-center text-muted py-3">Create a class and share its invite code with your students.</td></tr>';
+            return;
+        }
+        classes.forEach(taught => {
+            const row = document.createElement('tr');
+            row.appendChild(this.cell(taught.name));
+            
+            const code = this.cell(taught.invite_code, 'font-monospace');
+            code.appendChild(document.createTextNode(' '));
+            code.appendChild(this.button('btn-link p-0', 'fa-sync-alt', 'New invite code', () => handlers.onRegenerate(taught)));
+            row.appendChild(code);
+            
+            row.appendChild(this.cell(taught.student_count, 'text-end'));
+            
+            const actions = document.createElement('td');
+            actions.className = 'text-end';
+            actions.appendChild(this.button('btn-outline-primary', 'fa-users', 'Show students', () => handlers.onSelect(taught)));
+            row.appendChild(actions);
+            list.appendChild(row);
+        });
+    },
+
+    // Display the roster of a class
+    displayStudents: function(data, handlers) {
+        document.getElementById('roster-title').textContent = 'Students of ' + data.class.name;
+        const list = document.getElementById('roster-list');
+        list.innerHTML = '';
+        
+        if (data.students.length === 0) {
+            list.innerHTML = '<tr><td colspan="5" class="text-center text-muted py-3">No students yet. Share the invite code ' + data.class.invite_code + ' with your class.</td></tr>';
+        }
+        data.students.forEach(student => {
+            const row = document.createElement('tr');
+            row.appendChild(this.cell(student.name));
+            row.appendChild(this.cell(student.email, 'small'));
+            row.appendChild(this.cell(student.conversation_count, 'text-end'));
+            row.appendChild(this.cell(student.last_conversation_at
+                ? new Date(student.last_conversation_at).toLocaleDateString()
+                : '-', 'text-end'));
+            
+            const actions = document.createElement('td');
+            actions.className = 'text-end text-nowrap';
+            actions.appendChild(this.button('btn-outline-primary me-1', 'fa-comments', 'Show work', () => handlers.onSelect(student)));
+            actions.appendChild(this.button('btn-outline-danger', 'fa-user-minus', 'Remove from class', () => handlers.onRemove(student)));
+            row.appendChild(actions);
+            list.appendChild(row);
+        });
+        this.setVisible('roster-section', true);
+    },
+
+    // Labels and badge colours of assignment statuses
+    statuses: {
+        completed: { label: 'Completed', className: 'bg-success' },
+        late: { label: 'Completed late', className: 'bg-warning text-dark' },
+        in_progress: { label: 'In progress', className: 'bg-info text-dark' },
+        not_started: { label: 'Not started', className: 'bg-secondary' },
+        overdue: { label: 'Overdue', className: 'bg-danger' }
+    },
+
+    // Build a badge for an assignment status; unfinished work past its due date is overdue
+    statusBadge: function(assignment, progress) {
+        let status = progress.status;
+        if ((status === 'not_started' || status === 'in_progress') && new Date(assignment.due_at) < new Date()) {
+            status = 'overdue';
+        }
+        const badge = document.createElement('span');
+        badge.className = 'badge ' + this.statuses[status].className;
+        badge.textContent = this.statuses[status].label;
+        return badge;
+    },
+
+    // Describe what an assignment asks for
+    describeAssignment: function(assignment) {
+        const task = assignment.mode === 'scenario'
+            ? 'Scenario: ' + (assignment.scenario_title || 'no longer available')
+            : 'Topic: ' + assignment.topic;
+        return task + ' · at least ' + assignment.min_turns + ' turns';
+    },
+
+    // Display the assignments of the classes the user has joined with a link to start each
+    displayMyAssignments: function(assignments) {
+        const list = document.getElementById('my-assignments');
+        list.innerHTML = '';
+        
+        if (assignments.length === 0) {
+            list.innerHTML = '<div class="text-muted small">No assignments yet.</div>';
+            return;
+        }
+        assignments.forEach(assignment => {
+            const item = document.createElement('div');
+            item.className = 'list-group-item';
+            
+            const header = document.createElement('div');
+            header.className = 'd-flex justify-content-between align-items-center';
+            const title = document.createElement('strong');
+            title.textContent = assignment.title;
+            header.appendChild(title);
+            header.appendChild(this.statusBadge(assignment, assignment.progress));
+            item.appendChild(header);
+            
+            const details = document.createElement('div');
+            details.className = 'text-muted small';
+            details.textContent = assignment.class_name + ' · due ' + new Date(assignment.due_at).toLocaleString() + ' · ' + this.describeAssignment(assignment);
+            item.appendChild(details);
+            
+            const done = assignment.progress.status === 'completed' || assignment.progress.status === 'late';
+            const start = document.createElement('a');
+            start.className = 'btn btn-sm mt-2 ' + (done ? 'btn-outline-primary' : 'btn-primary');
+            start.href = '/conversation?assignment=' + assignment.id;
+            start.innerHTML = '<i class="fas fa-microphone"></i> ' + (done ? 'Practise again' : 'Start');
+            item.appendChild(start);
+            list.appendChild(item);
+        });
+    },
+
+    // Fill the scenario and language pickers of the assignment form
+    populateAssignmentForm: function(scenarios, languages) {
+        const scenarioSelect = document.getElementById('assignment-scenario');
+        scenarioSelect.innerHTML = '';
+        scenarios.forEach(scenario => {
+            const option = document.createElement('option');
+            option.value = scenario.id;
+            option.textContent = scenario.title;
+            scenarioSelect.appendChild(option);
+        });
+        
+        const languageSelect = document.getElementById('assignment-language');
+        languageSelect.innerHTML = '';
+        languages.forEach(language => {
+            const option = document.createElement('option');
+            option.value = language.code;
+            option.textContent = language.name;
+            languageSelect.appendChild(option);
+        });
+        this.updateAssignmentMode();
+    },
+
+    // Show the topic or the scenario field for the selected type; scenarios are in English only
+    updateAssignmentMode: function() {
+        const scenario = document.getElementById('assignment-mode').value === 'scenario';
+        this.setVisible('assignment-topic-group', !scenario);
+        this.setVisible('assignment-scenario-group', scenario);
+        const languageSelect = document.getElementById('assignment-language');
+        if (scenario) {
+            languageSelect.value = 'en';
+        }
+        languageSelect.disabled = scenario;
+    },
+
+    // Read the assignment form
+    getAssignmentForm: function() {
+        const mode = document.getElementById('assignment-mode').value;
+        const scenarioId = parseInt(document.getElementById('assignment-scenario').value, 10);
+        return {
+            title: document.getElementById('assignment-title').value.trim(),
+            mode: mode,
+            scenario_id: mode === 'scenario' && !isNaN(scenarioId) ? scenarioId : null,
+            topic: mode === 'conversation' ? document.getElementById('assignment-topic').value.trim() : '',
+            language: document.getElementById('assignment-language').value,
+            min_turns: parseInt(document.getElementById('assignment-min-turns').value, 10),
+            due_at: new Date(document.getElementById('assignment-due').value).toISOString()
+        };
+    },
+
+    // Clear the assignment form after an assignment is created
+    resetAssignmentForm: function() {
+        document.getElementById('assignment-title').value = '';
+        document.getElementById('assignment-topic').value = '';
+        document.getElementById('assignment-due').value = '';
+    },
+
+    // Display the assignments of the selected class
+    displayAssignments: function(taught, assignments, handlers) {
+        document.getElementById('assignments-title').textContent = 'Assignments of ' + taught.name;
+        const list = document.getElementById('assignment-list');
+        list.innerHTML = '';
+        
+        if (assignments.length === 0) {
+            list.innerHTML = '<tr><td colspan="4" class="text-center text-muted py-3">No assignments yet.</td></tr>';
+        }
+        assignments.forEach(assignment => {
+            const row = document.createElement('tr');
+            const title = this.cell(assignment.title);
+            const details = document.createElement('div');
+            details.className = 'text-muted small';
+            details.textContent = this.describeAssignment(assignment);
+            title.appendChild(details);
+            row.appendChild(title);
+            row.appendChild(this.cell(new Date(assignment.due_at).toLocaleString(), 'small'));
+            row.appendChild(this.cell(assignment.completed_count + ' / ' + assignment.student_count, 'text-end'));
+            
+            const actions = document.createElement('td');
+            actions.className = 'text-end text-nowrap';
+            actions.appendChild(this.button('btn-outline-primary me-1', 'fa-tasks', 'Show status', () => handlers.onSelect(assignment)));
+            actions.appendChild(this.button('btn-outline-danger', 'fa-trash', 'Delete assignment', () => handlers.onDelete(assignment)));
+            row.appendChild(actions);
+            list.appendChild(row);
+        });
+        this.setVisible('assignment-status-section', false);
+        this.setVisible('assignments-section', true);
+    },
+
+    // Display who has and has not finished an assignment
+    displayAssignmentStudents: function(data) {
+        document.getElementById('assignment-status-title').textContent = 'Status of ' + data.assignment.title;
+        const list = document.getElementById('assignment-status-list');
+        list.innerHTML = '';
+        
+        if (data.students.length === 0) {
+            list.innerHTML = '<tr><td colspan="5" class="text-center text-muted py-3">The class has no students yet.</td></tr>';
+        }
+        data.students.forEach(student => {
+            const row = document.createElement('tr');
+            row.appendChild(this.cell(student.name));
+            const status = document.createElement('td');
+            status.appendChild(this.statusBadge(data.assignment, student.progress));
+            row.appendChild(status);
+            row.appendChild(this.cell(student.progress.attempts, 'text-end'));
+            row.appendChild(this.cell(student.progress.best_turns, 'text-end'));
+            row.appendChild(this.cell(student.progress.completed_at
+                ? new Date(student.progress.completed_at).toLocaleString()
+                : '-', 'text-end small'));
+            list.appendChild(row);
+        });
+        this.setVisible('assignment-status-section', true);
+    },
+
+    // Display a student's most frequent errors and conversations, which open in the analysis page
+    displayStudent: function(student, errors, conversations) {
+        document.getElementById('student-title').textContent = student.name;
+        
+        const insights = document.getElementById('student-insights');
+        insights.innerHTML = '';
+        const frequent = errors.errors.slice(0, this.maxErrors);
+        if (frequent.length === 0) {
+            insights.innerHTML = '<li class="text-muted">No errors recorded yet.</li>';
+        }
+        frequent.forEach(stat => {
+            const item = document.createElement('li');
+            item.textContent = stat.label + ': ' + Math.round(stat.rate * 100) + '% of ' + errors.sentences + ' sentences';
+            insights.appendChild(item);
+        });
+        
+        const list = document.getElementById('student-conversations');
+        list.innerHTML = '';
+        if (conversations.conversations.length === 0) {
+            list.innerHTML = '<div class="text-muted small">No conversations yet.</div>';
+        }
+        conversations.conversations.forEach(conversation => {
+            const link = document.createElement('a');
+            link.className = 'list-group-item list-group-item-action';
+            link.href = '/conversation-analysis?student=' + student.user_id + '&conversation=' + conversation.id;
+            
+            const header = document.createElement('div');
+            header.className = 'd-flex justify-content-between';
+            const date = document.createElement('strong');
+            date.textContent = new Date(conversation.created_at).toLocaleString();
+            header.appendChild(date);
+            if (conversation.level) {
+                const level = document.createElement('span');
+                level.className = 'badge bg-primary';
+                level.textContent = conversation.level;
+                header.appendChild(level);
+            }
+            link.appendChild(header);
+            
+            const preview = document.createElement('div');
+            preview.className = 'text-muted small text-truncate';
+            preview.textContent = conversation.preview;
+            link.appendChild(preview);
+            list.appendChild(link);
+        });
+        this.setVisible('student-section', true);
+    }
+};
+
+export { ClassesUI };
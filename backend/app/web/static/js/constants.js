@@ -4,6 +4,8 @@ export const CONSTANTS = {
     MAX_SENTENCES: 4,
     AUDIO_SAMPLE_RATE: 44100,
     HELLO_SOUND_URL: "/static/audio/hello.mp3",
+    WEBSOCKET_PATH: "/api/conversation/ws",
+    AUDIO_CHUNK_INTERVAL: 250, // milliseconds between streamed audio chunks
     // UI States
     UI_STATES: {
         READY: 'ready',
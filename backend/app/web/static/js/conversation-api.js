@@ -1,10 +1,118 @@
 import { ConversationState } from './conversation-state.js';
 import { ConversationUI } from './conversation-ui.js';
 import { ConversationRecording } from './conversation-recording.js';
+import { ConversationSocket } from './conversation-socket.js';
 import { CONSTANTS } from './constants.js';
 
 // API communication for conversation
 export const ConversationAPI = {
+    // Function to load the scenarios a conversation can be set in
+    fetchScenarios: function() {
+        return fetch('/api/scenarios', {
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to load scenarios');
+            }
+            return response.json();
+        })
+        .then(data => data.scenarios);
+    },
+
+    // Function to load the languages a user can practise and the one they have chosen
+    fetchLanguages: function() {
+        return fetch('/api/languages', {
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to load languages');
+            }
+            return response.json();
+        });
+    },
+
+    // Function to save the language the user practises in new conversations
+    saveLanguage: function(language) {
+        return fetch('/api/user/language', {
+            method: 'PUT',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify({ language: language }),
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to save language');
+            }
+            return response.json();
+        });
+    },
+
+    // Function to save the native language corrections are explained in, or '' for none
+    saveNativeLanguage: function(nativeLanguage) {
+        return fetch('/api/user/native-language', {
+            method: 'PUT',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify({ native_language: nativeLanguage }),
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to save native language');
+            }
+            return response.json();
+        });
+    },
+
+    // Function to load an assignment of one of the user's classes
+    fetchAssignment: function(assignmentId) {
+        return fetch('/api/assignments', {
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to load assignments');
+            }
+            return response.json();
+        })
+        .then(data => {
+            const assignment = data.assignments.find(a => a.id === assignmentId);
+            if (!assignment) {
+                throw new Error('Assignment not found');
+            }
+            return assignment;
+        });
+    },
+
+    // Function to open a server-side conversation session in a language, optionally set in a
+    // scenario or for an assignment, which then decides both
+    startSession: function(scenarioId, language, assignmentId) {
+        return fetch('/api/conversation/start', {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify({ scenario_id: scenarioId, language: language, assignment_id: assignmentId }),
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to start conversation');
+            }
+            return response.json();
+        })
+        .then(data => {
+            ConversationState.setSessionId(data.session_id);
+            ConversationState.setConversationHistory(data.history);
+            return data;
+        });
+    },
+
     // Function to send MP3 to server
     sendToServer: function(mp3Blob) {
         // Check if we should skip processing (for immediate end conversation)
@@ -15,7 +123,7 @@ export const ConversationAPI = {
         
         const formData = new FormData();
         formData.append('audio', mp3Blob, 'recording.mp3');
-        formData.append('history', JSON.stringify(ConversationState.getConversationHistory()));
+        formData.append('session_id', ConversationState.getSessionId());
         
         // Add a temporary user message with "Processing..." indicator
         ConversationState.addToConversationHistory({
@@ -54,8 +162,9 @@ export const ConversationAPI = {
             // Update the message display
             ConversationUI.updateMessageDisplay();
             
-            // Save conversation to sessionStorage for the analysis page
-            sessionStorage.setItem('currentConversation', JSON.stringify(ConversationState.getConversationHistory()));
+            if (data.scenario_completed) {
+                ConversationUI.showScenarioComplete(data.scenario_reason);
+            }
             
             // Check if we need to end the conversation after processing
             if (ConversationState.getShouldEndAfterProcessing()) {
@@ -147,15 +256,15 @@ export const ConversationAPI = {
         // Update UI state to show we're processing the end conversation request
         ConversationUI.updateUIState(CONSTANTS.UI_STATES.PROCESSING);
         
-        // Save conversation to sessionStorage for the analysis page
-        sessionStorage.setItem('currentConversation', JSON.stringify(ConversationState.getConversationHistory()));
+        // No more turns will be streamed
+        ConversationSocket.close();
         
         fetch('/api/conversation/end', {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json',
             },
-            body: JSON.stringify({ history: ConversationState.getConversationHistory() }),
+            body: JSON.stringify({ session_id: ConversationState.getSessionId() }),
             credentials: 'include'
         })
         .then(response => {
@@ -2,6 +2,7 @@ import { ConversationState } from './conversation-state.js';
 import { ConversationUI } from './conversation-ui.js';
 import { ConversationRecording } from './conversation-recording.js';
 import { ConversationAPI } from './conversation-api.js';
+import { ConversationSocket } from './conversation-socket.js';
 import { CONSTANTS } from './constants.js';
 
 // Main application logic for conversation
@@ -12,6 +13,34 @@ document.addEventListener('DOMContentLoaded', function() {
     // Initialize UI state
     ConversationUI.updateUIState(CONSTANTS.UI_STATES.READY);
     
+    // Offer the languages; the server falls back to the saved ones if they fail to load
+    ConversationAPI.fetchLanguages()
+        .then(data => {
+            ConversationUI.populateLanguages(data.languages, data.language, language => {
+                ConversationAPI.saveLanguage(language)
+                    .catch(error => console.error("Error saving language:", error));
+            });
+            ConversationUI.populateNativeLanguages(data.native_languages, data.native_language, nativeLanguage => {
+                ConversationAPI.saveNativeLanguage(nativeLanguage)
+                    .catch(error => console.error("Error saving native language:", error));
+            });
+        })
+        .catch(error => console.error("Error loading languages:", error));
+    
+    // Offer the scenarios; a free conversation is still possible if they fail to load
+    ConversationAPI.fetchScenarios()
+        .then(scenarios => ConversationUI.populateScenarios(scenarios))
+        .catch(error => console.error("Error loading scenarios:", error));
+    
+    // A conversation opened from an assignment (?assignment=ID) is linked to it
+    const assignmentParam = parseInt(new URLSearchParams(window.location.search).get('assignment'), 10);
+    const assignmentId = isNaN(assignmentParam) ? null : assignmentParam;
+    if (assignmentId !== null) {
+        ConversationAPI.fetchAssignment(assignmentId)
+            .then(assignment => ConversationUI.showAssignment(assignment))
+            .catch(error => console.error("Error loading assignment:", error));
+    }
+    
     // Event handler for the start/stop button
     ConversationUI.elements.startButton.addEventListener('click', function() {
         if (ConversationState.getIsRecording()) {
@@ -40,19 +69,34 @@ document.addEventListener('DOMContentLoaded', function() {
         try {
             ConversationUI.updateUIState(CONSTANTS.UI_STATES.PROCESSING);
             
-            // If it's the first turn, add the hello message immediately
+            // If it's the first turn, open a session and show its greeting
+            let openingAudio = null;
+            let inScenario = false;
             if (ConversationState.getIsFirstTurn()) {
-                ConversationState.addToConversationHistory({
-                    role: 'assistant',
-                    content: "Hello! What would you like to talk about today?"
-                });
+                const scenarioId = ConversationUI.getSelectedScenarioId();
+                const language = ConversationUI.getSelectedLanguage();
+                const session = await ConversationAPI.startSession(scenarioId, language, assignmentId);
+                openingAudio = session.opening_audio_base64 || null;
+                inScenario = session.scenario !== null;
                 
+                ConversationUI.lockScenario();
                 ConversationUI.updateMessageDisplay();
                 ConversationState.setIsFirstTurn(false);
             }
             
-            // Play the hello sound
-            await ConversationUI.playHelloSound();
+            // Connect the streaming socket, falling back to per-turn uploads if it fails
+            try {
+                await ConversationSocket.connect();
+            } catch (error) {
+                console.warn("Streaming unavailable, falling back to uploads:", error);
+            }
+            
+            // Speak the scenario's opening line, or play the hello sound
+            if (openingAudio) {
+                await ConversationUI.playOpeningAudio(openingAudio);
+            } else if (!inScenario) {
+                await ConversationUI.playHelloSound();
+            }
             await ConversationRecording.startRecordingProcess();
         } catch (error) {
             console.error("Error starting conversation:", error);
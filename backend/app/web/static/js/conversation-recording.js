@@ -1,11 +1,13 @@
 import { ConversationState } from './conversation-state.js';
 import { ConversationUI } from './conversation-ui.js';
 import { ConversationAPI } from './conversation-api.js';
+import { ConversationSocket } from './conversation-socket.js';
 import { CONSTANTS } from './constants.js';
 
 // Recording functionality for conversation
 export const ConversationRecording = {
     audioContext: null,
+    isStreamingTurn: false,
     
     // Function to stop recording and cleanup
     stopRecordingAndCleanup: function() {
@@ -79,12 +81,22 @@ export const ConversationRecording = {
             // Reset audio chunks
             ConversationState.resetAudioChunks();
             
+            // Stream the audio over the WebSocket when it is connected
+            this.isStreamingTurn = ConversationSocket.isOpen();
+            if (this.isStreamingTurn) {
+                ConversationSocket.startTurn(mediaRecorder.mimeType);
+            }
+            
             // Event handler for when data is available
             mediaRecorder.ondataavailable = (event) => {
                 if (event.data.size > 0) {
                     const audioChunks = ConversationState.getAudioChunks();
                     audioChunks.push(event.data);
                     ConversationState.setAudioChunks(audioChunks);
+                    
+                    if (this.isStreamingTurn) {
+                        ConversationSocket.sendChunk(event.data);
+                    }
                 }
             };
             
@@ -99,17 +111,26 @@ export const ConversationRecording = {
                     return;
                 }
                 
-                // Create a blob from the audio chunks
-                const audioBlob = new Blob(ConversationState.getAudioChunks(), { type: 'audio/wav' });
-                
-                // Convert to MP3
-                this.convertToMp3(audioBlob);
+                if (this.isStreamingTurn) {
+                    // All chunks have been sent, let the server process the turn
+                    ConversationSocket.endTurn();
+                } else {
+                    // Create a blob from the audio chunks
+                    const audioBlob = new Blob(ConversationState.getAudioChunks(), { type: 'audio/wav' });
+                    
+                    // Convert to MP3
+                    this.convertToMp3(audioBlob);
+                }
                 
                 ConversationState.setIsRecording(false);
             };
             
-            // Start recording
-            mediaRecorder.start();
+            // Start recording, emitting chunks periodically when streaming
+            if (this.isStreamingTurn) {
+                mediaRecorder.start(CONSTANTS.AUDIO_CHUNK_INTERVAL);
+            } else {
+                mediaRecorder.start();
+            }
             
             // Set a timeout to automatically stop recording after 30 seconds
             const recordingTimeout = setTimeout(() => {
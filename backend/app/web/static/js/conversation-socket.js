@@ -0,0 +1,249 @@
+import { ConversationState } from './conversation-state.js';
+import { ConversationUI } from './conversation-ui.js';
+import { ConversationRecording } from './conversation-recording.js';
+import { ConversationAPI } from './conversation-api.js';
+import { CONSTANTS } from './constants.js';
+
+// Streaming conversation turns over a WebSocket
+export const ConversationSocket = {
+    socket: null,
+    audioQueue: [],
+    isPlaying: false,
+    isTurnComplete: false,
+
+    // Open the WebSocket connection
+    connect: function() {
+        return new Promise((resolve, reject) => {
+            if (this.isOpen()) {
+                resolve();
+                return;
+            }
+
+            const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
+            const socket = new WebSocket(protocol + '//' + window.location.host + CONSTANTS.WEBSOCKET_PATH);
+
+            socket.onopen = () => {
+                this.socket = socket;
+                resolve();
+            };
+            socket.onerror = (error) => {
+                console.error('WebSocket error:', error);
+                reject(new Error('WebSocket connection failed'));
+            };
+            socket.onclose = () => {
+                this.socket = null;
+                // Fail the turn that was in flight when the connection dropped
+                if (ConversationState.getConversationHistory().some(turn => turn.isProcessing || turn.isStreaming)) {
+                    this.failTurn('Connection lost');
+                }
+            };
+            socket.onmessage = (message) => {
+                this.handleEvent(JSON.parse(message.data));
+            };
+        });
+    },
+
+    // Check whether turns can be streamed
+    isOpen: function() {
+        return this.socket !== null && this.socket.readyState === WebSocket.OPEN;
+    },
+
+    // Close the WebSocket connection
+    close: function() {
+        if (this.socket) {
+            this.socket.close();
+            this.socket = null;
+        }
+    },
+
+    // Announce a new turn before the first audio chunk is sent
+    startTurn: function(mimeType) {
+        this.audioQueue = [];
+        this.isPlaying = false;
+        this.isTurnComplete = false;
+
+        this.socket.send(JSON.stringify({
+            type: 'turn_start',
+            session_id: ConversationState.getSessionId(),
+            mime_type: mimeType
+        }));
+    },
+
+    // Send a recorded audio chunk
+    sendChunk: function(blob) {
+        if (this.isOpen()) {
+            this.socket.send(blob);
+        }
+    },
+
+    // Finish the turn so the server starts processing it
+    endTurn: function() {
+        // Add a temporary user message with "Processing..." indicator
+        ConversationState.addToConversationHistory({
+            role: 'user',
+            content: 'Processing...',
+            isProcessing: true
+        });
+        ConversationUI.updateMessageDisplay();
+
+        this.socket.send(JSON.stringify({ type: 'turn_end' }));
+    },
+
+    // Find the user turn that is currently being processed
+    currentUserTurn: function() {
+        const history = ConversationState.getConversationHistory();
+        for (let i = history.length - 1; i >= 0; i--) {
+            if (history[i].role === 'user') {
+                return history[i];
+            }
+        }
+        return null;
+    },
+
+    // Find or create the assistant turn that is currently being streamed
+    currentAssistantTurn: function() {
+        const history = ConversationState.getConversationHistory();
+        const last = history[history.length - 1];
+        if (last && last.role === 'assistant' && last.isStreaming) {
+            return last;
+        }
+
+        const turn = { role: 'assistant', content: '', isStreaming: true };
+        ConversationState.addToConversationHistory(turn);
+        return turn;
+    },
+
+    // Handle an event sent by the server
+    handleEvent: function(event) {
+        switch (event.type) {
+            case 'ready':
+                if (event.user_name) {
+                    ConversationState.setUserName(event.user_name);
+                }
+                break;
+            case 'transcript': {
+                const turn = this.currentUserTurn();
+                if (turn) {
+                    turn.content = event.text;
+                    turn.user_name = ConversationState.getUserName();
+                    turn.pronunciation = event.pronunciation;
+                }
+                ConversationUI.updateMessageDisplay();
+                break;
+            }
+            case 'suggestion': {
+                const turn = this.currentUserTurn();
+                if (turn) {
+                    turn.suggestion = event.suggestion || '';
+                    turn.suggestion_diff = event.suggestion_diff;
+                    turn.explanation = event.explanation;
+                    delete turn.isProcessing;
+                }
+                ConversationUI.updateMessageDisplay();
+                break;
+            }
+            case 'assistant_token':
+                this.currentAssistantTurn().content += event.text;
+                ConversationUI.updateMessageDisplay();
+                break;
+            case 'assistant_done':
+                this.currentAssistantTurn().content = event.text;
+                ConversationUI.updateMessageDisplay();
+                break;
+            case 'audio':
+                this.audioQueue.push(event.audio_base64);
+                this.playNext();
+                break;
+            case 'tts_error':
+                console.error('TTS error:', event.error);
+                break;
+            case 'scenario_complete':
+                ConversationUI.showScenarioComplete(event.text);
+                break;
+            case 'turn_complete':
+                this.completeTurn(event);
+                break;
+            case 'error':
+                this.failTurn(event.error);
+                break;
+            default:
+                console.warn('Unknown WebSocket event:', event);
+        }
+    },
+
+    // Replace the streamed turns with the history confirmed by the server
+    completeTurn: function(event) {
+        ConversationState.setConversationHistory(event.history);
+        if (event.user_name) {
+            ConversationState.setUserName(event.user_name);
+        }
+        ConversationUI.updateMessageDisplay();
+
+        this.isTurnComplete = true;
+
+        // Check if we need to end the conversation after processing
+        if (ConversationState.getShouldEndAfterProcessing()) {
+            ConversationState.setShouldEndAfterProcessing(false);
+            ConversationAPI.endConversation();
+            return;
+        }
+
+        this.startNextTurnIfIdle();
+    },
+
+    // Drop the unfinished turn and show the error
+    failTurn: function(message) {
+        console.error('Error processing turn:', message);
+        const history = ConversationState.getConversationHistory().filter(turn => !turn.isProcessing && !turn.isStreaming);
+        ConversationState.setConversationHistory(history);
+        ConversationUI.updateMessageDisplay();
+
+        this.audioQueue = [];
+        ConversationUI.updateUIState(CONSTANTS.UI_STATES.READY);
+        ConversationUI.elements.statusIndicator.textContent = "Error: " + message;
+    },
+
+    // Play the queued sentences one after another
+    playNext: function() {
+        if (this.isPlaying) return;
+
+        const audioBase64 = this.audioQueue.shift();
+        if (audioBase64 === undefined) {
+            this.startNextTurnIfIdle();
+            return;
+        }
+
+        this.isPlaying = true;
+        ConversationUI.updateUIState(CONSTANTS.UI_STATES.PLAYING);
+
+        const audio = new Audio("data:audio/mp3;base64," + audioBase64);
+        const onFinished = () => {
+            this.isPlaying = false;
+            this.playNext();
+        };
+
+        audio.onended = onFinished;
+        audio.onerror = (e) => {
+            console.error("Audio playback failed:", e);
+            onFinished();
+        };
+        audio.play().catch(e => {
+            console.error("Audio play error:", e);
+            onFinished();
+        });
+    },
+
+    // Start recording again once the turn is complete and all audio has been played
+    startNextTurnIfIdle: function() {
+        if (!this.isTurnComplete || this.isPlaying || this.audioQueue.length > 0) return;
+        this.isTurnComplete = false;
+
+        ConversationUI.updateUIState(CONSTANTS.UI_STATES.PREPARING);
+        // Reset for next recording
+        ConversationState.resetAudioChunks();
+
+        setTimeout(() => {
+            ConversationRecording.startRecordingProcess();
+        }, 1000);
+    }
+};
@@ -6,6 +6,7 @@ export const ConversationState = {
     audioChunks: [],
     stream: null,
     conversationHistory: [],
+    sessionId: null,
     isFirstTurn: true,
     isRecording: false,
     userName: "You",
@@ -30,6 +31,8 @@ export const ConversationState = {
     getConversationHistory: function() { return this.conversationHistory; },
     setConversationHistory: function(history) { this.conversationHistory = history; },
     addToConversationHistory: function(turn) { this.conversationHistory.push(turn); },
+    getSessionId: function() { return this.sessionId; },
+    setSessionId: function(id) { this.sessionId = id; },
     removeLastFromConversationHistory: function() { return this.conversationHistory.pop(); },
     getIsFirstTurn: function() { return this.isFirstTurn; },
     setIsFirstTurn: function(value) { this.isFirstTurn = value; },
@@ -1,4 +1,6 @@
 import { ConversationState } from './conversation-state.js';
+import { ConversationUtils } from './shared-conversation-utils.js';
+import { VocabularyAPI } from './vocabulary-api.js';
 import { CONSTANTS } from './constants.js';
 
 // UI management for conversation
@@ -9,9 +11,18 @@ const ConversationUI = {
         endConversationButton: null,
         statusIndicator: null,
         conversationHistoryDiv: null,
-        audioPlayer: null
+        audioPlayer: null,
+        languageSelect: null,
+        nativeLanguageSelect: null,
+        scenarioSelect: null,
+        scenarioDescription: null,
+        scenarioComplete: null,
+        assignmentBrief: null
     },
 
+    // Lower-case words saved to the word bank during this conversation
+    savedWords: new Set(),
+
     // Initialize UI elements
     init: function() {
         this.elements.startButton = document.getElementById('startButton');
@@ -19,6 +30,12 @@ const ConversationUI = {
         this.elements.statusIndicator = document.getElementById('statusIndicator');
         this.elements.conversationHistoryDiv = document.getElementById('conversation-history');
         this.elements.audioPlayer = document.getElementById('audio-player');
+        this.elements.languageSelect = document.getElementById('languageSelect');
+        this.elements.nativeLanguageSelect = document.getElementById('nativeLanguageSelect');
+        this.elements.scenarioSelect = document.getElementById('scenarioSelect');
+        this.elements.scenarioDescription = document.getElementById('scenarioDescription');
+        this.elements.scenarioComplete = document.getElementById('scenarioComplete');
+        this.elements.assignmentBrief = document.getElementById('assignmentBrief');
         
         // Show initial placeholder if no conversation history
         if (ConversationState.getConversationHistory().length === 0) {
@@ -107,17 +124,18 @@ const ConversationUI = {
             
             const contentSpan = document.createElement('span');
             contentSpan.className = 'message-content';
-            contentSpan.textContent = turn.content;
+            if (turn.role === 'assistant') {
+                this.appendSavableWords(contentSpan, turn.content);
+            } else {
+                contentSpan.textContent = turn.content;
+            }
             
             messageDiv.appendChild(roleSpan);
             messageDiv.appendChild(contentSpan);
             
             // Add suggestion if available
             if (turn.suggestion !== undefined && turn.suggestion !== '') {
-                const suggestionDiv = document.createElement('div');
-                suggestionDiv.className = 'suggestion';
-                suggestionDiv.innerHTML = '<strong>Suggestion:</strong> ' + turn.suggestion;
-                messageDiv.appendChild(suggestionDiv);
+                messageDiv.appendChild(ConversationUtils.formatSuggestion(turn));
             } else if (turn.role === 'user' && turn.suggestion !== undefined) {
                 const positiveDiv = document.createElement('div');
                 positiveDiv.className = 'positive-feedback';
@@ -130,6 +148,11 @@ const ConversationUI = {
                 messageDiv.appendChild(processingDiv);
             }
             
+            // Add the speech analysis if available
+            if (turn.pronunciation) {
+                messageDiv.appendChild(ConversationUtils.formatPronunciation(turn.pronunciation));
+            }
+            
             this.elements.conversationHistoryDiv.appendChild(messageDiv);
         });
         
@@ -137,6 +160,187 @@ const ConversationUI = {
         this.elements.conversationHistoryDiv.scrollTop = this.elements.conversationHistoryDiv.scrollHeight;
     },
 
+    // Write Voxy's reply as clickable words; clicking one saves it to the word bank
+    // together with the sentence it appears in
+    appendSavableWords: function(container, text) {
+        const sentences = text.match(/[^.!?]*[.!?]+\s*|[^.!?]+$/g) || [];
+        sentences.forEach(sentence => {
+            sentence.split(/(\p{L}+(?:['’]\p{L}+)*)/u).forEach((part, i) => {
+                // Odd parts are the words captured by the split
+                if (i % 2 === 0) {
+                    container.appendChild(document.createTextNode(part));
+                    return;
+                }
+                const word = document.createElement('span');
+                word.className = 'savable-word';
+                word.textContent = part;
+                word.title = 'Save to word bank';
+                if (this.savedWords.has(part.toLowerCase())) {
+                    word.classList.add('saved-word');
+                }
+                word.addEventListener('click', () => this.saveWord(word, part, sentence.trim()));
+                container.appendChild(word);
+            });
+        });
+    },
+
+    // Save a word from Voxy's reply to the word bank and highlight it
+    saveWord: function(element, word, sentence) {
+        VocabularyAPI.saveWord(word, sentence, ConversationState.getSessionId())
+            .then(() => {
+                this.savedWords.add(word.toLowerCase());
+                element.classList.add('saved-word');
+                element.title = 'Saved to word bank';
+            })
+            .catch(error => {
+                console.error('Error saving word:', error);
+                element.title = 'Could not save this word';
+            });
+    },
+
+    // Fill the language picker, select the user's language and report changes.
+    // Scenarios are written in English, so they are only offered for English.
+    populateLanguages: function(languages, current, onChange) {
+        const select = this.elements.languageSelect;
+        if (!select) return;
+        
+        languages.forEach(language => {
+            const option = document.createElement('option');
+            option.value = language.code;
+            option.textContent = language.native_name === language.name
+                ? language.name
+                : language.name + ' (' + language.native_name + ')';
+            select.appendChild(option);
+        });
+        select.value = current;
+        this.updateScenarioAvailability();
+        
+        select.addEventListener('change', () => {
+            this.updateScenarioAvailability();
+            onChange(select.value);
+        });
+    },
+
+    // Fill the native language picker, select the user's native language and report changes.
+    // The explanations follow the saved setting, so it can be changed during a conversation.
+    populateNativeLanguages: function(nativeLanguages, current, onChange) {
+        const select = this.elements.nativeLanguageSelect;
+        if (!select) return;
+        
+        nativeLanguages.forEach(nativeLanguage => {
+            const option = document.createElement('option');
+            option.value = nativeLanguage.code;
+            option.textContent = nativeLanguage.name;
+            select.appendChild(option);
+        });
+        select.value = current || '';
+        
+        select.addEventListener('change', () => onChange(select.value));
+    },
+
+    // The code of the selected language, or null to use the saved one
+    getSelectedLanguage: function() {
+        const select = this.elements.languageSelect;
+        if (!select || select.value === '') return null;
+        return select.value;
+    },
+
+    // Offer scenarios only in English, falling back to a free conversation otherwise
+    updateScenarioAvailability: function() {
+        const select = this.elements.scenarioSelect;
+        if (!select) return;
+        const language = this.getSelectedLanguage();
+        const english = language === null || language === 'en';
+        if (!english && select.value !== '') {
+            select.value = '';
+            select.dispatchEvent(new Event('change'));
+        }
+        select.disabled = !english;
+    },
+
+    // Fill the scenario picker and describe the selected scenario
+    populateScenarios: function(scenarios) {
+        const select = this.elements.scenarioSelect;
+        if (!select) return;
+        
+        scenarios.forEach(scenario => {
+            const option = document.createElement('option');
+            option.value = scenario.id;
+            option.textContent = scenario.title;
+            select.appendChild(option);
+        });
+        
+        select.addEventListener('change', () => {
+            const scenario = scenarios.find(s => String(s.id) === select.value);
+            this.elements.scenarioDescription.textContent = scenario
+                ? scenario.description + ' Your goal: ' + scenario.learner_goal
+                : '';
+        });
+    },
+
+    // The ID of the selected scenario, or null for a free conversation
+    getSelectedScenarioId: function() {
+        const select = this.elements.scenarioSelect;
+        if (!select || select.value === '') return null;
+        return parseInt(select.value, 10);
+    },
+
+    // Lock the language and scenario once the conversation has started
+    lockScenario: function() {
+        if (this.elements.languageSelect) {
+            this.elements.languageSelect.disabled = true;
+        }
+        if (this.elements.scenarioSelect) {
+            this.elements.scenarioSelect.disabled = true;
+        }
+    },
+
+    // Describe the assignment being practised; its language and scenario are set by the
+    // teacher, so their pickers are hidden
+    showAssignment: function(assignment) {
+        const brief = this.elements.assignmentBrief;
+        if (!brief) return;
+        brief.innerHTML = '<i class="fas fa-tasks me-2"></i>';
+        const title = document.createElement('strong');
+        title.textContent = assignment.title;
+        brief.appendChild(title);
+        
+        const task = document.createElement('div');
+        task.className = 'small';
+        task.textContent = (assignment.mode === 'scenario'
+            ? 'Scenario: ' + (assignment.scenario_title || '')
+            : 'Topic: ' + assignment.topic)
+            + '. Take at least ' + assignment.min_turns + ' turns by ' + new Date(assignment.due_at).toLocaleString() + '.';
+        brief.appendChild(task);
+        brief.classList.remove('d-none');
+        
+        ['languagePicker', 'scenarioPicker'].forEach(id => {
+            const picker = document.getElementById(id);
+            if (picker) {
+                picker.classList.add('d-none');
+            }
+        });
+    },
+
+    // Congratulate the learner on reaching the scenario goal
+    showScenarioComplete: function(reason) {
+        const alert = this.elements.scenarioComplete;
+        if (!alert) return;
+        alert.innerHTML = '<i class="fas fa-trophy me-2"></i><strong>Goal reached!</strong> ';
+        alert.appendChild(document.createTextNode(reason || ''));
+        alert.classList.remove('d-none');
+    },
+
+    // Play the spoken opening line of a scenario
+    playOpeningAudio: function(audioBase64) {
+        return new Promise((resolve, reject) => {
+            const opening = new Audio("data:audio/mp3;base64," + audioBase64);
+            opening.onended = resolve;
+            opening.onerror = reject;
+            opening.play().catch(reject);
+        });
+    },
+
     // Play hello sound
     playHelloSound: function() {
         return new Promise((resolve, reject) => {
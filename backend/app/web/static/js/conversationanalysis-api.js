@@ -2,9 +2,10 @@ import { ConversationUtils } from './shared-conversation-utils.js';
 
 // API functions for conversation analysis
 const ConversationAnalysisAPI = {
-    // Function to fetch feedback from the server
-    fetchFeedback: function(conversationHistory) {
-        if (!conversationHistory || conversationHistory.length === 0) {
+    // Function to fetch the feedback report of a conversation, named by its ID or its session.
+    // The stored report is returned unless regenerate is set.
+    fetchFeedback: function(conversation, regenerate = false) {
+        if (!conversation || (!conversation.conversationId && !conversation.sessionId)) {
             return Promise.reject(new Error('No conversation available for feedback'));
         }
         
@@ -13,7 +14,11 @@ const ConversationAnalysisAPI = {
             headers: {
                 'Content-Type': 'application/json',
             },
-            body: JSON.stringify({ history: conversationHistory }),
+            body: JSON.stringify({
+                conversation_id: conversation.conversationId || 0,
+                session_id: conversation.sessionId || '',
+                regenerate: regenerate
+            }),
             credentials: 'include'
         })
         .then(response => {
@@ -24,6 +29,97 @@ const ConversationAnalysisAPI = {
         });
     },
 
+    // Function to fetch a conversation session recorded by the server
+    fetchSession: function(sessionId) {
+        return fetch('/api/conversation/sessions/' + encodeURIComponent(sessionId), {
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('No conversation found');
+            }
+            return response.json();
+        });
+    },
+
+    // Function to fetch a saved conversation by its ID
+    fetchConversation: function(conversationId) {
+        return fetch('/api/conversations/' + encodeURIComponent(conversationId), {
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('No conversation found');
+            }
+            return response.json();
+        });
+    },
+
+    // Function to fetch a saved conversation of a student, for their teacher
+    fetchStudentConversation: function(studentId, conversationId) {
+        return fetch('/api/teacher/students/' + encodeURIComponent(studentId) + '/conversations/' + encodeURIComponent(conversationId), {
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('No conversation found');
+            }
+            return response.json();
+        });
+    },
+
+    // Function to fetch the stored feedback report of a student's conversation, for their teacher
+    fetchStudentFeedback: function(studentId, conversationId) {
+        return fetch('/api/teacher/students/' + encodeURIComponent(studentId) + '/conversations/' + encodeURIComponent(conversationId) + '/feedback', {
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('No feedback report yet');
+            }
+            return response.json();
+        });
+    },
+
+    // Send a teacher's review of a student's conversation with the given method; path is
+    // the part of the URL after the conversation
+    sendReview: function(method, studentId, conversationId, path, body, errorMessage) {
+        const options = { method: method, credentials: 'include' };
+        if (body) {
+            options.headers = { 'Content-Type': 'application/json' };
+            options.body = JSON.stringify(body);
+        }
+        return fetch('/api/teacher/students/' + encodeURIComponent(studentId) + '/conversations/' + encodeURIComponent(conversationId) + path, options)
+        .then(response => {
+            if (!response.ok) {
+                return response.text().then(text => {
+                    throw new Error(text.trim() || errorMessage);
+                });
+            }
+            return response.status === 204 ? null : response.json();
+        });
+    },
+
+    // Function to comment on a student turn and approve or override its correction
+    saveAnnotation: function(studentId, conversationId, turnIndex, annotation) {
+        return this.sendReview('PUT', studentId, conversationId, '/turns/' + turnIndex + '/annotation', annotation, 'Failed to save annotation');
+    },
+
+    // Function to remove the annotation of a student turn
+    deleteAnnotation: function(studentId, conversationId, turnIndex) {
+        return this.sendReview('DELETE', studentId, conversationId, '/turns/' + turnIndex + '/annotation', null, 'Failed to delete annotation');
+    },
+
+    // Function to grade a student's conversation
+    saveGrade: function(studentId, conversationId, grade) {
+        return this.sendReview('PUT', studentId, conversationId, '/grade', grade, 'Failed to save grade');
+    },
+
+    // Function to remove the grade of a student's conversation
+    deleteGrade: function(studentId, conversationId) {
+        return this.sendReview('DELETE', studentId, conversationId, '/grade', null, 'Failed to remove grade');
+    },
+
     // Function to fetch the latest conversation
     fetchLatestConversation: function() {
         return fetch('/api/conversation/latest', {
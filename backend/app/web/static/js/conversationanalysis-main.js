@@ -4,44 +4,132 @@ import { ConversationAnalysisUI } from './conversationanalysis-ui.js';
 
 // Main application logic for conversation analysis
 document.addEventListener('DOMContentLoaded', function() {
-    // Try to get conversation from sessionStorage first
-    const conversationHistory = ConversationUtils.loadConversationFromStorage();
-    
-    if (conversationHistory && conversationHistory.length > 0) {
-        ConversationUtils.displayConversation(conversationHistory, 'conversation-history');
+    // Fetch and display the feedback report, grading it again if asked to
+    function loadFeedback(conversation, regenerate) {
+        ConversationAnalysisUI.showLoading('feedback-content', regenerate ? 'Regenerating feedback...' : 'Generating feedback...');
+        ConversationAnalysisUI.setRegenerateEnabled(false);
         
-        // Fetch and display feedback
-        ConversationAnalysisAPI.fetchFeedback(conversationHistory)
+        ConversationAnalysisAPI.fetchFeedback(conversation, regenerate)
             .then(data => {
-                ConversationAnalysisUI.displayFeedback(data.feedback);
+                ConversationAnalysisUI.displayFeedback(data.report, data.error_stats, data.grade);
+                ConversationAnalysisUI.displayGrade(data.grade);
+                ConversationAnalysisUI.displayReportInfo(data);
+                ConversationAnalysisUI.setRegenerateEnabled(true);
             })
             .catch(error => {
                 console.error('Error fetching feedback:', error);
                 ConversationAnalysisUI.showError('Unable to generate feedback at this time. Please try again later.', 'feedback-content');
+                ConversationAnalysisUI.setRegenerateEnabled(true);
             });
+    }
+    
+    // Display a conversation with the teacher's annotations and fetch its feedback
+    function showConversation(conversation, history, annotations) {
+        if (!history || history.length === 0) {
+            ConversationUtils.displayConversation([], 'conversation-history');
+            ConversationAnalysisUI.showError('No conversation available for feedback.', 'feedback-content');
+            return;
+        }
         
-        return;
+        ConversationUtils.displayConversation(history, 'conversation-history');
+        ConversationAnalysisUI.displayAnnotations(history, annotations, null);
+        
+        const regenerateButton = document.getElementById('regenerate-feedback');
+        if (regenerateButton) {
+            regenerateButton.addEventListener('click', () => loadFeedback(conversation, true));
+        }
+        
+        loadFeedback(conversation, false);
     }
     
-    // Fall back to API call if no conversation in sessionStorage
-    ConversationAnalysisAPI.fetchLatestConversation()
-        .then(history => {
-            if (history && history.length > 0) {
-                ConversationUtils.displayConversation(history, 'conversation-history');
-                
-                // Fetch and display feedback
-                ConversationAnalysisAPI.fetchFeedback(history)
-                    .then(data => {
-                        ConversationAnalysisUI.displayFeedback(data.feedback);
-                    })
-                    .catch(error => {
-                        console.error('Error fetching feedback:', error);
-                        ConversationAnalysisUI.showError('Unable to generate feedback at this time. Please try again later.', 'feedback-content');
+    // Display a student's conversation to their teacher with the report the student asked for,
+    // and let the teacher review its turns and grade it
+    function showStudentConversation(studentId, conversationId) {
+        // Display the conversation with its annotations and grade, which the teacher can edit
+        function loadReview() {
+            return ConversationAnalysisAPI.fetchStudentConversation(studentId, conversationId)
+                .then(data => {
+                    ConversationUtils.displayConversation(data.history, 'conversation-history');
+                    ConversationAnalysisUI.displayAnnotations(data.history, data.annotations, {
+                        onSave: (index, annotation) => ConversationAnalysisAPI.saveAnnotation(studentId, conversationId, index, annotation).then(loadReview),
+                        onDelete: index => ConversationAnalysisAPI.deleteAnnotation(studentId, conversationId, index).then(loadReview)
                     });
-            } else {
-                ConversationUtils.displayConversation([], 'conversation-history');
-                ConversationAnalysisUI.showError('No conversation available for feedback.', 'feedback-content');
+                    ConversationAnalysisUI.displayGrade(data.grade);
+                    ConversationAnalysisUI.displayGradeForm(data.grade);
+                });
+        }
+        
+        // Display the stored report, whose level the teacher's grade replaces
+        function loadStudentFeedback() {
+            ConversationAnalysisAPI.fetchStudentFeedback(studentId, conversationId)
+                .then(feedback => {
+                    ConversationAnalysisUI.displayFeedback(feedback.report, feedback.error_stats, feedback.grade);
+                    ConversationAnalysisUI.displayReportInfo(feedback);
+                })
+                .catch(error => {
+                    console.error('Error fetching student feedback:', error);
+                    ConversationAnalysisUI.showError('The student has not asked for feedback on this conversation yet.', 'feedback-content');
+                });
+        }
+        
+        // Reload both after the grade changes
+        function reloadGrade() {
+            loadReview().catch(error => console.error('Error fetching student conversation:', error));
+            loadStudentFeedback();
+        }
+        
+        document.getElementById('grade-form').addEventListener('submit', function(event) {
+            event.preventDefault();
+            ConversationAnalysisAPI.saveGrade(studentId, conversationId, ConversationAnalysisUI.getGradeForm())
+                .then(reloadGrade)
+                .catch(error => {
+                    console.error('Error saving grade:', error);
+                    alert('Unable to save the grade. Please try again.');
+                });
+        });
+        document.getElementById('remove-grade').addEventListener('click', function() {
+            if (!confirm('Remove your grade? The generated level will apply again.')) {
+                return;
             }
+            ConversationAnalysisAPI.deleteGrade(studentId, conversationId)
+                .then(reloadGrade)
+                .catch(error => {
+                    console.error('Error removing grade:', error);
+                    alert('Unable to remove the grade. Please try again.');
+                });
+        });
+        
+        loadReview()
+            .then(loadStudentFeedback)
+            .catch(error => {
+                console.error('Error fetching student conversation:', error);
+                ConversationUtils.displayConversation([], 'conversation-history');
+                ConversationAnalysisUI.showError('Unable to load conversation for feedback.', 'feedback-content');
+            });
+    }
+    
+    // Use the conversation or session named in the URL, or fall back to the latest conversation.
+    // Teachers open their students' conversations with the student parameter.
+    const params = new URLSearchParams(window.location.search);
+    const conversationId = params.get('conversation');
+    const sessionId = params.get('session');
+    const studentId = params.get('student');
+    if (studentId && conversationId) {
+        showStudentConversation(studentId, conversationId);
+        return;
+    }
+    let request;
+    if (conversationId) {
+        request = ConversationAnalysisAPI.fetchConversation(conversationId);
+    } else if (sessionId) {
+        request = ConversationAnalysisAPI.fetchSession(sessionId);
+    } else {
+        request = ConversationAnalysisAPI.fetchLatestConversation();
+    }
+    
+    request
+        .then(data => {
+            showConversation({ conversationId: data.conversation_id, sessionId: data.session_id }, data.history, data.annotations);
         })
         .catch(error => {
             console.error('Error fetching conversation:', error);
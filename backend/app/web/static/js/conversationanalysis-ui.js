@@ -1,43 +1,288 @@
 // UI functions for conversation analysis
 const ConversationAnalysisUI = {
-    // Format feedback with proper HTML structure
-    formatFeedback: function(feedbackText) {
-        if (!feedbackText) {
+    // Escape text from the model before inserting it as HTML
+    escapeHTML: function(text) {
+        const div = document.createElement('div');
+        div.textContent = text == null ? '' : String(text);
+        return div.innerHTML;
+    },
+
+    // Format a score from 0 to 100 as a labelled progress bar
+    formatScore: function(label, score, unit = '/100') {
+        return `
+            <div class="mb-2">
+                <div class="d-flex justify-content-between small">
+                    <span>${label}</span>
+                    <span>${score}${unit}</span>
+                </div>
+                <div class="progress" style="height: 8px;">
+                    <div class="progress-bar" role="progressbar" style="width: ${score}%"
+                         aria-valuenow="${score}" aria-valuemin="0" aria-valuemax="100"></div>
+                </div>
+            </div>
+        `;
+    },
+
+    // Format the structured feedback report. The teacher's grade, if any, replaces the
+    // generated level.
+    formatReport: function(report, grade) {
+        if (!report) {
             return '<p>No feedback available at this time.</p>';
         }
-        
-        // Split the feedback into sections based on common patterns
-        const sections = feedbackText.split(/\d+\.\s+/).filter(section => section.trim().length > 0);
-        
-        if (sections.length > 0) {
-            let html = '';
-            
-            sections.forEach((section, index) => {
-                const titleMatch = section.match(/^([^:]+):/);
-                const title = titleMatch ? titleMatch[1] : `Area ${index + 1}`;
-                const content = titleMatch ? section.replace(titleMatch[0], '').trim() : section.trim();
-                
-                html += `
-                    <div class="feedback-point">
-                        <h5>${title}</h5>
-                        <p>${content}</p>
-                    </div>
-                `;
+
+        const esc = this.escapeHTML;
+        const generated = grade && grade.level !== report.level
+            ? `<div class="small text-muted">Generated: ${esc(report.level)}</div>`
+            : '';
+        let html = `
+            <div class="d-flex align-items-center mb-3">
+                <div class="text-center me-3">
+                    <span class="badge bg-primary fs-4">${esc(grade ? grade.level : report.level)}</span>
+                    ${generated}
+                </div>
+                <p class="mb-0">${esc(report.summary)}</p>
+            </div>
+            <div class="feedback-point">
+                <h5>Scores</h5>
+                ${this.formatScore('Grammar', report.scores.grammar)}
+                ${this.formatScore('Vocabulary', report.scores.vocabulary)}
+                ${this.formatScore('Fluency', report.scores.fluency)}
+                ${this.formatScore('Coherence', report.scores.coherence)}
+            </div>
+        `;
+
+        if (report.recurring_errors.length > 0) {
+            html += '<div class="feedback-point"><h5>Recurring Errors</h5>';
+            report.recurring_errors.forEach(error => {
+                html += `<h6 class="mt-3">${esc(error.category)}</h6><p>${esc(error.description)}</p>`;
+                if (error.examples && error.examples.length > 0) {
+                    html += '<ul>';
+                    error.examples.forEach(example => {
+                        html += `<li><span class="text-danger">${esc(example.original)}</span> &rarr; <span class="text-success">${esc(example.corrected)}</span></li>`;
+                    });
+                    html += '</ul>';
+                }
             });
-            
-            return html;
+            html += '</div>';
         }
-        
-        // Fallback: just wrap the entire text in paragraphs
-        return `<p>${feedbackText.replace(/\n/g, '</p><p>')}</p>`;
+
+        if (report.focus_areas.length > 0) {
+            html += '<div class="feedback-point"><h5>Focus Areas</h5><ul>';
+            report.focus_areas.forEach(area => {
+                html += `<li>${esc(area)}</li>`;
+            });
+            html += '</ul></div>';
+        }
+
+        return html;
+    },
+
+    // Format the measured error rates of this conversation next to those of all conversations
+    formatErrorStats: function(errorStats) {
+        if (!errorStats || !errorStats.conversation || errorStats.conversation.errors.length === 0) {
+            return '';
+        }
+
+        const esc = this.escapeHTML;
+        const overallRates = {};
+        if (errorStats.overall) {
+            errorStats.overall.errors.forEach(stat => {
+                overallRates[stat.type] = stat.rate;
+            });
+        }
+
+        let html = '<div class="feedback-point"><h5>Error Types</h5>';
+        html += `<p class="small text-muted">Share of your ${errorStats.conversation.sentences} sentences in this conversation with each type of error</p>`;
+        errorStats.conversation.errors.forEach(stat => {
+            html += this.formatScore(esc(stat.label), Math.round(stat.rate * 100), '%');
+            if (overallRates[stat.type] !== undefined) {
+                html += `<p class="small text-muted mt-n1">${Math.round(overallRates[stat.type] * 100)}% across all your conversations</p>`;
+            }
+        });
+        html += '</div>';
+        return html;
     },
 
     // Display feedback in the UI
-    displayFeedback: function(feedback) {
+    displayFeedback: function(report, errorStats, grade) {
         const feedbackContent = document.getElementById('feedback-content');
         
         if (feedbackContent) {
-            feedbackContent.innerHTML = this.formatFeedback(feedback);
+            feedbackContent.innerHTML = this.formatReport(report, grade) + this.formatErrorStats(errorStats);
+        }
+    },
+
+    // Show the teacher's grade and comment, or hide it if the conversation has none
+    displayGrade: function(grade) {
+        const alert = document.getElementById('teacher-grade');
+        if (!alert) return;
+        
+        alert.classList.toggle('d-none', !grade);
+        if (!grade) return;
+        alert.innerHTML = '<i class="fas fa-chalkboard-teacher me-2"></i>';
+        const level = document.createElement('strong');
+        level.textContent = (grade.teacher_name || 'Teacher') + "'s grade: " + grade.level;
+        alert.appendChild(level);
+        if (grade.comment) {
+            alert.appendChild(document.createTextNode(' — ' + grade.comment));
+        }
+    },
+
+    // Fill the grading form with the current grade and show it to the teacher
+    displayGradeForm: function(grade) {
+        const form = document.getElementById('grade-form');
+        if (!form) return;
+        
+        document.getElementById('grade-level').value = grade ? grade.level : 'B1';
+        document.getElementById('grade-comment').value = grade ? grade.comment : '';
+        document.getElementById('remove-grade').classList.toggle('d-none', !grade);
+        form.classList.remove('d-none');
+    },
+
+    // Read the grading form
+    getGradeForm: function() {
+        return {
+            level: document.getElementById('grade-level').value,
+            comment: document.getElementById('grade-comment').value.trim()
+        };
+    },
+
+    // Build the teacher's annotation of a turn: their verdict on the correction and comment
+    formatAnnotation: function(annotation) {
+        const div = document.createElement('div');
+        div.className = 'annotation';
+        const label = document.createElement('strong');
+        label.textContent = (annotation.teacher_name || 'Teacher') + ':';
+        div.appendChild(label);
+        
+        if (annotation.correction_status === 'approved') {
+            div.appendChild(document.createTextNode(' ✓ Correction approved.'));
+        } else if (annotation.correction_status === 'overridden') {
+            div.appendChild(document.createTextNode(' Better: '));
+            const correction = document.createElement('span');
+            correction.className = 'annotation-correction';
+            correction.textContent = annotation.correction;
+            div.appendChild(correction);
+        }
+        if (annotation.comment) {
+            const comment = document.createElement('div');
+            comment.textContent = annotation.comment;
+            div.appendChild(comment);
+        }
+        return div;
+    },
+
+    // Add the teacher's annotations under the student turns of the displayed conversation.
+    // With handlers, the teacher can also review each student turn.
+    displayAnnotations: function(history, annotations, handlers) {
+        const container = document.getElementById('conversation-history');
+        if (!container) return;
+        
+        const byTurn = {};
+        (annotations || []).forEach(annotation => {
+            byTurn[annotation.turn_index] = annotation;
+        });
+        // The conversation is displayed one element per turn
+        Array.from(container.children).forEach((element, index) => {
+            const turn = history[index];
+            if (!turn || turn.role !== 'user') return;
+            
+            const annotation = byTurn[index];
+            if (annotation) {
+                element.appendChild(this.formatAnnotation(annotation));
+            }
+            if (handlers) {
+                const review = document.createElement('button');
+                review.className = 'btn btn-sm btn-link p-0';
+                review.innerHTML = '<i class="fas fa-pen"></i> ' + (annotation ? 'Edit review' : 'Review');
+                review.addEventListener('click', () => {
+                    review.remove();
+                    element.appendChild(this.formatAnnotationForm(index, annotation, handlers));
+                });
+                element.appendChild(review);
+            }
+        });
+    },
+
+    // Build the form a teacher reviews a student turn with
+    formatAnnotationForm: function(index, annotation, handlers) {
+        const form = document.createElement('form');
+        form.className = 'annotation';
+        form.innerHTML = `
+            <textarea class="form-control form-control-sm mb-2" rows="2" maxlength="2000" placeholder="Comment for the student"></textarea>
+            <div class="d-flex gap-2 mb-2">
+                <select class="form-select form-select-sm w-auto">
+                    <option value="">No verdict on the correction</option>
+                    <option value="approved">Approve the correction</option>
+                    <option value="overridden">Replace the correction</option>
+                </select>
+                <input type="text" class="form-control form-control-sm d-none" maxlength="1000" placeholder="Your correction, or the sentence as it was if it needed none">
+            </div>
+            <div class="d-flex gap-2">
+                <button type="submit" class="btn btn-sm btn-primary">Save</button>
+                <button type="button" class="btn btn-sm btn-outline-danger">Delete</button>
+            </div>
+            <div class="form-text text-danger"></div>
+        `;
+        const comment = form.querySelector('textarea');
+        const status = form.querySelector('select');
+        const correction = form.querySelector('input');
+        const remove = form.querySelector('button[type="button"]');
+        const errorText = form.querySelector('.form-text');
+        
+        comment.value = annotation ? annotation.comment : '';
+        status.value = annotation ? annotation.correction_status : '';
+        correction.value = annotation ? annotation.correction : '';
+        const updateCorrection = () => correction.classList.toggle('d-none', status.value !== 'overridden');
+        status.addEventListener('change', updateCorrection);
+        updateCorrection();
+        remove.classList.toggle('d-none', !annotation);
+        
+        const onError = error => {
+            errorText.textContent = error.message;
+        };
+        form.addEventListener('submit', event => {
+            event.preventDefault();
+            handlers.onSave(index, {
+                comment: comment.value.trim(),
+                correction_status: status.value,
+                correction: correction.value.trim()
+            }).catch(onError);
+        });
+        remove.addEventListener('click', () => handlers.onDelete(index).catch(onError));
+        return form;
+    },
+
+    // Show how and when the displayed report was graded
+    displayReportInfo: function(data) {
+        const info = document.getElementById('feedback-info');
+        if (!info) return;
+        
+        const gradedAt = new Date(data.created_at).toLocaleString();
+        info.textContent = 'Graded by ' + data.model + ' on ' + gradedAt + (data.cached ? ' (saved report)' : '');
+    },
+
+    // Enable or disable the regenerate button while a report is being graded
+    setRegenerateEnabled: function(enabled) {
+        const button = document.getElementById('regenerate-feedback');
+        if (button) {
+            button.disabled = !enabled;
+        }
+    },
+
+    // Show a spinner with a message
+    showLoading: function(elementId, message) {
+        const element = document.getElementById(elementId);
+        
+        if (element) {
+            element.innerHTML = `
+                <div class="loading-feedback text-center text-muted py-3">
+                    <div class="spinner-border text-primary" role="status">
+                        <span class="visually-hidden">Loading...</span>
+                    </div>
+                    <p>${message}</p>
+                </div>
+            `;
         }
     },
 
@@ -0,0 +1,25 @@
+// API functions for browsing past conversations
+const ConversationsAPI = {
+    // Function to fetch a page of conversations, optionally between two dates
+    fetchConversations: function(page, filters) {
+        const params = new URLSearchParams({ page: page });
+        if (filters.from) {
+            params.set('from', filters.from);
+        }
+        if (filters.to) {
+            params.set('to', filters.to);
+        }
+        
+        return fetch('/api/conversations?' + params.toString(), {
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to load conversations');
+            }
+            return response.json();
+        });
+    }
+};
+
+export { ConversationsAPI };
@@ -0,0 +1,47 @@
+import { ConversationsAPI } from './conversations-api.js';
+import { ConversationsUI } from './conversations-ui.js';
+
+// Main application logic for browsing past conversations
+document.addEventListener('DOMContentLoaded', function() {
+    let currentPage = 1;
+    let filters = {};
+    
+    // Load and display a page of conversations
+    function loadPage(page) {
+        ConversationsAPI.fetchConversations(page, filters)
+            .then(data => {
+                currentPage = data.page;
+                ConversationsUI.displayConversations(data);
+            })
+            .catch(error => {
+                console.error('Error fetching conversations:', error);
+                ConversationsUI.showError('Unable to load your conversations. Please try again later.');
+            });
+    }
+    
+    document.getElementById('conversation-filters').addEventListener('submit', function(event) {
+        event.preventDefault();
+        filters = {
+            from: document.getElementById('filter-from').value,
+            to: document.getElementById('filter-to').value
+        };
+        loadPage(1);
+    });
+    
+    document.getElementById('clear-filters').addEventListener('click', function() {
+        document.getElementById('filter-from').value = '';
+        document.getElementById('filter-to').value = '';
+        filters = {};
+        loadPage(1);
+    });
+    
+    document.getElementById('previous-page').addEventListener('click', function() {
+        loadPage(currentPage - 1);
+    });
+    
+    document.getElementById('next-page').addEventListener('click', function() {
+        loadPage(currentPage + 1);
+    });
+    
+    loadPage(1);
+});
@@ -0,0 +1,69 @@
+// UI functions for browsing past conversations
+const ConversationsUI = {
+    // Build a list entry that opens the analysis of the conversation
+    formatConversation: function(conversation) {
+        const link = document.createElement('a');
+        link.className = 'list-group-item list-group-item-action';
+        link.href = '/conversation-analysis?conversation=' + conversation.id;
+        
+        const header = document.createElement('div');
+        header.className = 'd-flex justify-content-between align-items-center';
+        
+        const date = document.createElement('strong');
+        date.textContent = new Date(conversation.created_at).toLocaleString();
+        header.appendChild(date);
+        
+        if (conversation.level) {
+            const level = document.createElement('span');
+            level.className = 'badge bg-primary';
+            level.textContent = conversation.level;
+            header.appendChild(level);
+        }
+        
+        const preview = document.createElement('p');
+        preview.className = 'mb-0 text-muted small text-truncate';
+        preview.textContent = conversation.preview || 'No messages';
+        
+        const turns = document.createElement('small');
+        turns.className = 'text-muted';
+        turns.textContent = conversation.turn_count + ' messages';
+        
+        link.appendChild(header);
+        link.appendChild(preview);
+        link.appendChild(turns);
+        return link;
+    },
+
+    // Display a page of conversations and update the pagination controls
+    displayConversations: function(data) {
+        const list = document.getElementById('conversation-list');
+        list.innerHTML = '';
+        
+        if (data.conversations.length === 0) {
+            list.innerHTML = '<div class="text-center text-muted py-3">No conversations found.</div>';
+        } else {
+            data.conversations.forEach(conversation => {
+                list.appendChild(this.formatConversation(conversation));
+            });
+        }
+        
+        document.getElementById('page-info').textContent = data.total_pages > 0
+            ? 'Page ' + data.page + ' of ' + data.total_pages
+            : '';
+        document.getElementById('previous-page').disabled = data.page <= 1;
+        document.getElementById('next-page').disabled = data.page >= data.total_pages;
+    },
+
+    // Show error message
+    showError: function(message) {
+        const list = document.getElementById('conversation-list');
+        list.innerHTML = '';
+        
+        const error = document.createElement('div');
+        error.className = 'text-center text-muted py-3';
+        error.textContent = message;
+        list.appendChild(error);
+    }
+};
+
+export { ConversationsUI };
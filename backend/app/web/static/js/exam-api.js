@@ -0,0 +1,57 @@
+// API functions for the speaking exam simulation
+const ExamAPI = {
+    // Send a JSON body and return the decoded response
+    postJSON: function(url, body, errorMessage) {
+        return fetch(url, {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify(body),
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error(errorMessage);
+            }
+            return response.json();
+        });
+    },
+
+    // Function to start the exam in Part 1
+    startExam: function(exam) {
+        return this.postJSON('/api/exam/start', { exam: exam }, 'Failed to start the exam');
+    },
+
+    // Function to send the candidate's recorded answer
+    sendTurn: function(sessionId, audioBlob, mimeType) {
+        const formData = new FormData();
+        formData.append('session_id', sessionId);
+        formData.append('mime_type', mimeType);
+        formData.append('audio', audioBlob, 'recording');
+
+        return fetch('/api/exam/turn', {
+            method: 'POST',
+            body: formData,
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Server returned an error: ' + response.status);
+            }
+            return response.json();
+        });
+    },
+
+    // Function to move on to the next part
+    nextPart: function(sessionId) {
+        return this.postJSON('/api/exam/next', { session_id: sessionId }, 'Failed to start the next part');
+    },
+
+    // Function to finish the exam and get its report
+    finishExam: function(sessionId) {
+        return this.postJSON('/api/exam/finish', { session_id: sessionId }, 'Failed to grade the exam');
+    }
+};
+
+export { ExamAPI };
@@ -0,0 +1,223 @@
+import { ExamAPI } from './exam-api.js';
+import { ExamUI } from './exam-ui.js';
+import { CONSTANTS } from './constants.js';
+
+// Main application logic for the speaking exam simulation
+document.addEventListener('DOMContentLoaded', function() {
+    ExamUI.init();
+
+    const state = {
+        slug: ExamUI.elements['exam'].dataset.exam,
+        exam: null,
+        sessionId: null,
+        part: 0,
+        history: [],
+        remaining: 0,
+        timer: null,
+        recorder: null,
+        stream: null,
+        chunks: []
+    };
+
+    // The part currently in progress
+    function currentPart() {
+        return state.exam.parts.find(part => part.number === state.part);
+    }
+
+    // Whether the current part is the last one of the paper
+    function isLastPart() {
+        return state.part === state.exam.parts.length;
+    }
+
+    // Play the examiner's words, resolving when they finish or cannot be played
+    function playAudio(audioBase64) {
+        return new Promise(resolve => {
+            if (!audioBase64) {
+                resolve();
+                return;
+            }
+            const audio = new Audio("data:audio/mp3;base64," + audioBase64);
+            audio.onended = resolve;
+            audio.onerror = resolve;
+            audio.play().catch(error => {
+                console.error("Audio play error:", error);
+                resolve();
+            });
+        });
+    }
+
+    // Count down the time left in the part; at zero the candidate is asked to move on
+    function startTimer(seconds) {
+        clearInterval(state.timer);
+        state.remaining = seconds;
+        ExamUI.updateTimer(state.remaining);
+        state.timer = setInterval(() => {
+            state.remaining = Math.max(state.remaining - 1, 0);
+            ExamUI.updateTimer(state.remaining);
+            if (state.remaining === 0) {
+                clearInterval(state.timer);
+                if (state.recorder && state.recorder.state === 'recording') {
+                    state.recorder.stop();
+                } else {
+                    endOfPart();
+                }
+            }
+        }, 1000);
+    }
+
+    // Offer the next part, or the report after the last one
+    function endOfPart() {
+        ExamUI.toggle('exam-record', false);
+        ExamUI.setStatus(`Time is up for Part ${state.part}.`);
+    }
+
+    // Show the controls for a part in progress
+    function showPartControls() {
+        ExamUI.toggle('exam-record', true);
+        ExamUI.toggle('exam-next', !isLastPart());
+        ExamUI.toggle('exam-finish', isLastPart());
+        ExamUI.elements['exam-record'].disabled = false;
+        ExamUI.setStatus('Press Answer and speak when you are ready.');
+    }
+
+    // Show a part and let the examiner open it
+    async function beginPart(data) {
+        state.part = data.part;
+        state.history = data.history;
+        ExamUI.renderPart(currentPart(), state.exam.parts.length);
+        ExamUI.renderHistory(state.history, state.part);
+        ExamUI.elements['exam-record'].disabled = true;
+        ExamUI.setStatus('The examiner is speaking...');
+        await playAudio(data.audio_base64);
+        startTimer(data.remaining_seconds);
+        showPartControls();
+    }
+
+    // Record the candidate's answer
+    async function startRecording() {
+        try {
+            state.stream = await navigator.mediaDevices.getUserMedia({
+                audio: {
+                    channelCount: 1,
+                    sampleRate: CONSTANTS.AUDIO_SAMPLE_RATE,
+                    sampleSize: 16
+                }
+            });
+            state.chunks = [];
+            state.recorder = new MediaRecorder(state.stream);
+            state.recorder.ondataavailable = event => {
+                if (event.data.size > 0) {
+                    state.chunks.push(event.data);
+                }
+            };
+            state.recorder.onstop = sendAnswer;
+            state.recorder.start();
+            ExamUI.setRecording(true);
+            ExamUI.setStatus('Recording... Speak now');
+        } catch (error) {
+            console.error("Error starting recording:", error);
+            ExamUI.setStatus("Error: " + error.message);
+        }
+    }
+
+    // Send the recorded answer and play the examiner's reply
+    async function sendAnswer() {
+        ExamUI.setRecording(false);
+        ExamUI.elements['exam-record'].disabled = true;
+        ExamUI.setStatus('Processing...');
+        if (state.stream) {
+            state.stream.getTracks().forEach(track => track.stop());
+            state.stream = null;
+        }
+
+        const mimeType = state.recorder.mimeType;
+        const audioBlob = new Blob(state.chunks, { type: mimeType });
+        try {
+            const data = await ExamAPI.sendTurn(state.sessionId, audioBlob, mimeType);
+            state.history = data.history;
+            ExamUI.renderHistory(state.history, state.part);
+            ExamUI.setStatus('The examiner is speaking...');
+            await playAudio(data.audio_base64);
+
+            if (data.time_up || state.remaining === 0) {
+                clearInterval(state.timer);
+                ExamUI.updateTimer(0);
+                endOfPart();
+            } else {
+                showPartControls();
+            }
+        } catch (error) {
+            console.error('Error sending answer:', error);
+            ExamUI.setStatus("Error: " + error.message);
+            ExamUI.elements['exam-record'].disabled = false;
+        }
+    }
+
+    // Grade the exam and show the report
+    async function finishExam() {
+        clearInterval(state.timer);
+        ['exam-record', 'exam-next', 'exam-finish'].forEach(id => ExamUI.toggle(id, false));
+        ExamUI.setStatus('The examiners are grading your test...');
+        try {
+            const data = await ExamAPI.finishExam(state.sessionId);
+            ExamUI.renderReport(data.exam, data.report);
+            ExamUI.setStatus('Test complete');
+        } catch (error) {
+            console.error('Error finishing exam:', error);
+            ExamUI.setStatus("Error: " + error.message);
+            ExamUI.toggle('exam-finish', true);
+        }
+    }
+
+    ExamUI.elements['exam-start'].addEventListener('click', async function() {
+        ExamUI.elements['exam-start'].disabled = true;
+        ExamUI.setStatus('Starting the test...');
+        try {
+            const data = await ExamAPI.startExam(state.slug);
+            state.exam = data.exam;
+            state.sessionId = data.session_id;
+            ExamUI.toggle('exam-start', false);
+            await beginPart(data);
+        } catch (error) {
+            console.error('Error starting exam:', error);
+            ExamUI.setStatus("Error: " + error.message);
+            ExamUI.elements['exam-start'].disabled = false;
+        }
+    });
+
+    ExamUI.elements['exam-record'].addEventListener('click', function() {
+        if (state.recorder && state.recorder.state === 'recording') {
+            state.recorder.stop();
+        } else {
+            startRecording();
+        }
+    });
+
+    ExamUI.elements['exam-next'].addEventListener('click', async function() {
+        if (state.recorder && state.recorder.state === 'recording') {
+            return;
+        }
+        clearInterval(state.timer);
+        ExamUI.elements['exam-next'].disabled = true;
+        try {
+            const data = await ExamAPI.nextPart(state.sessionId);
+            if (data.finished) {
+                await finishExam();
+                return;
+            }
+            await beginPart(data);
+        } catch (error) {
+            console.error('Error starting next part:', error);
+            ExamUI.setStatus("Error: " + error.message);
+        } finally {
+            ExamUI.elements['exam-next'].disabled = false;
+        }
+    });
+
+    ExamUI.elements['exam-finish'].addEventListener('click', function() {
+        if (state.recorder && state.recorder.state === 'recording') {
+            return;
+        }
+        finishExam();
+    });
+});
@@ -0,0 +1,164 @@
+import { ConversationUtils } from './shared-conversation-utils.js';
+
+// UI functions for the speaking exam simulation
+const ExamUI = {
+    // DOM elements
+    elements: {},
+
+    // The Cambridge assessment scales in report order
+    criteria: [
+        { key: 'grammar_vocabulary', label: 'Grammar and Vocabulary' },
+        { key: 'discourse_management', label: 'Discourse Management' },
+        { key: 'pronunciation', label: 'Pronunciation' },
+        { key: 'interactive_communication', label: 'Interactive Communication' }
+    ],
+
+    // Initialize UI elements
+    init: function() {
+        [
+            'exam', 'exam-intro', 'exam-part', 'part-title', 'task-sheet',
+            'exam-history', 'exam-report', 'exam-start', 'exam-record', 'exam-next',
+            'exam-finish', 'exam-status', 'part-timer', 'part-timer-value'
+        ].forEach(id => {
+            this.elements[id] = document.getElementById(id);
+        });
+    },
+
+    // Escape text before inserting it as HTML
+    escapeHTML: function(text) {
+        const div = document.createElement('div');
+        div.textContent = text == null ? '' : String(text);
+        return div.innerHTML;
+    },
+
+    // Show or hide an element
+    toggle: function(id, visible) {
+        this.elements[id].classList.toggle('d-none', !visible);
+    },
+
+    // Set the status line
+    setStatus: function(text) {
+        this.elements['exam-status'].textContent = text;
+    },
+
+    // Show the title and task sheet of a part
+    renderPart: function(part, partCount) {
+        const esc = this.escapeHTML;
+        this.toggle('exam-intro', false);
+        this.toggle('exam-part', true);
+        this.elements['part-title'].textContent = `Part ${part.number} of ${partCount}: ${part.title}`;
+
+        let sheet = '';
+        if (part.task) {
+            sheet += `<h6 class="fw-bold">${esc(part.task)}</h6>`;
+        }
+        if (part.pictures && part.pictures.length > 0) {
+            sheet += '<div class="row g-2">';
+            part.pictures.forEach((picture, i) => {
+                sheet += `
+                    <div class="col-md">
+                        <div class="p-2 h-100 bg-light rounded">
+                            <i class="fas fa-image text-primary me-1"></i><strong>Picture ${i + 1}</strong>
+                            <p class="mb-0 small">${esc(picture)}</p>
+                        </div>
+                    </div>
+                `;
+            });
+            sheet += '</div>';
+        }
+        if (part.prompts && part.prompts.length > 0) {
+            sheet += '<ul class="mb-0">' + part.prompts.map(prompt => `<li>${esc(prompt)}</li>`).join('') + '</ul>';
+        }
+        this.elements['task-sheet'].innerHTML = sheet;
+        this.toggle('task-sheet', sheet !== '');
+    },
+
+    // Show the turns of the current part
+    renderHistory: function(history, partNumber) {
+        const esc = this.escapeHTML;
+        const turns = history.filter(turn => turn.part === partNumber);
+        this.elements['exam-history'].innerHTML = turns.map(turn => {
+            const speaker = turn.role === 'candidate' ? 'You' : 'Examiner';
+            const cls = turn.role === 'candidate' ? 'message user-message' : 'message assistant-message';
+            const speech = turn.pronunciation ? ConversationUtils.formatPronunciation(turn.pronunciation).outerHTML : '';
+            return `<div class="${cls}"><span class="message-role">${speaker}: </span><span class="message-content">${esc(turn.content)}</span>${speech}</div>`;
+        }).join('');
+        this.elements['exam-history'].scrollTop = this.elements['exam-history'].scrollHeight;
+    },
+
+    // Show the time left in the part
+    updateTimer: function(seconds) {
+        this.toggle('part-timer', true);
+        const minutes = Math.floor(seconds / 60);
+        const rest = String(seconds % 60).padStart(2, '0');
+        this.elements['part-timer-value'].textContent = `${minutes}:${rest}`;
+        this.elements['part-timer'].classList.toggle('bg-warning', seconds <= 30);
+        this.elements['part-timer'].classList.toggle('bg-light', seconds > 30);
+    },
+
+    // Show the record button as idle or recording
+    setRecording: function(recording) {
+        const button = this.elements['exam-record'];
+        button.classList.toggle('btn-danger', recording);
+        button.classList.toggle('btn-primary', !recording);
+        button.innerHTML = recording
+            ? '<i class="fas fa-stop me-2"></i>Stop Answering'
+            : '<i class="fas fa-microphone me-2"></i>Answer';
+    },
+
+    // Format a band as a labelled progress bar
+    formatBand: function(label, band, max) {
+        const percent = Math.round(band / max * 100);
+        return `
+            <div class="mb-2">
+                <div class="d-flex justify-content-between small">
+                    <span>${label}</span>
+                    <span>${band}/${max}</span>
+                </div>
+                <div class="progress" style="height: 8px;">
+                    <div class="progress-bar" role="progressbar" style="width: ${percent}%"
+                         aria-valuenow="${band}" aria-valuemin="0" aria-valuemax="${max}"></div>
+                </div>
+            </div>
+        `;
+    },
+
+    // Show the per-part report on the Cambridge assessment scales
+    renderReport: function(exam, report) {
+        const esc = this.escapeHTML;
+        this.toggle('exam-part', false);
+        this.toggle('part-timer', false);
+
+        let html = `
+            <h5>Your Report</h5>
+            <p>${esc(report.summary)}</p>
+            <div class="feedback-point">
+                <h5>Overall <span class="badge bg-primary ms-2">${report.overall.total}/20</span></h5>
+                ${this.criteria.map(c => this.formatBand(c.label, report.overall[c.key], 5)).join('')}
+            </div>
+        `;
+
+        report.parts.forEach(partReport => {
+            const part = exam.parts.find(p => p.number === partReport.part);
+            const title = part ? part.title : '';
+            html += `
+                <div class="feedback-point">
+                    <h5>Part ${partReport.part}: ${esc(title)}</h5>
+                    ${this.criteria.map(c => this.formatBand(c.label, partReport.scores[c.key], 5)).join('')}
+                    <p class="mt-2">${esc(partReport.comment)}</p>
+            `;
+            if (partReport.strengths.length > 0) {
+                html += '<h6>Strengths</h6><ul>' + partReport.strengths.map(s => `<li>${esc(s)}</li>`).join('') + '</ul>';
+            }
+            if (partReport.improvements.length > 0) {
+                html += '<h6>To Improve</h6><ul>' + partReport.improvements.map(s => `<li>${esc(s)}</li>`).join('') + '</ul>';
+            }
+            html += '</div>';
+        });
+
+        this.elements['exam-report'].innerHTML = html;
+        this.toggle('exam-report', true);
+    }
+};
+
+export { ExamUI };
@@ -0,0 +1,82 @@
+// Notifications from the user's teacher on the home dashboard
+const HomeNotifications = {
+    // Function to fetch the unread notifications
+    fetchNotifications: function() {
+        return fetch('/api/notifications', {
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to load notifications');
+            }
+            return response.json();
+        });
+    },
+
+    // Function to mark notifications as read, or all of them when no IDs are given
+    markRead: function(ids) {
+        return fetch('/api/notifications/read', {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify({ ids: ids }),
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to update notifications');
+            }
+        });
+    },
+
+    // Show the notifications, each linking to the conversation it is about. Opening one marks
+    // it as read; the section is hidden when none are left.
+    render: function(notifications) {
+        const section = document.getElementById('notifications');
+        const list = document.getElementById('notifications-list');
+        list.innerHTML = '';
+        section.classList.toggle('d-none', notifications.length === 0);
+
+        notifications.forEach(notification => {
+            const item = document.createElement(notification.conversation_id ? 'a' : 'div');
+            item.className = 'list-group-item list-group-item-action d-flex justify-content-between';
+            if (notification.conversation_id) {
+                item.href = '/conversation-analysis?conversation=' + notification.conversation_id;
+                item.addEventListener('click', event => {
+                    event.preventDefault();
+                    this.markRead([notification.id])
+                        .catch(error => console.error('Error marking notification read:', error))
+                        .finally(() => {
+                            window.location.href = item.href;
+                        });
+                });
+            }
+            const message = document.createElement('span');
+            message.textContent = notification.message;
+            const date = document.createElement('small');
+            date.className = 'text-muted ms-3';
+            date.textContent = new Date(notification.created_at).toLocaleDateString();
+            item.appendChild(message);
+            item.appendChild(date);
+            list.appendChild(item);
+        });
+    }
+};
+
+document.addEventListener('DOMContentLoaded', function() {
+    const dismiss = document.getElementById('notifications-dismiss');
+    if (!dismiss) return;
+
+    dismiss.addEventListener('click', () => {
+        HomeNotifications.markRead([])
+            .then(() => HomeNotifications.render([]))
+            .catch(error => console.error('Error marking notifications read:', error));
+    });
+
+    HomeNotifications.fetchNotifications()
+        .then(data => HomeNotifications.render(data.notifications))
+        .catch(error => {
+            console.error('Error fetching notifications:', error);
+        });
+});
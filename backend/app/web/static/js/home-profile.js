@@ -0,0 +1,222 @@
+// Learner profile form on the home dashboard
+const HomeProfile = {
+    // Function to fetch the languages the user can practise and the one they practise
+    fetchLanguages: function() {
+        return fetch('/api/languages', {
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to load languages');
+            }
+            return response.json();
+        });
+    },
+
+    // Function to fetch the user's learner profile with their level in a language
+    fetchProfile: function(language) {
+        return fetch('/api/user/profile?language=' + encodeURIComponent(language), {
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to load profile');
+            }
+            return response.json();
+        });
+    },
+
+    // Function to save the user's learner profile
+    saveProfile: function(profile) {
+        return fetch('/api/user/profile', {
+            method: 'PUT',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify(profile),
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                return response.text().then(text => {
+                    throw new Error(text.trim() || 'Failed to save profile');
+                });
+            }
+            return response.json();
+        });
+    },
+
+    // Function to fetch what is remembered about the user
+    fetchMemories: function() {
+        return fetch('/api/user/memory', {
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to load memories');
+            }
+            return response.json();
+        });
+    },
+
+    // Function to forget one memory, or all of them when no ID is given
+    deleteMemories: function(memoryId) {
+        const url = memoryId ? '/api/user/memory/' + memoryId : '/api/user/memory';
+        return fetch(url, {
+            method: 'DELETE',
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to delete memory');
+            }
+        });
+    },
+
+    // Split a comma-separated field into a list
+    splitList: function(value) {
+        return value.split(',').map(entry => entry.trim()).filter(entry => entry !== '');
+    },
+
+    // Offer the languages a level can be set for
+    renderLanguages: function(data) {
+        const select = document.getElementById('profile-language');
+        select.innerHTML = '';
+        data.languages.forEach(language => {
+            const option = document.createElement('option');
+            option.value = language.code;
+            option.textContent = language.name;
+            select.appendChild(option);
+        });
+        select.value = data.language;
+    },
+
+    // Fill the form and describe how Voxy adapts to the level
+    render: function(data) {
+        const profile = data.profile;
+        document.getElementById('profile-language').value = profile.language;
+        document.getElementById('profile-level').value = profile.cefr_level || '';
+        document.getElementById('profile-interests').value = profile.interests.join(', ');
+        document.getElementById('profile-goals').value = profile.goals.join(', ');
+        document.getElementById('profile-topics').value = profile.preferred_topics.join(', ');
+
+        const pace = data.speech_speed < 1
+            ? 'speaks at ' + Math.round(data.speech_speed * 100) + '% speed'
+            : 'speaks at natural speed';
+        document.getElementById('profile-adaptation').textContent = profile.cefr_level
+            ? 'At ' + profile.cefr_level + ' in this language, Voxy ' + pace + ' and replies in up to ' + data.max_sentences + ' sentences.'
+            : 'Voxy adapts its words, sentence length and speaking speed to your level in this language once it is known.';
+    },
+
+    // List the remembered facts, each with a button to forget it
+    renderMemories: function(memories) {
+        const list = document.getElementById('memories-list');
+        document.getElementById('memories-clear').classList.toggle('d-none', memories.length === 0);
+        list.innerHTML = '';
+        if (memories.length === 0) {
+            list.innerHTML = '<li class="list-group-item text-muted">Nothing remembered yet</li>';
+            return;
+        }
+        memories.forEach(memory => {
+            const item = document.createElement('li');
+            item.className = 'list-group-item d-flex justify-content-between align-items-center';
+
+            const text = document.createElement('span');
+            const category = document.createElement('span');
+            category.className = 'badge bg-secondary me-2';
+            category.textContent = memory.category;
+            text.appendChild(category);
+            text.appendChild(document.createTextNode(memory.fact));
+
+            const forget = document.createElement('button');
+            forget.type = 'button';
+            forget.className = 'btn btn-sm btn-outline-danger';
+            forget.title = 'Forget this';
+            forget.innerHTML = '<i class="fas fa-times"></i>';
+            forget.dataset.memoryId = memory.id;
+
+            item.appendChild(text);
+            item.appendChild(forget);
+            list.appendChild(item);
+        });
+    },
+
+    // Read the form
+    read: function() {
+        return {
+            language: document.getElementById('profile-language').value,
+            cefr_level: document.getElementById('profile-level').value,
+            interests: this.splitList(document.getElementById('profile-interests').value),
+            goals: this.splitList(document.getElementById('profile-goals').value),
+            preferred_topics: this.splitList(document.getElementById('profile-topics').value)
+        };
+    }
+};
+
+document.addEventListener('DOMContentLoaded', function() {
+    const form = document.getElementById('profile-form');
+    if (!form) return;
+    const status = document.getElementById('profile-status');
+
+    form.addEventListener('submit', event => {
+        event.preventDefault();
+        status.className = 'ms-3 small';
+        status.textContent = 'Saving...';
+        HomeProfile.saveProfile(HomeProfile.read())
+            .then(data => {
+                HomeProfile.render(data);
+                status.classList.add('text-success');
+                status.textContent = 'Saved';
+            })
+            .catch(error => {
+                status.classList.add('text-danger');
+                status.textContent = error.message;
+            });
+    });
+
+    const loadMemories = () => HomeProfile.fetchMemories()
+        .then(data => HomeProfile.renderMemories(data.memories))
+        .catch(error => {
+            console.error('Error fetching memories:', error);
+        });
+
+    document.getElementById('memories-list').addEventListener('click', event => {
+        const button = event.target.closest('button[data-memory-id]');
+        if (!button) return;
+        button.disabled = true;
+        HomeProfile.deleteMemories(button.dataset.memoryId)
+            .then(loadMemories)
+            .catch(error => {
+                button.disabled = false;
+                alert(error.message);
+            });
+    });
+
+    document.getElementById('memories-clear').addEventListener('click', () => {
+        if (!confirm('Forget everything Voxy remembers about you?')) return;
+        HomeProfile.deleteMemories()
+            .then(loadMemories)
+            .catch(error => alert(error.message));
+    });
+
+    loadMemories();
+    const loadProfile = language => HomeProfile.fetchProfile(language)
+        .then(data => HomeProfile.render(data))
+        .catch(error => {
+            console.error('Error fetching profile:', error);
+        });
+
+    document.getElementById('profile-language').addEventListener('change', event => {
+        status.textContent = '';
+        loadProfile(event.target.value);
+    });
+
+    HomeProfile.fetchLanguages()
+        .then(data => {
+            HomeProfile.renderLanguages(data);
+            return loadProfile(data.language);
+        })
+        .catch(error => {
+            console.error('Error fetching languages:', error);
+        });
+});
@@ -0,0 +1,140 @@
+// Progress charts on the home dashboard
+const CEFR_LEVELS = ['A1', 'A2', 'B1', 'B2', 'C1', 'C2'];
+
+const HomeProgress = {
+    charts: [],
+
+    // Function to fetch the metrics averaged per interval
+    fetchTrend: function(interval) {
+        return fetch('/api/progress/trend?interval=' + encodeURIComponent(interval), {
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to load progress');
+            }
+            return response.json();
+        });
+    },
+
+    // Replace the charts with the given trend
+    render: function(trend) {
+        this.charts.forEach(chart => chart.destroy());
+        this.charts = [];
+
+        const empty = trend.length === 0;
+        document.getElementById('progress-empty').classList.toggle('d-none', !empty);
+        if (empty) return;
+
+        const labels = trend.map(point => new Date(point.period).toLocaleDateString());
+
+        this.charts.push(new Chart(document.getElementById('progress-chart'), {
+            type: 'line',
+            data: {
+                labels: labels,
+                datasets: [
+                    { label: 'Words per turn', data: trend.map(point => point.words_per_turn), yAxisID: 'words' },
+                    { label: 'Correction rate (%)', data: trend.map(point => Math.round(point.correction_rate * 100)), yAxisID: 'percent' },
+                    { label: 'Vocabulary diversity (%)', data: trend.map(point => Math.round(point.vocabulary_diversity * 100)), yAxisID: 'percent' }
+                ]
+            },
+            options: {
+                scales: {
+                    words: { type: 'linear', position: 'left', beginAtZero: true },
+                    percent: { type: 'linear', position: 'right', min: 0, max: 100, grid: { drawOnChartArea: false } }
+                }
+            }
+        }));
+
+        this.charts.push(new Chart(document.getElementById('level-chart'), {
+            type: 'line',
+            data: {
+                labels: labels,
+                datasets: [{
+                    label: 'CEFR level',
+                    data: trend.map(point => point.cefr_level ? CEFR_LEVELS.indexOf(point.cefr_level) : null),
+                    spanGaps: true,
+                    stepped: true
+                }]
+            },
+            options: {
+                scales: {
+                    y: {
+                        min: 0,
+                        max: CEFR_LEVELS.length - 1,
+                        ticks: { stepSize: 1, callback: value => CEFR_LEVELS[value] }
+                    }
+                }
+            }
+        }));
+    },
+
+    // Function to fetch the grammar errors by type over all conversations
+    fetchErrors: function() {
+        return fetch('/api/progress/errors', {
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to load grammar errors');
+            }
+            return response.json();
+        });
+    },
+
+    // Show the most common error types and the share of sentences they occur in
+    renderErrors: function(data) {
+        const section = document.getElementById('errors-section');
+        if (data.errors.length === 0) return;
+        section.classList.remove('d-none');
+
+        const list = document.getElementById('error-insights');
+        list.innerHTML = '';
+        data.insights.forEach(insight => {
+            const item = document.createElement('li');
+            item.className = 'mb-2';
+            item.textContent = '• ' + insight + '.';
+            list.appendChild(item);
+        });
+
+        new Chart(document.getElementById('errors-chart'), {
+            type: 'bar',
+            data: {
+                labels: data.errors.map(stat => stat.label),
+                datasets: [{
+                    label: 'Sentences with the error (%)',
+                    data: data.errors.map(stat => Math.round(stat.rate * 100))
+                }]
+            },
+            options: {
+                indexAxis: 'y',
+                scales: {
+                    x: { min: 0, max: 100 }
+                }
+            }
+        });
+    },
+
+    // Load and display the trend for an interval
+    load: function(interval) {
+        this.fetchTrend(interval)
+            .then(data => this.render(data.trend))
+            .catch(error => {
+                console.error('Error fetching progress:', error);
+            });
+    }
+};
+
+document.addEventListener('DOMContentLoaded', function() {
+    const intervalSelect = document.getElementById('progress-interval');
+    if (!intervalSelect) return;
+
+    intervalSelect.addEventListener('change', () => HomeProgress.load(intervalSelect.value));
+    HomeProgress.load(intervalSelect.value);
+
+    HomeProgress.fetchErrors()
+        .then(data => HomeProgress.renderErrors(data))
+        .catch(error => {
+            console.error('Error fetching grammar errors:', error);
+        });
+});
@@ -0,0 +1,37 @@
+// API functions for reviewing past corrections
+const ReviewAPI = {
+    // Function to fetch the next card due for review
+    fetchNextCard: function() {
+        return fetch('/api/review/next', {
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to load review cards');
+            }
+            return response.json();
+        });
+    },
+
+    // Function to send the recorded answer to a card
+    sendAnswer: function(cardId, audioBlob, mimeType) {
+        const formData = new FormData();
+        formData.append('card_id', cardId);
+        formData.append('mime_type', mimeType);
+        formData.append('audio', audioBlob, 'recording');
+
+        return fetch('/api/review/answer', {
+            method: 'POST',
+            body: formData,
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Server returned an error: ' + response.status);
+            }
+            return response.json();
+        });
+    }
+};
+
+export { ReviewAPI };
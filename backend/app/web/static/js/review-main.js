@@ -0,0 +1,122 @@
+import { ReviewAPI } from './review-api.js';
+import { ReviewUI } from './review-ui.js';
+import { CONSTANTS } from './constants.js';
+
+// Main application logic for reviewing past corrections
+document.addEventListener('DOMContentLoaded', function() {
+    ReviewUI.init();
+
+    const state = {
+        card: null,
+        audioBase64: '',
+        recorder: null,
+        stream: null,
+        chunks: []
+    };
+
+    // Play the original sentence, resolving when it finishes or cannot be played
+    function playAudio() {
+        return new Promise(resolve => {
+            if (!state.audioBase64) {
+                resolve();
+                return;
+            }
+            const audio = new Audio("data:audio/mp3;base64," + state.audioBase64);
+            audio.onended = resolve;
+            audio.onerror = resolve;
+            audio.play().catch(error => {
+                console.error("Audio play error:", error);
+                resolve();
+            });
+        });
+    }
+
+    // Load the next due card and read it out
+    async function loadNextCard() {
+        ReviewUI.setStatus('Loading...');
+        try {
+            const data = await ReviewAPI.fetchNextCard();
+            ReviewUI.updateStats(data.stats);
+            state.card = data.card;
+            state.audioBase64 = data.audio_base64;
+            if (!state.card) {
+                ReviewUI.showEmpty(data.stats);
+                return;
+            }
+            ReviewUI.showCard(state.card);
+            ReviewUI.setStatus('Listen, then say the sentence the correct way.');
+            await playAudio();
+        } catch (error) {
+            console.error('Error loading review card:', error);
+            ReviewUI.setStatus("Error: " + error.message);
+        }
+    }
+
+    // Record the student's answer
+    async function startRecording() {
+        try {
+            state.stream = await navigator.mediaDevices.getUserMedia({
+                audio: {
+                    channelCount: 1,
+                    sampleRate: CONSTANTS.AUDIO_SAMPLE_RATE,
+                    sampleSize: 16
+                }
+            });
+            state.chunks = [];
+            state.recorder = new MediaRecorder(state.stream);
+            state.recorder.ondataavailable = event => {
+                if (event.data.size > 0) {
+                    state.chunks.push(event.data);
+                }
+            };
+            state.recorder.onstop = sendAnswer;
+            state.recorder.start();
+            ReviewUI.setRecording(true);
+            ReviewUI.setStatus('Recording... Speak now');
+        } catch (error) {
+            console.error("Error starting recording:", error);
+            ReviewUI.setStatus("Error: " + error.message);
+        }
+    }
+
+    // Send the recorded answer and show its grade
+    async function sendAnswer() {
+        ReviewUI.setRecording(false);
+        ReviewUI.elements['review-record'].disabled = true;
+        ReviewUI.setStatus('Checking your answer...');
+        if (state.stream) {
+            state.stream.getTracks().forEach(track => track.stop());
+            state.stream = null;
+        }
+
+        const mimeType = state.recorder.mimeType;
+        const audioBlob = new Blob(state.chunks, { type: mimeType });
+        try {
+            const data = await ReviewAPI.sendAnswer(state.card.id, audioBlob, mimeType);
+            ReviewUI.showResult(data.result, data.card);
+            ReviewUI.setStatus('');
+        } catch (error) {
+            console.error('Error sending answer:', error);
+            ReviewUI.setStatus("Error: " + error.message);
+            ReviewUI.elements['review-record'].disabled = false;
+        }
+    }
+
+    ReviewUI.elements['review-record'].addEventListener('click', function() {
+        if (state.recorder && state.recorder.state === 'recording') {
+            state.recorder.stop();
+        } else {
+            startRecording();
+        }
+    });
+
+    ReviewUI.elements['review-listen'].addEventListener('click', function() {
+        playAudio();
+    });
+
+    ReviewUI.elements['review-next'].addEventListener('click', function() {
+        loadNextCard();
+    });
+
+    loadNextCard();
+});
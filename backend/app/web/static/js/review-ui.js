@@ -0,0 +1,108 @@
+// UI functions for reviewing past corrections
+const ReviewUI = {
+    // DOM elements
+    elements: {},
+
+    // Initialize UI elements
+    init: function() {
+        [
+            'review-due', 'review-card', 'review-original', 'review-listen', 'review-result',
+            'review-empty', 'review-empty-text', 'review-record', 'review-next', 'review-status'
+        ].forEach(id => {
+            this.elements[id] = document.getElementById(id);
+        });
+    },
+
+    // Show or hide an element
+    toggle: function(id, visible) {
+        this.elements[id].classList.toggle('d-none', !visible);
+    },
+
+    // Set the status line
+    setStatus: function(text) {
+        this.elements['review-status'].textContent = text;
+    },
+
+    // Show how many cards are due
+    updateStats: function(stats) {
+        this.elements['review-due'].textContent = stats.due + ' due · ' + stats.total + ' total';
+    },
+
+    // Show a card waiting for an answer
+    showCard: function(card) {
+        this.toggle('review-empty', false);
+        this.toggle('review-card', true);
+        this.toggle('review-result', false);
+        this.toggle('review-next', false);
+        this.toggle('review-record', true);
+        this.elements['review-record'].disabled = false;
+        this.elements['review-original'].textContent = card.original;
+    },
+
+    // Explain that nothing is due, and when the next card is
+    showEmpty: function(stats) {
+        this.toggle('review-card', false);
+        this.toggle('review-record', false);
+        this.toggle('review-next', false);
+        this.toggle('review-empty', true);
+
+        let text;
+        if (stats.total === 0) {
+            text = 'No corrections to review yet. Have a conversation with Voxy and your corrected sentences will appear here.';
+        } else if (stats.next_due_at) {
+            text = 'All caught up! Your next review is due ' + new Date(stats.next_due_at).toLocaleString() + '.';
+        } else {
+            text = 'All caught up!';
+        }
+        this.elements['review-empty-text'].textContent = text;
+        this.setStatus('');
+    },
+
+    // Set the record button to its recording or idle look
+    setRecording: function(recording) {
+        const button = this.elements['review-record'];
+        button.classList.toggle('btn-danger', recording);
+        button.classList.toggle('btn-success', !recording);
+        button.innerHTML = recording
+            ? '<i class="fas fa-stop me-2"></i>Stop'
+            : '<i class="fas fa-microphone me-2"></i>Say the Correct Sentence';
+    },
+
+    // Show the grade of an answer next to the expected sentence
+    showResult: function(result, card) {
+        const div = this.elements['review-result'];
+        div.innerHTML = '';
+        div.className = 'mb-3 alert ' + (result.correct ? 'alert-success' : 'alert-warning');
+
+        const heading = document.createElement('strong');
+        heading.textContent = result.correct
+            ? (result.quality === 5 ? 'Perfect!' : 'Correct!')
+            : 'Not quite.';
+        div.appendChild(heading);
+
+        [
+            ['You said: ', result.transcript || '(nothing recognised)'],
+            ['Correct sentence: ', result.expected]
+        ].forEach(([label, text]) => {
+            const line = document.createElement('div');
+            const strong = document.createElement('span');
+            strong.className = 'fw-semibold';
+            strong.textContent = label;
+            line.appendChild(strong);
+            line.appendChild(document.createTextNode(text));
+            div.appendChild(line);
+        });
+
+        const next = document.createElement('small');
+        next.className = 'text-muted';
+        next.textContent = 'Accuracy ' + Math.round(result.accuracy * 100) + '% · next review in ' +
+            card.interval_days + (card.interval_days === 1 ? ' day' : ' days');
+        div.appendChild(next);
+
+        this.toggle('review-result', true);
+        this.toggle('review-record', false);
+        this.toggle('review-next', true);
+    }
+};
+
+export { ReviewUI };
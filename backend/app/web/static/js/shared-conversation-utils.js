@@ -1,5 +1,77 @@
 // Shared utility functions for conversation features
 const ConversationUtils = {
+    // Summarize the speech analysis of a turn: speaking rate, pauses and unclear words
+    formatPronunciation: function(analysis) {
+        const div = document.createElement('div');
+        div.className = 'pronunciation small text-muted';
+        
+        const parts = [Math.round(analysis.words_per_minute) + ' words/min'];
+        if (analysis.pauses.length > 0) {
+            parts.push(analysis.pauses.length + (analysis.pauses.length === 1 ? ' long pause' : ' long pauses'));
+        }
+        div.textContent = '🗣 ' + parts.join(' · ');
+        
+        if (analysis.low_confidence_words.length > 0) {
+            const unclear = document.createElement('span');
+            unclear.className = 'text-warning-emphasis';
+            unclear.textContent = ' · unclear: ' + analysis.low_confidence_words.map(w => w.word).join(', ');
+            unclear.title = 'Words the speech recognizer was unsure about';
+            div.appendChild(unclear);
+        }
+        return div;
+    },
+
+    // Build the suggestion of a user turn, marking the words the correction removed and
+    // added when the word-level diff is available, followed by its explanation
+    formatSuggestion: function(turn) {
+        const div = document.createElement('div');
+        div.className = 'suggestion';
+        const label = document.createElement('strong');
+        label.textContent = 'Suggestion:';
+        div.appendChild(label);
+        
+        if (!turn.suggestion_diff || turn.suggestion_diff.length === 0) {
+            div.appendChild(document.createTextNode(' ' + turn.suggestion));
+            this.appendExplanation(div, turn);
+            return div;
+        }
+        
+        const appendWords = (tag, className, text, type) => {
+            const element = document.createElement(tag);
+            element.className = className;
+            element.textContent = text;
+            element.title = type.replace('_', ' ');
+            div.appendChild(element);
+        };
+        turn.suggestion_diff.forEach(span => {
+            div.appendChild(document.createTextNode(' '));
+            if (span.op === 'equal') {
+                div.appendChild(document.createTextNode(span.corrected));
+                return;
+            }
+            if (span.original) {
+                appendWords('del', 'diff-delete', span.original, span.type);
+            }
+            if (span.original && span.corrected) {
+                div.appendChild(document.createTextNode(' '));
+            }
+            if (span.corrected) {
+                appendWords('ins', 'diff-insert', span.corrected, span.type);
+            }
+        });
+        this.appendExplanation(div, turn);
+        return div;
+    },
+
+    // Add the explanation of a correction in the learner's native language, if any
+    appendExplanation: function(div, turn) {
+        if (!turn.explanation) return;
+        const explanation = document.createElement('span');
+        explanation.className = 'explanation';
+        explanation.textContent = turn.explanation;
+        div.appendChild(explanation);
+    },
+
     // Format message with role and content
     formatMessage: function(turn, userName = "You") {
         const div = document.createElement('div');
@@ -31,10 +103,7 @@ const ConversationUtils = {
                 div.appendChild(positiveDiv);
             } else {
                 // Show suggestion for incorrect sentences
-                const suggestionDiv = document.createElement('div');
-                suggestionDiv.className = 'suggestion';
-                suggestionDiv.innerHTML = '<strong>Suggestion:</strong> ' + turn.suggestion;
-                div.appendChild(suggestionDiv);
+                div.appendChild(this.formatSuggestion(turn));
             }
         } else if (turn.role === 'user' && turn.isProcessing) {
             // Show processing indicator for user messages that are still being processed
@@ -44,6 +113,10 @@ const ConversationUtils = {
             div.appendChild(processingDiv);
         }
         
+        if (turn.pronunciation) {
+            div.appendChild(this.formatPronunciation(turn.pronunciation));
+        }
+        
         return div;
     },
 
@@ -70,28 +143,6 @@ const ConversationUtils = {
         
         // Scroll to bottom to show latest message
         container.scrollTop = container.scrollHeight;
-    },
-
-    // Save conversation to sessionStorage
-    saveConversationToStorage: function(conversationHistory) {
-        try {
-            sessionStorage.setItem('currentConversation', JSON.stringify(conversationHistory));
-            return true;
-        } catch (error) {
-            console.error('Error saving conversation to storage:', error);
-            return false;
-        }
-    },
-
-    // Load conversation from sessionStorage
-    loadConversationFromStorage: function() {
-        try {
-            const conversationHistory = JSON.parse(sessionStorage.getItem('currentConversation'));
-            return conversationHistory || [];
-        } catch (error) {
-            console.error('Error loading conversation from storage:', error);
-            return [];
-        }
     }
 };
 
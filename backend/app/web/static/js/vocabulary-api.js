@@ -0,0 +1,81 @@
+// API functions for the vocabulary tracker and word bank
+const VocabularyAPI = {
+    // Function to fetch a page of the words the user has used
+    fetchVocabulary: function(page, filters) {
+        const params = new URLSearchParams({ page: page });
+        if (filters.q) {
+            params.set('q', filters.q);
+        }
+        if (filters.level) {
+            params.set('level', filters.level);
+        }
+        if (filters.sort) {
+            params.set('sort', filters.sort);
+        }
+        
+        return fetch('/api/vocabulary?' + params.toString(), {
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to load vocabulary');
+            }
+            return response.json();
+        });
+    },
+
+    // Function to fetch a page of the word bank
+    fetchWordBank: function(page, search) {
+        const params = new URLSearchParams({ page: page });
+        if (search) {
+            params.set('q', search);
+        }
+        
+        return fetch('/api/vocabulary/bank?' + params.toString(), {
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to load word bank');
+            }
+            return response.json();
+        });
+    },
+
+    // Function to save a word with the sentence it appeared in
+    saveWord: function(word, context, sessionId) {
+        return fetch('/api/vocabulary/bank', {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            credentials: 'include',
+            body: JSON.stringify({
+                word: word,
+                context: context,
+                session_id: sessionId || null
+            })
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to save word');
+            }
+            return response.json();
+        });
+    },
+
+    // Function to remove a word from the word bank
+    deleteWord: function(id) {
+        return fetch('/api/vocabulary/bank/' + id, {
+            method: 'DELETE',
+            credentials: 'include'
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error('Failed to delete word');
+            }
+        });
+    }
+};
+
+export { VocabularyAPI };
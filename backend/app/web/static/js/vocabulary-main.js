@@ -0,0 +1,94 @@
+import { VocabularyAPI } from './vocabulary-api.js';
+import { VocabularyUI } from './vocabulary-ui.js';
+
+// Main application logic for the vocabulary tracker and word bank
+document.addEventListener('DOMContentLoaded', function() {
+    let vocabularyPage = 1;
+    let vocabularyFilters = {};
+    let wordBankPage = 1;
+    let wordBankSearch = '';
+    
+    // Load and display a page of vocabulary
+    function loadVocabulary(page) {
+        VocabularyAPI.fetchVocabulary(page, vocabularyFilters)
+            .then(data => {
+                vocabularyPage = data.page;
+                VocabularyUI.displayVocabulary(data);
+            })
+            .catch(error => {
+                console.error('Error fetching vocabulary:', error);
+                VocabularyUI.showError('vocabulary-list', 'Unable to load your vocabulary. Please try again later.');
+            });
+    }
+    
+    // Load and display a page of the word bank
+    function loadWordBank(page) {
+        VocabularyAPI.fetchWordBank(page, wordBankSearch)
+            .then(data => {
+                // Deleting the last word of a page goes back to the previous one
+                if (data.words.length === 0 && data.page > 1) {
+                    loadWordBank(data.page - 1);
+                    return;
+                }
+                wordBankPage = data.page;
+                VocabularyUI.displayWordBank(data, deleteWord);
+            })
+            .catch(error => {
+                console.error('Error fetching word bank:', error);
+                VocabularyUI.showError('word-bank-list', 'Unable to load your word bank. Please try again later.');
+            });
+    }
+    
+    // Remove a word from the word bank after confirmation
+    function deleteWord(entry) {
+        if (!confirm('Remove "' + entry.word + '" from your word bank?')) {
+            return;
+        }
+        VocabularyAPI.deleteWord(entry.id)
+            .then(() => loadWordBank(wordBankPage))
+            .catch(error => {
+                console.error('Error deleting word:', error);
+                alert('Unable to remove the word. Please try again.');
+            });
+    }
+    
+    document.getElementById('vocabulary-filters').addEventListener('submit', function(event) {
+        event.preventDefault();
+        vocabularyFilters = {
+            q: document.getElementById('vocabulary-search').value.trim(),
+            level: document.getElementById('vocabulary-level').value,
+            sort: document.getElementById('vocabulary-sort').value
+        };
+        loadVocabulary(1);
+    });
+    
+    document.getElementById('vocabulary-previous').addEventListener('click', function() {
+        loadVocabulary(vocabularyPage - 1);
+    });
+    
+    document.getElementById('vocabulary-next').addEventListener('click', function() {
+        loadVocabulary(vocabularyPage + 1);
+    });
+    
+    document.getElementById('word-bank-filters').addEventListener('submit', function(event) {
+        event.preventDefault();
+        wordBankSearch = document.getElementById('word-bank-search').value.trim();
+        loadWordBank(1);
+    });
+    
+    document.getElementById('word-bank-previous').addEventListener('click', function() {
+        loadWordBank(wordBankPage - 1);
+    });
+    
+    document.getElementById('word-bank-next').addEventListener('click', function() {
+        loadWordBank(wordBankPage + 1);
+    });
+    
+    // Open the word bank directly with /vocabulary#word-bank
+    if (window.location.hash === '#word-bank') {
+        bootstrap.Tab.getOrCreateInstance(document.getElementById('word-bank-tab-button')).show();
+    }
+    
+    loadVocabulary(1);
+    loadWordBank(1);
+});
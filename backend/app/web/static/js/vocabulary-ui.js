@@ -0,0 +1,134 @@
+// UI functions for the vocabulary tracker and word bank
+const VocabularyUI = {
+    // Build a level badge, or an empty string for words without a level
+    formatLevel: function(level) {
+        if (!level) {
+            return '';
+        }
+        const badge = document.createElement('span');
+        badge.className = 'badge bg-primary';
+        badge.textContent = level;
+        return badge;
+    },
+
+    // Build a table row for a word the user has used
+    formatWord: function(word) {
+        const row = document.createElement('tr');
+        
+        const lemma = document.createElement('td');
+        lemma.textContent = word.lemma;
+        row.appendChild(lemma);
+        
+        const level = document.createElement('td');
+        level.append(this.formatLevel(word.cefr_level));
+        row.appendChild(level);
+        
+        [word.frequency, word.correction_count, new Date(word.first_seen_at).toLocaleDateString()].forEach(value => {
+            const cell = document.createElement('td');
+            cell.className = 'text-end';
+            cell.textContent = value;
+            row.appendChild(cell);
+        });
+        return row;
+    },
+
+    // Display a page of vocabulary and update its pagination controls
+    displayVocabulary: function(data) {
+        const list = document.getElementById('vocabulary-list');
+        list.innerHTML = '';
+        
+        if (data.words.length === 0) {
+            list.innerHTML = '<tr><td colspan="5" class="text-center text-muted py-3">No words found. Have a conversation to build your vocabulary.</td></tr>';
+        } else {
+            data.words.forEach(word => {
+                list.appendChild(this.formatWord(word));
+            });
+        }
+        
+        this.updatePagination('vocabulary', data);
+    },
+
+    // Build a list entry for a saved word with a button to delete it
+    formatEntry: function(entry, onDelete) {
+        const item = document.createElement('div');
+        item.className = 'list-group-item';
+        
+        const header = document.createElement('div');
+        header.className = 'd-flex justify-content-between align-items-center';
+        
+        const word = document.createElement('strong');
+        word.textContent = entry.word;
+        const title = document.createElement('div');
+        title.className = 'd-flex gap-2 align-items-center';
+        title.appendChild(word);
+        title.append(this.formatLevel(entry.cefr_level));
+        header.appendChild(title);
+        
+        const remove = document.createElement('button');
+        remove.className = 'btn btn-sm btn-outline-danger';
+        remove.title = 'Remove from word bank';
+        remove.innerHTML = '<i class="fas fa-trash"></i>';
+        remove.addEventListener('click', () => onDelete(entry));
+        header.appendChild(remove);
+        item.appendChild(header);
+        
+        if (entry.context) {
+            const context = document.createElement('p');
+            context.className = 'mb-0 text-muted small fst-italic';
+            context.textContent = entry.context;
+            item.appendChild(context);
+        }
+        
+        const saved = document.createElement('small');
+        saved.className = 'text-muted';
+        saved.textContent = 'Saved ' + new Date(entry.created_at).toLocaleDateString();
+        item.appendChild(saved);
+        return item;
+    },
+
+    // Display a page of the word bank and update its pagination controls
+    displayWordBank: function(data, onDelete) {
+        const list = document.getElementById('word-bank-list');
+        list.innerHTML = '';
+        
+        if (data.words.length === 0) {
+            list.innerHTML = '<div class="text-center text-muted py-3">Your word bank is empty.</div>';
+        } else {
+            data.words.forEach(entry => {
+                list.appendChild(this.formatEntry(entry, onDelete));
+            });
+        }
+        
+        this.updatePagination('word-bank', data);
+    },
+
+    // Update the page information and buttons of a list
+    updatePagination: function(prefix, data) {
+        document.getElementById(prefix + '-page-info').textContent = data.total_pages > 0
+            ? 'Page ' + data.page + ' of ' + data.total_pages + ' (' + data.total + ' words)'
+            : '';
+        document.getElementById(prefix + '-previous').disabled = data.page <= 1;
+        document.getElementById(prefix + '-next').disabled = data.page >= data.total_pages;
+    },
+
+    // Show error message in a list
+    showError: function(listId, message) {
+        const list = document.getElementById(listId);
+        list.innerHTML = '';
+        
+        const error = document.createElement(list.tagName === 'TBODY' ? 'tr' : 'div');
+        if (list.tagName === 'TBODY') {
+            const cell = document.createElement('td');
+            cell.colSpan = 5;
+            cell.className = 'text-center text-muted py-3';
+            cell.textContent = message;
+            error.appendChild(cell);
+        } else {
+            error.className = 'text-center text-muted py-3';
+            error.textContent = message;
+        }
+        list.appendChild(error);
+    }
+};
+
+export { VocabularyUI };
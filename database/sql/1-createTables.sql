@@ -1,6 +1,6 @@
 BEGIN;
 
--- Users table
+-- Users table (role is student, teacher or admin; the first admin is set with UPDATE users SET role = 'admin')
 CREATE TABLE users (
 		id SERIAL PRIMARY KEY,
 		provider VARCHAR(255) NOT NULL,
@@ -14,23 +14,332 @@ CREATE TABLE users (
 		refresh_token TEXT,
 		expires_at TIMESTAMPTZ,
 		picture_link TEXT,
+		target_language VARCHAR(5) NOT NULL DEFAULT 'en',
+		native_language VARCHAR(5),
+		role VARCHAR(10) NOT NULL DEFAULT 'student',
 		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
 		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
 		UNIQUE(provider, id_by_provider)
 );
 
+-- Scenarios table (role-play situations a conversation can be set in)
+CREATE TABLE scenarios (
+		id SERIAL PRIMARY KEY,
+		slug VARCHAR(100) NOT NULL UNIQUE,
+		title VARCHAR(255) NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		setting TEXT NOT NULL,
+		ai_role TEXT NOT NULL,
+		learner_goal TEXT NOT NULL,
+		target_vocabulary TEXT[] NOT NULL DEFAULT '{}',
+		completion_condition TEXT NOT NULL,
+		opening_line TEXT NOT NULL,
+		created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Classes table (a teacher's class, which students join with its invite code)
+CREATE TABLE classes (
+		id SERIAL PRIMARY KEY,
+		teacher_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		name VARCHAR(100) NOT NULL,
+		invite_code VARCHAR(12) NOT NULL UNIQUE,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Class members table (the students of each class)
+CREATE TABLE class_members (
+		class_id INTEGER NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		joined_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (class_id, user_id)
+);
+
+-- Assignments table (work a teacher sets a class: a free conversation on a topic or a scenario)
+CREATE TABLE assignments (
+		id SERIAL PRIMARY KEY,
+		class_id INTEGER NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+		title VARCHAR(200) NOT NULL,
+		mode VARCHAR(20) NOT NULL,
+		scenario_id INTEGER REFERENCES scenarios(id) ON DELETE SET NULL,
+		topic TEXT NOT NULL DEFAULT '',
+		language VARCHAR(5) NOT NULL DEFAULT 'en',
+		min_turns INTEGER NOT NULL,
+		due_at TIMESTAMPTZ NOT NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Conversation sessions table (server-owned history of a conversation in progress)
+CREATE TABLE conversation_sessions (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		history JSONB NOT NULL DEFAULT '[]'::jsonb,
+		status VARCHAR(20) NOT NULL DEFAULT 'active',
+		scenario_id INTEGER REFERENCES scenarios(id) ON DELETE SET NULL,
+		scenario_completed_at TIMESTAMPTZ,
+		assignment_id INTEGER REFERENCES assignments(id) ON DELETE SET NULL,
+		language VARCHAR(5) NOT NULL DEFAULT 'en',
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		ended_at TIMESTAMPTZ
+);
+
 -- Conversations table
 CREATE TABLE conversations (
 		id SERIAL PRIMARY KEY,
 		user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+		session_id UUID UNIQUE REFERENCES conversation_sessions(id) ON DELETE SET NULL,
+		scenario_id INTEGER REFERENCES scenarios(id) ON DELETE SET NULL,
+		scenario_completed BOOLEAN NOT NULL DEFAULT FALSE,
+		assignment_id INTEGER REFERENCES assignments(id) ON DELETE SET NULL,
+		language VARCHAR(5) NOT NULL DEFAULT 'en',
 		history JSONB NOT NULL,
+		vocabulary_recorded BOOLEAN NOT NULL DEFAULT FALSE,
+		review_cards_created BOOLEAN NOT NULL DEFAULT FALSE,
+		memory_recorded BOOLEAN NOT NULL DEFAULT FALSE,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Feedback reports table (latest graded report of each conversation)
+CREATE TABLE feedback_reports (
+		id SERIAL PRIMARY KEY,
+		conversation_id INTEGER NOT NULL UNIQUE REFERENCES conversations(id) ON DELETE CASCADE,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		report JSONB NOT NULL,
+		level VARCHAR(2) NOT NULL,
+		grammar_score SMALLINT NOT NULL,
+		vocabulary_score SMALLINT NOT NULL,
+		fluency_score SMALLINT NOT NULL,
+		coherence_score SMALLINT NOT NULL,
+		prompt_version VARCHAR(50) NOT NULL,
+		model VARCHAR(255) NOT NULL,
 		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Conversation metrics table (progress measures derived from each conversation)
+CREATE TABLE conversation_metrics (
+		conversation_id INTEGER PRIMARY KEY REFERENCES conversations(id) ON DELETE CASCADE,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		turn_count INTEGER NOT NULL,
+		word_count INTEGER NOT NULL,
+		words_per_turn REAL NOT NULL,
+		correction_rate REAL NOT NULL,
+		vocabulary_diversity REAL NOT NULL,
+		cefr_level VARCHAR(2),
+		conversation_at TIMESTAMPTZ NOT NULL,
+		computed_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Exam sessions table (Cambridge speaking exam simulations and their reports)
+CREATE TABLE exam_sessions (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		exam VARCHAR(10) NOT NULL,
+		current_part INTEGER NOT NULL DEFAULT 1,
+		part_started_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		history JSONB NOT NULL DEFAULT '[]'::jsonb,
+		status VARCHAR(20) NOT NULL DEFAULT 'active',
+		report JSONB,
+		model VARCHAR(255),
+		prompt_version VARCHAR(50),
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		ended_at TIMESTAMPTZ
+);
+
+-- Vocabulary table (every lemma a user has used or been corrected on)
+CREATE TABLE vocabulary (
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		lemma VARCHAR(100) NOT NULL,
+		cefr_level VARCHAR(2),
+		frequency INTEGER NOT NULL DEFAULT 0,
+		correction_count INTEGER NOT NULL DEFAULT 0,
+		first_seen_at TIMESTAMPTZ NOT NULL,
+		last_seen_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (user_id, lemma)
+);
+
+-- Word bank table (words a user saved from Voxy's replies to study later)
+CREATE TABLE word_bank (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		word VARCHAR(100) NOT NULL,
+		lemma VARCHAR(100) NOT NULL,
+		cefr_level VARCHAR(2),
+		context TEXT NOT NULL DEFAULT '',
+		session_id UUID REFERENCES conversation_sessions(id) ON DELETE SET NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (user_id, lemma)
+);
+
+-- Review cards table (past corrections scheduled for spaced repetition with SM-2)
+CREATE TABLE review_cards (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		conversation_id INTEGER REFERENCES conversations(id) ON DELETE SET NULL,
+		original TEXT NOT NULL,
+		corrected TEXT NOT NULL,
+		language VARCHAR(5) NOT NULL DEFAULT 'en',
+		ease_factor REAL NOT NULL DEFAULT 2.5,
+		interval_days INTEGER NOT NULL DEFAULT 0,
+		repetitions INTEGER NOT NULL DEFAULT 0,
+		due_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		review_count INTEGER NOT NULL DEFAULT 0,
+		correct_count INTEGER NOT NULL DEFAULT 0,
+		last_reviewed_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (user_id, original, corrected)
+);
+
+-- Conversation grammar table (the number of sentences whose grammar errors were counted)
+CREATE TABLE conversation_grammar (
+		conversation_id INTEGER PRIMARY KEY REFERENCES conversations(id) ON DELETE CASCADE,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		sentence_count INTEGER NOT NULL,
+		conversation_at TIMESTAMPTZ NOT NULL,
+		computed_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Grammar errors table (corrections of each conversation classified by error type)
+CREATE TABLE grammar_errors (
+		conversation_id INTEGER NOT NULL REFERENCES conversation_grammar(conversation_id) ON DELETE CASCADE,
+		error_type VARCHAR(20) NOT NULL,
+		sentence_count INTEGER NOT NULL,
+		error_count INTEGER NOT NULL,
+		PRIMARY KEY (conversation_id, error_type)
+);
+
+-- Assignment submissions table (each conversation saved from an assignment and whether it completes it)
+CREATE TABLE assignment_submissions (
+		conversation_id INTEGER PRIMARY KEY REFERENCES conversations(id) ON DELETE CASCADE,
+		assignment_id INTEGER NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		turn_count INTEGER NOT NULL,
+		completed BOOLEAN NOT NULL,
+		submitted_at TIMESTAMPTZ NOT NULL
+);
+
+-- Turn annotations table (a teacher's comment on a student turn and their verdict on its correction)
+CREATE TABLE turn_annotations (
+		id SERIAL PRIMARY KEY,
+		conversation_id INTEGER NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+		turn_index INTEGER NOT NULL,
+		teacher_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		comment TEXT NOT NULL DEFAULT '',
+		correction_status VARCHAR(10) NOT NULL DEFAULT '',
+		correction TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (conversation_id, turn_index)
+);
+
+-- Conversation grades table (a CEFR level a teacher gave a conversation, which takes precedence over the graded report)
+CREATE TABLE conversation_grades (
+		conversation_id INTEGER PRIMARY KEY REFERENCES conversations(id) ON DELETE CASCADE,
+		teacher_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		level VARCHAR(2) NOT NULL,
+		comment TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Notifications table (news for a user, shown on their home page until read)
+CREATE TABLE notifications (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		conversation_id INTEGER REFERENCES conversations(id) ON DELETE CASCADE,
+		message TEXT NOT NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		read_at TIMESTAMPTZ
+);
+
+-- Learner profiles table (what a user likes to talk about)
+CREATE TABLE learner_profiles (
+		user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+		interests TEXT[] NOT NULL DEFAULT '{}',
+		goals TEXT[] NOT NULL DEFAULT '{}',
+		preferred_topics TEXT[] NOT NULL DEFAULT '{}',
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Learner levels table (a user's current CEFR level in each language they practise, kept up to date from their graded conversations in it)
+CREATE TABLE learner_levels (
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		language VARCHAR(5) NOT NULL,
+		cefr_level VARCHAR(2) NOT NULL,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, language)
+);
+
+-- Learner memories table (durable facts about a user, such as their name, job, hobbies and past topics, remembered from their conversations)
+CREATE TABLE learner_memories (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		category VARCHAR(20) NOT NULL,
+		fact TEXT NOT NULL,
+		conversation_id INTEGER REFERENCES conversations(id) ON DELETE SET NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Indexes
 CREATE INDEX idx_users_email ON users (email);
 CREATE INDEX idx_users_provider_id_by_provider ON users (provider, id_by_provider);
 CREATE INDEX idx_conversations_user_id ON conversations (user_id);
 CREATE INDEX idx_conversations_created_at ON conversations (created_at);
+CREATE INDEX idx_conversation_sessions_user_id ON conversation_sessions (user_id);
+CREATE INDEX idx_feedback_reports_user_id ON feedback_reports (user_id);
+CREATE INDEX idx_feedback_reports_level ON feedback_reports (level);
+CREATE INDEX idx_conversation_metrics_user_id_conversation_at ON conversation_metrics (user_id, conversation_at);
+CREATE INDEX idx_exam_sessions_user_id ON exam_sessions (user_id);
+CREATE INDEX idx_vocabulary_user_id_cefr_level ON vocabulary (user_id, cefr_level);
+CREATE INDEX idx_word_bank_user_id_created_at ON word_bank (user_id, created_at);
+CREATE INDEX idx_review_cards_user_id_due_at ON review_cards (user_id, due_at);
+CREATE INDEX idx_conversation_grammar_user_id_conversation_at ON conversation_grammar (user_id, conversation_at);
+CREATE INDEX idx_classes_teacher_id ON classes (teacher_id);
+CREATE INDEX idx_class_members_user_id ON class_members (user_id);
+CREATE INDEX idx_assignments_class_id_due_at ON assignments (class_id, due_at);
+CREATE INDEX idx_assignment_submissions_assignment_id_user_id ON assignment_submissions (assignment_id, user_id);
+CREATE INDEX idx_notifications_user_id_created_at ON notifications (user_id, created_at);
+CREATE UNIQUE INDEX idx_notifications_unread_conversation ON notifications (user_id, conversation_id) WHERE read_at IS NULL;
+CREATE UNIQUE INDEX idx_learner_memories_user_id_fact ON learner_memories (user_id, LOWER(fact));
+
+-- Built-in scenarios
+INSERT INTO scenarios (slug, title, description, setting, ai_role, learner_goal, target_vocabulary, completion_condition, opening_line) VALUES
+(
+		'restaurant',
+		'Ordering at a Restaurant',
+		'Order a meal and pay the bill in a busy restaurant.',
+		'A friendly neighbourhood restaurant at dinner time.',
+		'a waiter named Sam who takes orders, recommends dishes and brings the bill',
+		'Order a starter, a main course and a drink, ask about at least one dish, and ask for the bill.',
+		ARRAY['menu', 'starter', 'main course', 'dessert', 'recommend', 'allergic', 'bill', 'tip'],
+		'The learner has ordered a main course and a drink and has asked for the bill.',
+		'Good evening and welcome! Here is the menu. Can I get you something to drink to start with?'
+),
+(
+		'job-interview',
+		'Job Interview',
+		'Answer questions in an interview for a job you want.',
+		'The office of a mid-sized company interviewing candidates for an open position.',
+		'a hiring manager named Alex who asks about experience, strengths and motivation',
+		'Introduce yourself, describe your experience and strengths, and ask the interviewer at least one question about the job.',
+		ARRAY['experience', 'responsible for', 'strength', 'weakness', 'team', 'deadline', 'salary', 'opportunity'],
+		'The learner has described their experience and a strength and has asked the interviewer a question about the job.',
+		'Hello, thanks for coming in today. Please have a seat. Could you start by telling me a little about yourself?'
+),
+(
+		'doctor',
+		'Visiting the Doctor',
+		'Describe your symptoms and understand the advice you get.',
+		'A general practitioner''s consultation room.',
+		'a doctor named Dr. Lee who asks about symptoms and gives advice and a prescription',
+		'Explain what is wrong, answer questions about how long and how badly it hurts, and check how to take the medicine.',
+		ARRAY['symptom', 'headache', 'fever', 'sore throat', 'prescription', 'appointment', 'allergy', 'twice a day'],
+		'The learner has described their symptoms and has asked or confirmed how to take the prescribed medicine.',
+		'Good morning, please come in and sit down. What seems to be the problem today?'
+);
 
 COMMIT;